@@ -0,0 +1,277 @@
+// Command scarf-replay re-submits events spooled to disk, such as the files
+// written by scarf.FileTransport on offline or air-gapped machines, to an
+// event collection endpoint.
+//
+// Usage:
+//
+//   scarf-replay [flags] [file ...]
+//
+// Each file is an export written by scarf.FileTransport or scarf.ExportEvents
+// (see scarf.ExportVersion), or holds one plain JSON event object per line;
+// "-" or no files reads standard input. Records whose checksum doesn't match
+// are skipped and reported. Original timestamps and event IDs are kept, so replaying a file twice
+// doesn't double count with endpoints that deduplicate by ID. Events are sent
+// in batches at a limited rate, with progress reported on standard error.
+// When the endpoint rate limits the replay, the batch is retried after the
+// requested delay. Events that still can't be delivered are written to the
+// -failed file, as an export, for a later run.
+//
+// The endpoint defaults to SCARF_ENDPOINT_URL and the API key to
+// SCARF_API_KEY. The exit status is 1 if any event was not delivered or any
+// line was not a valid event, and 2 for usage errors.
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "os/signal"
+    "strings"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+func main() {
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stderr))
+}
+
+// config holds the parsed command line.
+type config struct {
+    endpoint string
+    apiKey   string
+    rate     float64
+    batch    int
+    retries  int
+    timeout  time.Duration
+    progress time.Duration
+    failed   string
+    dryRun   bool
+    files    []string
+}
+
+func parseFlags(args []string, stderr io.Writer) (config, error) {
+    var c config
+    fs := flag.NewFlagSet("scarf-replay", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    fs.Usage = func() {
+        fmt.Fprintln(stderr, "usage: scarf-replay [flags] [file ...]")
+        fs.PrintDefaults()
+    }
+    fs.StringVar(&c.endpoint, "endpoint", os.Getenv("SCARF_ENDPOINT_URL"), "event collection endpoint `URL`")
+    fs.StringVar(&c.apiKey, "api-key", os.Getenv("SCARF_API_KEY"), "API `key` sent as a bearer token")
+    fs.Float64Var(&c.rate, "rate", 50, "maximum events per second (0 for no limit)")
+    fs.IntVar(&c.batch, "batch", 100, "maximum events per request")
+    fs.IntVar(&c.retries, "retries", 3, "retries per batch when rate limited")
+    fs.DurationVar(&c.timeout, "timeout", 30*time.Second, "timeout per request")
+    fs.DurationVar(&c.progress, "progress", 2*time.Second, "interval between progress reports (0 to disable)")
+    fs.StringVar(&c.failed, "failed", "", "append events that could not be delivered to `file`")
+    fs.BoolVar(&c.dryRun, "dry-run", false, "read and count events without sending them")
+    if err := fs.Parse(args); err != nil {
+        return config{}, err
+    }
+    c.files = fs.Args()
+    switch {
+    case !c.dryRun && strings.TrimSpace(c.endpoint) == "":
+        return config{}, errors.New("no endpoint: set -endpoint or SCARF_ENDPOINT_URL")
+    case c.rate < 0:
+        return config{}, errors.New("-rate must not be negative")
+    case c.batch < 1:
+        return config{}, errors.New("-batch must be at least 1")
+    }
+    return c, nil
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stderr io.Writer) int {
+    c, err := parseFlags(args, stderr)
+    if errors.Is(err, flag.ErrHelp) {
+        return 0
+    }
+    if err != nil {
+        fmt.Fprintln(stderr, "scarf-replay:", err)
+        return 2
+    }
+
+    r := &replayer{config: c, stderr: stderr, start: time.Now()}
+    if !c.dryRun {
+        opts := []scarf.Option{scarf.WithTimeout(c.timeout)}
+        if c.apiKey != "" {
+            opts = append(opts, scarf.WithAPIKey(c.apiKey))
+        }
+        r.logger = scarf.New(c.endpoint, opts...)
+        if reason := r.logger.DisabledReason(); reason != "" {
+            fmt.Fprintln(stderr, "scarf-replay: analytics disabled:", reason)
+            return 1
+        }
+    }
+    if c.failed != "" {
+        f, err := os.OpenFile(c.failed, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+        if err != nil {
+            fmt.Fprintln(stderr, "scarf-replay:", err)
+            return 2
+        }
+        defer f.Close()
+        r.failedOut = scarf.NewEventWriter(f)
+        defer r.failedOut.Close()
+    }
+
+    files := c.files
+    if len(files) == 0 {
+        files = []string{"-"}
+    }
+    status := 0
+    for _, name := range files {
+        if err := r.replayFile(ctx, name, stdin); err != nil {
+            fmt.Fprintln(stderr, "scarf-replay:", err)
+            status = 1
+            break
+        }
+    }
+    r.report("done")
+    if r.failed > 0 || r.skipped > 0 {
+        status = 1
+    }
+    return status
+}
+
+// replayer sends events read from spool files and keeps count of them.
+type replayer struct {
+    config
+    logger    *scarf.ScarfEventLogger // nil in dry-run mode
+    stderr    io.Writer
+    failedOut *scarf.EventWriter
+
+    start        time.Time
+    lastReport   time.Time
+    sent, failed int
+    skipped      int       // lines that aren't valid events
+    next         time.Time // when the rate limit allows the next batch
+}
+
+func (r *replayer) replayFile(ctx context.Context, name string, stdin io.Reader) error {
+    in := stdin
+    if name != "-" {
+        f, err := os.Open(name)
+        if err != nil {
+            return err
+        }
+        defer f.Close()
+        in = f
+    }
+
+    er := scarf.NewEventReader(in)
+    var batch []scarf.Event
+    for {
+        ev, err := er.Next()
+        var lineErr *scarf.ExportLineError
+        switch {
+        case errors.As(err, &lineErr):
+            fmt.Fprintf(r.stderr, "scarf-replay: %s:%d: skipping invalid event: %v\n", name, lineErr.Line, lineErr.Err)
+            r.skipped++
+        case err == nil:
+            batch = append(batch, ev)
+        }
+        done := err != nil && lineErr == nil // io.EOF, or the file can't be read further
+        if len(batch) == r.batch || (done && len(batch) > 0) {
+            if serr := r.sendBatch(ctx, batch); serr != nil {
+                return serr
+            }
+            batch = batch[:0]
+        }
+        if err == io.EOF {
+            return nil
+        }
+        if done {
+            return fmt.Errorf("%s: %w", name, err)
+        }
+    }
+}
+
+// sendBatch sends events, waiting for the rate limit first and retrying
+// while the endpoint rate limits. It returns an error only if ctx is done;
+// undelivered events are counted and written to the -failed file.
+func (r *replayer) sendBatch(ctx context.Context, events []scarf.Event) error {
+    if err := sleepUntil(ctx, r.next); err != nil {
+        return err
+    }
+    if r.rate > 0 {
+        r.next = time.Now().Add(time.Duration(float64(len(events)) / r.rate * float64(time.Second)))
+    }
+
+    var err error
+    if r.logger != nil {
+        for attempt := 0; ; attempt++ {
+            err = r.logger.LogEvents(ctx, events)
+            if !errors.Is(err, scarf.ErrRateLimited) || attempt == r.retries {
+                break
+            }
+            if err := sleepUntil(ctx, retryAt(err, attempt)); err != nil {
+                return err
+            }
+        }
+    }
+    if err != nil {
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+        fmt.Fprintf(r.stderr, "scarf-replay: %d events not delivered: %v\n", len(events), err)
+        r.failed += len(events)
+        if r.failedOut != nil {
+            for _, ev := range events {
+                if err := r.failedOut.Write(ev); err != nil {
+                    fmt.Fprintln(r.stderr, "scarf-replay: writing failed events:", err)
+                    break
+                }
+            }
+        }
+    } else {
+        r.sent += len(events)
+    }
+    if r.progress > 0 && time.Since(r.lastReport) >= r.progress {
+        r.report("progress")
+    }
+    return nil
+}
+
+// report prints the counts so far.
+func (r *replayer) report(what string) {
+    r.lastReport = time.Now()
+    elapsed := time.Since(r.start)
+    rate := float64(r.sent) / elapsed.Seconds()
+    verb := "sent"
+    if r.dryRun {
+        verb = "read"
+    }
+    fmt.Fprintf(r.stderr, "scarf-replay: %s: %d %s, %d failed, %d skipped in %s (%.1f/s)\n",
+        what, r.sent, verb, r.failed, r.skipped, elapsed.Round(time.Millisecond), rate)
+}
+
+// retryAt returns when to retry after a rate limit error: the time the
+// endpoint asked for, or a growing delay if it didn't say.
+func retryAt(err error, attempt int) time.Time {
+    var epErr *scarf.EndpointError
+    if errors.As(err, &epErr) && !epErr.RetryAfter.IsZero() {
+        return epErr.RetryAfter
+    }
+    return time.Now().Add(time.Duration(attempt+1) * time.Second)
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+    d := time.Until(t)
+    if d <= 0 {
+        return ctx.Err()
+    }
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    select {
+    case <-timer.C:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+// writeSpool writes events to a spool file with FileTransport.
+func writeSpool(t *testing.T, events ...scarf.Event) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "events.ndjson")
+    ft, err := scarf.NewFileTransport(path, 0, 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, ev := range events {
+        if err := ft.Send(context.Background(), ev); err != nil {
+            t.Fatal(err)
+        }
+    }
+    ft.Close()
+    return path
+}
+
+func TestReplay(t *testing.T) {
+    ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+    path := writeSpool(t,
+        scarf.Event{Name: "install", Timestamp: ts, ID: "id-1", Properties: map[string]any{"n": 42}},
+        scarf.Event{Name: "startup", Timestamp: ts, ID: "id-2"},
+        scarf.Event{Name: "exit", Timestamp: ts, ID: "id-3"},
+    )
+    c := scarftest.NewCollector(t)
+    var stderr bytes.Buffer
+    if code := run(context.Background(), []string{"-endpoint", c.URL, "-batch", "2", "-rate", "0", path}, nil, &stderr); code != 0 {
+        t.Fatalf("exit status %d: %s", code, stderr.String())
+    }
+
+    got := c.Events()
+    if len(got) != 3 || c.Requests() != 2 {
+        t.Fatalf("expected 3 events in 2 requests, got %d in %d", len(got), c.Requests())
+    }
+    if got[0].Name != "install" || got[0].ID != "id-1" || !got[0].Timestamp.Equal(ts) {
+        t.Fatalf("expected the original name, ID and timestamp, got %+v", got[0])
+    }
+    if n := got[0].Properties["n"]; n != float64(42) {
+        t.Fatalf("unexpected property: %v", n)
+    }
+    if !strings.Contains(stderr.String(), "done: 3 sent, 0 failed, 0 skipped") {
+        t.Fatalf("unexpected summary: %s", stderr.String())
+    }
+}
+
+func TestReplayFailures(t *testing.T) {
+    path := writeSpool(t, scarf.Event{Name: "install", ID: "id-1"})
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    f.WriteString("not json\n")
+    f.WriteString(`{"crc32c":"00000000","data":{"event":"tampered"}}` + "\n")
+    f.Close()
+
+    c := scarftest.NewCollector(t)
+    c.SetStatus(500)
+    failed := filepath.Join(t.TempDir(), "failed.ndjson")
+    var stderr bytes.Buffer
+    code := run(context.Background(), []string{"-endpoint", c.URL, "-rate", "0", "-retries", "0", "-failed", failed, path}, nil, &stderr)
+    if code != 1 {
+        t.Fatalf("expected exit status 1, got %d: %s", code, stderr.String())
+    }
+    if !strings.Contains(stderr.String(), ":3: skipping invalid event") || !strings.Contains(stderr.String(), ":4: skipping invalid event: scarf: export checksum mismatch") || !strings.Contains(stderr.String(), "0 sent, 1 failed, 2 skipped") {
+        t.Fatalf("unexpected output: %s", stderr.String())
+    }
+
+    f, err = os.Open(failed)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+    if events, err := scarf.ImportEvents(f); err != nil || len(events) != 1 || events[0].ID != "id-1" {
+        t.Fatalf("expected the undelivered event in the failed file, got %v, %v", events, err)
+    }
+}
+
+func TestReplayRateLimit(t *testing.T) {
+    path := writeSpool(t, scarf.Event{Name: "a"}, scarf.Event{Name: "b"}, scarf.Event{Name: "c"})
+    c := scarftest.NewCollector(t)
+    start := time.Now()
+    var stderr bytes.Buffer
+    if code := run(context.Background(), []string{"-endpoint", c.URL, "-batch", "1", "-rate", "20", path}, nil, &stderr); code != 0 {
+        t.Fatalf("exit status %d: %s", code, stderr.String())
+    }
+    if d := time.Since(start); d < 100*time.Millisecond {
+        t.Fatalf("expected 3 events at 20/s to take at least 100ms, took %v", d)
+    }
+}
+
+func TestReplayUsage(t *testing.T) {
+    t.Setenv("SCARF_ENDPOINT_URL", "")
+    var stderr bytes.Buffer
+    if code := run(context.Background(), nil, nil, &stderr); code != 2 || !strings.Contains(stderr.String(), "no endpoint") {
+        t.Fatalf("expected a usage error, got %d: %s", code, stderr.String())
+    }
+
+    stderr.Reset()
+    in := strings.NewReader(`{"event":"install"}` + "\n" + `{"event":"exit"}`)
+    if code := run(context.Background(), []string{"-dry-run"}, in, &stderr); code != 0 || !strings.Contains(stderr.String(), "2 read") {
+        t.Fatalf("expected a dry run to read stdin, got %d: %s", code, stderr.String())
+    }
+}
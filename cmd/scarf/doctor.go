@@ -0,0 +1,385 @@
+package main
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// checkStatus is the outcome of one doctor check.
+type checkStatus string
+
+const (
+    statusOK   checkStatus = "ok"
+    statusWarn checkStatus = "warn"
+    statusFail checkStatus = "FAIL"
+    statusSkip checkStatus = "skip"
+)
+
+// check is one line of the doctor report, with optional detail lines.
+type check struct {
+    name   string
+    status checkStatus
+    detail string
+    notes  []string
+}
+
+// doctorEnv lists the environment variables that affect the SDK.
+var doctorEnv = []string{
+    "SCARF_ENDPOINT_URL", "SCARF_API_KEY", "SCARF_TIMEOUT", "SCARF_SAMPLE_RATE",
+    "SCARF_VERBOSE", "SCARF_STATE_DIR", "DO_NOT_TRACK", "SCARF_NO_ANALYTICS",
+    "HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY",
+}
+
+// certExpiryWarning is how close to expiry the endpoint's certificate
+// draws a warning.
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// doctor implements "scarf doctor": it checks the setup an application
+// configured from the same environment (and config file) would have, and
+// prints a report. The exit status is 1 if any check failed.
+func doctor(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+    var endpoint, configPath, app, spool string
+    var timeout time.Duration
+    fs := flag.NewFlagSet("scarf doctor", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    fs.Usage = func() {
+        fmt.Fprintln(stderr, "usage: scarf doctor [flags]")
+        fs.PrintDefaults()
+    }
+    fs.StringVar(&endpoint, "endpoint", "", "check this endpoint `URL` instead of the configured one")
+    fs.StringVar(&configPath, "config", "", "config `file` the application loads (see scarf.LoadConfig)")
+    fs.StringVar(&app, "app", "", "application `name` passed to WithAppName, to find its consent file")
+    fs.StringVar(&spool, "spool", "", "event `file` written by FileTransport, to check for events waiting to be replayed")
+    fs.DurationVar(&timeout, "timeout", 5*time.Second, "timeout for each network check")
+    if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+        return 0
+    } else if err != nil {
+        return 2
+    }
+    if fs.NArg() > 0 {
+        fmt.Fprintln(stderr, "scarf: doctor takes no arguments")
+        return 2
+    }
+
+    checks := []check{envCheck()}
+    var opts []scarf.Option
+    if app != "" {
+        opts = append(opts, scarf.WithAppName(app))
+    }
+    if endpoint != "" {
+        // Only the endpoint is overridden; the API key, timeout and the rest
+        // still come from the environment and config file.
+        opts = append(opts, scarf.WithEndpointURL(endpoint))
+    }
+    logger, err := scarf.NewFromSources(configPath, opts...)
+    if err != nil {
+        checks = append(checks, check{name: "configuration", status: statusFail, detail: err.Error(),
+            notes: []string{"set SCARF_ENDPOINT_URL, pass -endpoint, or pass the application's -config file"}})
+        checks = append(checks, spoolCheck(spool))
+        return printReport(stdout, checks)
+    }
+    checks = append(checks, configCheck(logger), enabledCheck(logger), consentCheck(logger))
+
+    u, err := url.Parse(endpointOf(logger))
+    if err != nil || u.Host == "" {
+        checks = append(checks, check{name: "endpoint", status: statusFail, detail: fmt.Sprintf("invalid endpoint URL %q", endpointOf(logger))})
+        checks = append(checks, spoolCheck(spool))
+        return printReport(stdout, checks)
+    }
+    proxy, proxyCheck := checkProxy(u)
+    checks = append(checks, proxyCheck, dnsCheck(ctx, u, proxy, timeout), tlsCheck(ctx, u, proxy, timeout),
+        pingCheck(ctx, logger), spoolCheck(spool))
+    return printReport(stdout, checks)
+}
+
+// printReport prints checks and returns the exit status.
+func printReport(w io.Writer, checks []check) int {
+    failed := 0
+    for _, c := range checks {
+        fmt.Fprintf(w, "%-5s %-14s %s\n", c.status, c.name, c.detail)
+        for _, n := range c.notes {
+            fmt.Fprintf(w, "%21s%s\n", "", n)
+        }
+        if c.status == statusFail {
+            failed++
+        }
+    }
+    switch failed {
+    case 0:
+        fmt.Fprintln(w, "\nno problems found")
+        return 0
+    case 1:
+        fmt.Fprintln(w, "\n1 problem found")
+    default:
+        fmt.Fprintf(w, "\n%d problems found\n", failed)
+    }
+    return 1
+}
+
+// envCheck lists the environment variables that are set. It never fails;
+// invalid values surface in the configuration check.
+func envCheck() check {
+    c := check{name: "environment", status: statusOK}
+    for _, k := range doctorEnv {
+        v, ok := os.LookupEnv(k)
+        if !ok {
+            continue
+        }
+        if k == "SCARF_API_KEY" && v != "" {
+            v = "REDACTED"
+        }
+        c.notes = append(c.notes, k+"="+redactURL(v))
+    }
+    c.detail = fmt.Sprintf("%d relevant variables set", len(c.notes))
+    return c
+}
+
+// configCheck reports the effective settings and where they came from.
+func configCheck(logger *scarf.ScarfEventLogger) check {
+    c := check{name: "configuration", status: statusOK, detail: "loaded"}
+    for _, st := range logger.ResolvedConfig() {
+        v := redactURL(st.Value)
+        if v == "" {
+            v = "(unset)"
+        }
+        c.notes = append(c.notes, fmt.Sprintf("%s = %s (%s)", st.Key, v, st.Source))
+        if st.Key == "sample_rate" && st.Value != "1" {
+            c.status, c.detail = statusWarn, "only a sample of events is sent (sample_rate "+st.Value+")"
+        }
+    }
+    return c
+}
+
+func enabledCheck(logger *scarf.ScarfEventLogger) check {
+    if !logger.Enabled() {
+        return check{name: "analytics", status: statusFail, detail: "disabled: " + logger.DisabledReason()}
+    }
+    return check{name: "analytics", status: statusOK, detail: "enabled (decided by " + logger.EnabledBy().String() + ")"}
+}
+
+func consentCheck(logger *scarf.ScarfEventLogger) check {
+    c := check{name: "consent", detail: "state " + logger.ConsentState().String()}
+    switch reason := logger.DisabledReason(); {
+    case strings.HasPrefix(reason, "telemetry consent"):
+        c.status, c.detail = statusFail, reason
+        c.notes = []string{"the application must record consent, e.g. with SetConsent(true) or AskConsent"}
+    default:
+        c.status = statusOK
+    }
+    return c
+}
+
+// checkProxy reports the proxy requests to u go through, if any.
+func checkProxy(u *url.URL) (*url.URL, check) {
+    proxy, err := http.ProxyFromEnvironment(&http.Request{URL: u})
+    switch {
+    case err != nil:
+        return nil, check{name: "proxy", status: statusFail, detail: "invalid proxy setting: " + err.Error()}
+    case proxy == nil:
+        return nil, check{name: "proxy", status: statusOK, detail: "direct connection"}
+    }
+    return proxy, check{name: "proxy", status: statusOK, detail: "via " + redactURL(proxy.String()),
+        notes: []string{"applications using WithHTTPClient must configure the proxy themselves"}}
+}
+
+// dnsCheck resolves the host the SDK connects to: the proxy's, if any.
+func dnsCheck(ctx context.Context, u, proxy *url.URL, timeout time.Duration) check {
+    host := u.Hostname()
+    if proxy != nil {
+        host = proxy.Hostname()
+    }
+    if net.ParseIP(host) != nil {
+        return check{name: "dns", status: statusSkip, detail: host + " is an IP address"}
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+    if err != nil {
+        return check{name: "dns", status: statusFail, detail: err.Error()}
+    }
+    return check{name: "dns", status: statusOK, detail: host + " resolves to " + strings.Join(addrs, ", ")}
+}
+
+// tlsCheck performs a TLS handshake with the endpoint and reports the
+// certificate. It is skipped behind a proxy, where the endpoint check
+// covers TLS through the tunnel.
+func tlsCheck(ctx context.Context, u, proxy *url.URL, timeout time.Duration) check {
+    switch {
+    case u.Scheme != "https":
+        return check{name: "tls", status: statusWarn, detail: "endpoint is not https; events are sent unencrypted"}
+    case proxy != nil:
+        return check{name: "tls", status: statusSkip, detail: "connecting through a proxy; see the endpoint check"}
+    }
+    addr := u.Host
+    if u.Port() == "" {
+        addr = net.JoinHostPort(u.Hostname(), "443")
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    d := tls.Dialer{Config: &tls.Config{ServerName: u.Hostname()}}
+    conn, err := d.DialContext(ctx, "tcp", addr)
+    if err != nil {
+        c := check{name: "tls", status: statusFail, detail: err.Error()}
+        var unknown x509.UnknownAuthorityError
+        if errors.As(err, &unknown) {
+            c.notes = []string{"the certificate isn't trusted; a TLS-intercepting proxy may need its CA added with WithCACert or SSL_CERT_FILE"}
+        }
+        return c
+    }
+    defer conn.Close()
+    state := conn.(*tls.Conn).ConnectionState()
+    cert := state.PeerCertificates[0]
+    c := check{name: "tls", status: statusOK, detail: fmt.Sprintf("%s, certificate for %s valid until %s",
+        tls.VersionName(state.Version), cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.DateOnly))}
+    if time.Until(cert.NotAfter) < certExpiryWarning {
+        c.status = statusWarn
+    }
+    return c
+}
+
+// pingCheck sends the endpoint a HEAD request through the logger, which
+// records no event.
+func pingCheck(ctx context.Context, logger *scarf.ScarfEventLogger) check {
+    err := logger.Ping(ctx)
+    var netErr *scarf.NetworkError
+    var epErr *scarf.EndpointError
+    switch {
+    case err == nil:
+        return check{name: "endpoint", status: statusOK, detail: "reachable"}
+    case errors.Is(err, scarf.ErrDisabled), errors.Is(err, scarf.ErrNoConsent):
+        return check{name: "endpoint", status: statusSkip, detail: "not contacted while analytics are disabled"}
+    case errors.As(err, &epErr) && (epErr.StatusCode == http.StatusUnauthorized || epErr.StatusCode == http.StatusForbidden):
+        return check{name: "endpoint", status: statusFail, detail: err.Error(), notes: []string{"check SCARF_API_KEY"}}
+    case errors.As(err, &netErr):
+        return check{name: "endpoint", status: statusFail, detail: err.Error(), notes: []string{"the endpoint could not be reached; check the network, proxy and firewall"}}
+    }
+    return check{name: "endpoint", status: statusFail, detail: err.Error()}
+}
+
+// spoolCheck looks for events left in a FileTransport file and its rotated
+// backups.
+func spoolCheck(path string) check {
+    if path == "" {
+        return check{name: "spool", status: statusSkip, detail: "no -spool file given"}
+    }
+    var files []string
+    if _, err := os.Stat(path); err == nil {
+        files = append(files, path)
+    } else if !errors.Is(err, os.ErrNotExist) {
+        return check{name: "spool", status: statusFail, detail: err.Error()}
+    }
+    files = append(files, spoolBackups(path)...)
+    if len(files) == 0 {
+        return check{name: "spool", status: statusOK, detail: "empty"}
+    }
+
+    var events, invalid int
+    var size int64
+    var oldest time.Time
+    for _, f := range files {
+        s, err := scanSpool(f)
+        if err != nil {
+            return check{name: "spool", status: statusFail, detail: err.Error()}
+        }
+        events, invalid, size = events+s.events, invalid+s.invalid, size+s.size
+        if !s.oldest.IsZero() && (oldest.IsZero() || s.oldest.Before(oldest)) {
+            oldest = s.oldest
+        }
+    }
+    c := check{name: "spool", status: statusOK, detail: fmt.Sprintf("%d events in %d files (%d bytes)", events, len(files), size)}
+    if events > 0 {
+        c.status = statusWarn
+        c.notes = append(c.notes, "events are waiting to be sent; replay them with scarf-replay")
+    }
+    if !oldest.IsZero() {
+        c.notes = append(c.notes, "oldest event from "+oldest.UTC().Format(time.RFC3339))
+    }
+    if invalid > 0 {
+        c.status = statusFail
+        c.notes = append(c.notes, fmt.Sprintf("invalid or corrupted lines: %d", invalid))
+    }
+    return c
+}
+
+// spoolBackups returns the rotated files FileTransport keeps next to path,
+// path.1, path.2 and so on, in order.
+func spoolBackups(path string) []string {
+    var files []string
+    for i := 1; ; i++ {
+        name := fmt.Sprintf("%s.%d", path, i)
+        if _, err := os.Stat(name); err != nil {
+            return files
+        }
+        files = append(files, name)
+    }
+}
+
+// spoolStats summarizes one spool file.
+type spoolStats struct {
+    events, invalid int
+    size            int64
+    oldest          time.Time
+}
+
+func scanSpool(path string) (spoolStats, error) {
+    var s spoolStats
+    f, err := os.Open(path)
+    if err != nil {
+        return s, err
+    }
+    defer f.Close()
+    if info, err := f.Stat(); err == nil {
+        s.size = info.Size()
+    }
+    er := scarf.NewEventReader(f)
+    for {
+        ev, err := er.Next()
+        var lineErr *scarf.ExportLineError
+        switch {
+        case err == io.EOF:
+            return s, nil
+        case errors.As(err, &lineErr):
+            s.invalid++
+        case err != nil:
+            return s, fmt.Errorf("%s: %w", path, err)
+        default:
+            s.events++
+            if !ev.Timestamp.IsZero() && (s.oldest.IsZero() || ev.Timestamp.Before(s.oldest)) {
+                s.oldest = ev.Timestamp
+            }
+        }
+    }
+}
+
+// endpointOf returns the logger's effective endpoint URL.
+func endpointOf(logger *scarf.ScarfEventLogger) string {
+    for _, st := range logger.ResolvedConfig() {
+        if st.Key == "endpoint_url" {
+            return st.Value
+        }
+    }
+    return ""
+}
+
+// redactURL hides the password in a URL with credentials, such as a proxy
+// setting; other values are returned unchanged.
+func redactURL(v string) string {
+    u, err := url.Parse(v)
+    if err != nil || u.User == nil {
+        return v
+    }
+    return u.Redacted()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "net/http/httptest"
+    "os"
+    "regexp"
+    "strings"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+// runDoctor runs "scarf doctor" and returns its exit status and report.
+func runDoctor(t *testing.T, args ...string) (int, string) {
+    t.Helper()
+    var stdout, stderr bytes.Buffer
+    code := run(context.Background(), append([]string{"doctor"}, args...), &stdout, &stderr)
+    return code, stdout.String() + stderr.String()
+}
+
+// wantLine fails unless report has a check line for name with status and
+// a detail matching pattern.
+func wantLine(t *testing.T, report string, status checkStatus, name, pattern string) {
+    t.Helper()
+    re := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(string(status)) + ` +` + name + ` +.*` + pattern)
+    if !re.MatchString(report) {
+        t.Errorf("expected a %s %s check matching %q, got:\n%s", status, name, pattern, report)
+    }
+}
+
+func TestDoctor(t *testing.T) {
+    c := scarftest.NewCollector(t)
+    t.Setenv("SCARF_ENDPOINT_URL", c.URL)
+    t.Setenv("SCARF_API_KEY", "secret")
+    code, report := runDoctor(t)
+    if code != 0 {
+        t.Fatalf("expected no problems, got exit status %d:\n%s", code, report)
+    }
+    wantLine(t, report, statusOK, "environment", "2 relevant variables set")
+    wantLine(t, report, statusOK, "analytics", "enabled")
+    wantLine(t, report, statusWarn, "tls", "not https")
+    wantLine(t, report, statusOK, "endpoint", "reachable")
+    wantLine(t, report, statusSkip, "spool", "no -spool file")
+    if strings.Contains(report, "secret") || !strings.Contains(report, "endpoint_url = "+c.URL+" (env)") {
+        t.Fatalf("expected the resolved config with the API key redacted, got:\n%s", report)
+    }
+    if len(c.Events()) != 0 {
+        t.Fatalf("expected no events to be recorded, got %+v", c.Events())
+    }
+}
+
+func TestDoctorProblems(t *testing.T) {
+    t.Setenv("SCARF_ENDPOINT_URL", "")
+    code, report := runDoctor(t)
+    wantLine(t, report, statusFail, "configuration", "endpoint URL is required")
+    if code != 1 || !strings.Contains(report, "1 problem found") {
+        t.Fatalf("expected one problem, got exit status %d:\n%s", code, report)
+    }
+
+    other := scarftest.NewCollector(t)
+    t.Setenv("SCARF_API_KEY", "secret")
+    t.Setenv("SCARF_TIMEOUT", "7s")
+    _, report = runDoctor(t, "-endpoint", other.URL)
+    for _, want := range []string{"endpoint_url = " + other.URL + " (code)", "api_key = REDACTED (env)", "timeout = 7s (env)"} {
+        if !strings.Contains(report, want) {
+            t.Fatalf("expected -endpoint to keep the rest of the configuration (%q), got:\n%s", want, report)
+        }
+    }
+
+    c := scarftest.NewCollector(t)
+    t.Setenv("DO_NOT_TRACK", "1")
+    _, report = runDoctor(t, "-endpoint", c.URL)
+    wantLine(t, report, statusFail, "analytics", "DO_NOT_TRACK is set")
+    wantLine(t, report, statusSkip, "endpoint", "not contacted")
+    if c.Requests() != 0 {
+        t.Fatal("expected a disabled logger not to contact the endpoint")
+    }
+
+    t.Setenv("DO_NOT_TRACK", "")
+    c.SetStatus(401)
+    _, report = runDoctor(t, "-endpoint", c.URL)
+    wantLine(t, report, statusFail, "endpoint", "401")
+    if !strings.Contains(report, "check SCARF_API_KEY") {
+        t.Fatalf("expected an API key hint, got:\n%s", report)
+    }
+}
+
+func TestDoctorTLS(t *testing.T) {
+    srv := httptest.NewTLSServer(nil)
+    defer srv.Close()
+    _, report := runDoctor(t, "-endpoint", srv.URL)
+    wantLine(t, report, statusFail, "tls", "certificate")
+    if !strings.Contains(report, "WithCACert") {
+        t.Fatalf("expected a CA hint for an untrusted certificate, got:\n%s", report)
+    }
+}
+
+func TestDoctorSpool(t *testing.T) {
+    c := scarftest.NewCollector(t)
+    path := t.TempDir() + "/events.ndjson"
+    ft, err := scarf.NewFileTransport(path, 80, 2)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, name := range []string{"a", "b", "c"} {
+        ft.Send(context.Background(), scarf.Event{Name: name, ID: strings.Repeat(name, 40)})
+    }
+    ft.Close()
+    _, report := runDoctor(t, "-endpoint", c.URL, "-spool", path)
+    wantLine(t, report, statusWarn, "spool", "3 events in 3 files")
+    if !strings.Contains(report, "scarf-replay") {
+        t.Fatalf("expected a replay hint, got:\n%s", report)
+    }
+
+    os.WriteFile(path+".1", []byte("garbage\n"), 0o600)
+    _, report = runDoctor(t, "-endpoint", c.URL, "-spool", path)
+    wantLine(t, report, statusFail, "spool", "2 events in 3 files")
+    if !strings.Contains(report, "invalid or corrupted lines: 1") {
+        t.Fatalf("expected the invalid line to be reported, got:\n%s", report)
+    }
+}
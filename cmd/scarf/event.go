@@ -0,0 +1,152 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// propsFlag collects repeated k=v flags into event properties.
+type propsFlag struct {
+    props map[string]any
+    json  bool // values are JSON rather than plain strings
+}
+
+func (p *propsFlag) String() string { return "" }
+
+func (p *propsFlag) Set(kv string) error {
+    k, v, ok := strings.Cut(kv, "=")
+    if !ok || k == "" {
+        return errors.New("must be key=value")
+    }
+    if !p.json {
+        p.props[k] = v
+        return nil
+    }
+    var x any
+    if err := json.Unmarshal([]byte(v), &x); err != nil {
+        return fmt.Errorf("invalid JSON for %q: %w", k, err)
+    }
+    p.props[k] = x
+    return nil
+}
+
+// eventSend implements "scarf event send": the event is named by its
+// argument or an "event" property, and sent synchronously.
+func eventSend(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+    props := map[string]any{}
+    var endpoint, apiKey string
+    var timeout time.Duration
+    var dryRun, verbose bool
+    fs := flag.NewFlagSet("scarf event send", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    fs.Usage = func() {
+        fmt.Fprintln(stderr, "usage: scarf event send [flags] [name]")
+        fs.PrintDefaults()
+    }
+    fs.StringVar(&endpoint, "endpoint", os.Getenv("SCARF_ENDPOINT_URL"), "event collection endpoint `URL`")
+    fs.StringVar(&apiKey, "api-key", os.Getenv("SCARF_API_KEY"), "API `key` sent as a bearer token")
+    fs.Var(&propsFlag{props: props}, "prop", "add a string property `key=value` (repeatable)")
+    fs.Var(&propsFlag{props: props, json: true}, "prop-json", "add a property `key=json`, e.g. count=3 (repeatable)")
+    fs.DurationVar(&timeout, "timeout", 5*time.Second, "request timeout")
+    fs.BoolVar(&dryRun, "dry-run", false, "print the request instead of sending it")
+    fs.BoolVar(&verbose, "v", false, "log what the SDK does to standard error")
+
+    // Flags may follow the event name.
+    var names []string
+    for {
+        if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+            return 0
+        } else if err != nil {
+            return 2
+        }
+        if fs.NArg() == 0 {
+            break
+        }
+        names, args = append(names, fs.Arg(0)), fs.Args()[1:]
+    }
+    switch {
+    case len(names) > 1:
+        fmt.Fprintln(stderr, "scarf: event send takes at most one event name")
+        return 2
+    case len(names) == 1:
+        props["event"] = names[0]
+    case props["event"] == nil:
+        fmt.Fprintln(stderr, "scarf: no event name: pass it as an argument or with -prop event=name")
+        return 2
+    }
+    if strings.TrimSpace(endpoint) == "" {
+        fmt.Fprintln(stderr, "scarf: no endpoint: set -endpoint or SCARF_ENDPOINT_URL")
+        return 2
+    }
+
+    opts := []scarf.Option{scarf.WithTimeout(timeout), scarf.WithVerbose(verbose)}
+    if apiKey != "" {
+        opts = append(opts, scarf.WithAPIKey(apiKey))
+    }
+    logger := scarf.New(endpoint, opts...)
+    if reason := logger.DisabledReason(); reason != "" {
+        if verbose {
+            fmt.Fprintln(stderr, "scarf: analytics disabled, not sending:", reason)
+        }
+        return 0
+    }
+
+    if dryRun {
+        ev := scarf.Event{Properties: props}
+        ev.Name, _ = props["event"].(string)
+        delete(props, "event")
+        req, err := logger.PrepareRequest(ctx, ev)
+        if err != nil {
+            fmt.Fprintln(stderr, "scarf:", err)
+            return 1
+        }
+        printRequest(stdout, req)
+        return 0
+    }
+    if err := logger.LogEventContext(ctx, props); err != nil {
+        fmt.Fprintln(stderr, "scarf:", err)
+        return 1
+    }
+    return 0
+}
+
+// redactedHeaders carry credentials, or values derived from them, that
+// printRequest leaves out so dry-run output can be pasted into bug reports
+// and CI logs.
+var redactedHeaders = map[string]bool{
+    "Authorization":                true,
+    scarf.SignatureHeader:          true,
+    scarf.SignatureTimestampHeader: true,
+}
+
+// printRequest writes req as an HTTP/1 style request, with credentials
+// redacted.
+func printRequest(w io.Writer, req scarf.PreparedRequest) {
+    fmt.Fprintf(w, "%s %s\n", req.Method, req.URL)
+    keys := make([]string, 0, len(req.Header))
+    for k := range req.Header {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        for _, v := range req.Header[k] {
+            if redactedHeaders[k] {
+                v = "REDACTED"
+            }
+            fmt.Fprintf(w, "%s: %s\n", k, v)
+        }
+    }
+    if len(req.Body) > 0 {
+        fmt.Fprintf(w, "\n%s\n", req.Body)
+    }
+}
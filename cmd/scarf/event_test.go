@@ -0,0 +1,90 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+func TestEventSend(t *testing.T) {
+    c := scarftest.NewCollector(t)
+    t.Setenv("SCARF_ENDPOINT_URL", c.URL)
+    var stdout, stderr bytes.Buffer
+    args := []string{"event", "send", "deploy", "-prop", "env=prod", "-prop-json", "count=3", "-prop-json", `tags=["a"]`}
+    if code := run(context.Background(), args, &stdout, &stderr); code != 0 {
+        t.Fatalf("exit status %d: %s", code, stderr.String())
+    }
+    got := c.Events()
+    if len(got) != 1 || got[0].Name != "deploy" || got[0].Properties["env"] != "prod" || got[0].Properties["count"] != "3" || got[0].Properties["tags"] != `["a"]` {
+        t.Fatalf("unexpected events: %+v", got)
+    }
+
+    c.Reset()
+    if code := run(context.Background(), []string{"event", "send", "-prop", "event=ci_run"}, &stdout, &stderr); code != 0 {
+        t.Fatalf("exit status %d: %s", code, stderr.String())
+    }
+    if got := c.Events(); len(got) != 1 || got[0].Name != "ci_run" {
+        t.Fatalf("expected the name from the event property, got %+v", got)
+    }
+}
+
+func TestEventSendFailures(t *testing.T) {
+    c := scarftest.NewCollector(t)
+    tests := []struct {
+        args []string
+        code int
+        out  string
+    }{
+        {[]string{"-endpoint", c.URL}, 2, "no event name"},
+        {[]string{"-endpoint", "", "x"}, 2, "no endpoint"},
+        {[]string{"-endpoint", c.URL, "-prop", "novalue", "x"}, 2, "must be key=value"},
+        {[]string{"-endpoint", c.URL, "-prop-json", "n={", "x"}, 2, "invalid JSON"},
+        {[]string{"-endpoint", c.URL, "x", "y"}, 2, "at most one event name"},
+    }
+    for _, tt := range tests {
+        var stdout, stderr bytes.Buffer
+        if code := eventSend(context.Background(), tt.args, &stdout, &stderr); code != tt.code || !strings.Contains(stderr.String(), tt.out) {
+            t.Errorf("eventSend(%q) = %d, %q; want %d with %q", tt.args, code, stderr.String(), tt.code, tt.out)
+        }
+    }
+
+    c.SetStatus(500)
+    var stdout, stderr bytes.Buffer
+    if code := eventSend(context.Background(), []string{"-endpoint", c.URL, "x"}, &stdout, &stderr); code != 1 {
+        t.Fatalf("expected a failed send to exit 1, got %d", code)
+    }
+}
+
+func TestEventSendDisabled(t *testing.T) {
+    c := scarftest.NewCollector(t)
+    t.Setenv("DO_NOT_TRACK", "1")
+    var stdout, stderr bytes.Buffer
+    if code := eventSend(context.Background(), []string{"-endpoint", c.URL, "-v", "x"}, &stdout, &stderr); code != 0 {
+        t.Fatalf("expected an opt-out to succeed, got %d", code)
+    }
+    if c.Requests() != 0 || !strings.Contains(stderr.String(), "analytics disabled") {
+        t.Fatalf("expected nothing sent and a notice, got %d requests and %q", c.Requests(), stderr.String())
+    }
+}
+
+func TestEventSendDryRun(t *testing.T) {
+    c := scarftest.NewCollector(t)
+    var stdout, stderr bytes.Buffer
+    if code := eventSend(context.Background(), []string{"-endpoint", c.URL, "-dry-run", "-prop", "k=v", "x"}, &stdout, &stderr); code != 0 {
+        t.Fatalf("exit status %d: %s", code, stderr.String())
+    }
+    if c.Requests() != 0 || !strings.HasPrefix(stdout.String(), "POST "+c.URL) || !strings.Contains(stdout.String(), "event=x") {
+        t.Fatalf("expected the request printed and not sent, got %q", stdout.String())
+    }
+
+    stdout.Reset()
+    if code := eventSend(context.Background(), []string{"-endpoint", c.URL, "-api-key", "secret", "-dry-run", "x"}, &stdout, &stderr); code != 0 {
+        t.Fatalf("exit status %d: %s", code, stderr.String())
+    }
+    if strings.Contains(stdout.String(), "secret") || !strings.Contains(stdout.String(), "Authorization: REDACTED") {
+        t.Fatalf("expected the API key redacted, got %q", stdout.String())
+    }
+}
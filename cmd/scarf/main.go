@@ -0,0 +1,60 @@
+// Command scarf sends telemetry from shell scripts, Makefiles and CI jobs
+// using the scarf package, without writing Go.
+//
+// Usage:
+//
+//   scarf event send [flags] [name]
+//   scarf doctor [flags]
+//
+// The endpoint defaults to SCARF_ENDPOINT_URL and the API key to
+// SCARF_API_KEY. DO_NOT_TRACK and SCARF_NO_ANALYTICS are honored: when
+// analytics are disabled, nothing is sent and the command still succeeds, so
+// scripts needn't check for opt-outs. Run a command with -h for its flags.
+package main
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "os/signal"
+)
+
+func main() {
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
+    os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+const usage = `usage: scarf <command> [arguments]
+
+commands:
+  event send    send an event
+  doctor        diagnose why events aren't arriving
+`
+
+// run executes the command line args and returns the exit status: 0 on
+// success, 1 if the command failed and 2 for usage errors.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+    if len(args) == 0 {
+        fmt.Fprint(stderr, usage)
+        return 2
+    }
+    switch args[0] {
+    case "event":
+        if len(args) > 1 && args[1] == "send" {
+            return eventSend(ctx, args[2:], stdout, stderr)
+        }
+    case "doctor":
+        return doctor(ctx, args[1:], stdout, stderr)
+    case "help", "-h", "-help", "--help":
+        fmt.Fprint(stdout, usage)
+        return 0
+    }
+    name := args[0]
+    if name == "event" && len(args) > 1 {
+        name += " " + args[1]
+    }
+    fmt.Fprintf(stderr, "scarf: unknown command %q\n%s", name, usage)
+    return 2
+}
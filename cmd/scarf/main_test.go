@@ -0,0 +1,28 @@
+package main
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+)
+
+func TestRunUsage(t *testing.T) {
+    tests := []struct {
+        args []string
+        code int
+        out  string
+    }{
+        {nil, 2, "usage: scarf"},
+        {[]string{"help"}, 0, "event send"},
+        {[]string{"event", "list"}, 2, `unknown command "event list"`},
+        {[]string{"frobnicate", "-x"}, 2, `unknown command "frobnicate"`},
+    }
+    for _, tt := range tests {
+        var stdout, stderr bytes.Buffer
+        code := run(context.Background(), tt.args, &stdout, &stderr)
+        if code != tt.code || !strings.Contains(stdout.String()+stderr.String(), tt.out) {
+            t.Errorf("run(%q) = %d, %q; want %d with %q", tt.args, code, stdout.String()+stderr.String(), tt.code, tt.out)
+        }
+    }
+}
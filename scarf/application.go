@@ -0,0 +1,102 @@
+package scarf
+
+import (
+    "sort"
+    "strings"
+)
+
+// WithApplication identifies the tool embedding the SDK, so Scarf can tell
+// apart the downstream tools that report through it. name and version lead
+// the User-Agent, as in "myapp/1.4.2 scarf-go/v0.1.0 (platform=linux; ...)",
+// and are attached to every event as the default properties "app_name" and
+// "app_version". version, which may be empty, is also the "version" of the
+// standard events (see LogInstallEvent).
+//
+// In the User-Agent, characters not allowed in an HTTP token are replaced
+// with "-". WithAppName, which namespaces persisted state, is separate.
+func WithApplication(name, version string) Option {
+    return func(s *ScarfEventLogger) {
+        name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+        if name == "" {
+            return
+        }
+        s.appVersion = version
+        s.uaProduct = httpToken(name)
+        if version != "" {
+            s.uaProduct += "/" + httpToken(version)
+        }
+        s.defaults.update(func(m map[string]any) {
+            m["app_name"] = name
+            if version != "" {
+                m["app_version"] = version
+            }
+        })
+    }
+}
+
+// WithUserAgentExtras adds key=value pairs to the comment of the User-Agent,
+// after the SDK's own, as in "scarf-go/v0.1.0 (platform=linux; arch=amd64;
+// go=1.22.3; distro=homebrew)". Pairs appear sorted by key. Keys must be HTTP
+// tokens other than platform, arch and go, and values non-empty printable
+// ASCII without ";", "(", ")" or "\\"; invalid pairs are dropped with a
+// warning. Like other options, a later call replaces an earlier one.
+func WithUserAgentExtras(extras map[string]string) Option {
+    return func(s *ScarfEventLogger) {
+        keys := make([]string, 0, len(extras))
+        for k := range extras {
+            keys = append(keys, k)
+        }
+        sort.Strings(keys)
+
+        var b strings.Builder
+        s.uaRejected = nil
+        for _, k := range keys {
+            if !validUserAgentExtra(k, extras[k]) {
+                s.uaRejected = append(s.uaRejected, k)
+                continue
+            }
+            b.WriteString("; " + k + "=" + extras[k])
+        }
+        s.uaExtras = b.String()
+    }
+}
+
+// validUserAgentExtra reports whether k=v can be added to the User-Agent
+// comment without breaking its structure or the header.
+func validUserAgentExtra(k, v string) bool {
+    switch k {
+    case "", "platform", "arch", "go":
+        return false
+    }
+    if httpToken(k) != k || v == "" {
+        return false
+    }
+    for i := 0; i < len(v); i++ {
+        if c := v[i]; c < 0x20 || c > 0x7e || strings.IndexByte(";()\\", c) >= 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// userAgent returns the User-Agent for the logger's requests.
+func (s *ScarfEventLogger) userAgent() string {
+    if s.uaProduct == "" {
+        return buildUserAgent(s.uaExtras)
+    }
+    return s.uaProduct + " " + buildUserAgent(s.uaExtras)
+}
+
+// httpToken replaces the characters of v that may not appear in an HTTP
+// token (RFC 9110) with "-".
+func httpToken(v string) string {
+    return strings.Map(func(r rune) rune {
+        switch {
+        case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+            return r
+        case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+            return r
+        }
+        return '-'
+    }, v)
+}
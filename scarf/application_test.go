@@ -0,0 +1,66 @@
+package scarf
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestWithApplication(t *testing.T) {
+    var ua string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ua = r.Header.Get("User-Agent")
+    }))
+    defer srv.Close()
+
+    var got []Event
+    l := New(srv.URL, WithApplication("my tool", "1.4.2"))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if !strings.HasPrefix(ua, "my-tool/1.4.2 scarf-go/") || !strings.Contains(ua, "(platform=") {
+        t.Fatalf("expected the app to lead the User-Agent, got %q", ua)
+    }
+
+    l = New("", WithApplication("mytool", "1.4.2"), captureEvents(&got))
+    _ = l.LogEvent(map[string]any{"event": "x", "app_version": "override"})
+    _ = l.LogInstallEvent(nil)
+    if got[0].Properties["app_name"] != "mytool" || got[0].Properties["app_version"] != "override" {
+        t.Fatalf("expected app properties that events can override, got %v", got[0].Properties)
+    }
+    if got[1].Properties["version"] != "1.4.2" {
+        t.Fatalf("expected the app version in standard events, got %v", got[1].Properties)
+    }
+}
+
+func TestWithApplicationNoVersion(t *testing.T) {
+    l := New("", WithApplication("mytool", ""))
+    if ua := l.userAgent(); !strings.HasPrefix(ua, "mytool scarf-go/") {
+        t.Fatalf("unexpected User-Agent %q", ua)
+    }
+    if ua := New("", WithApplication(" ", "1.0")).userAgent(); !strings.HasPrefix(ua, "scarf-go/") {
+        t.Fatalf("expected an empty name to be ignored, got %q", ua)
+    }
+}
+
+func TestWithUserAgentExtras(t *testing.T) {
+    l := New("", WithUserAgentExtras(map[string]string{
+        "distro":  "homebrew",
+        "channel": "stable",
+        "bad key": "x",
+        "arch":    "override",
+        "inject":  "a)\r\nX-Evil: 1",
+        "empty":   "",
+    }))
+    ua := l.userAgent()
+    if !strings.HasSuffix(ua, "; channel=stable; distro=homebrew)") {
+        t.Fatalf("expected sorted extras at the end of the comment, got %q", ua)
+    }
+    if strings.ContainsAny(ua, "\r\n") || strings.Contains(ua, "override") {
+        t.Fatalf("expected invalid extras to be dropped, got %q", ua)
+    }
+    if want := []string{"arch", "bad key", "empty", "inject"}; strings.Join(l.uaRejected, ",") != strings.Join(want, ",") {
+        t.Fatalf("expected rejected keys %v, got %v", want, l.uaRejected)
+    }
+}
@@ -0,0 +1,115 @@
+package scarf
+
+import "context"
+
+// Receipt reports the outcome of an event logged with LogEventAsync.
+type Receipt struct {
+    done chan struct{}
+    err  error
+}
+
+// Done returns a channel that is closed once delivery has finished, after
+// any fallback endpoints have been tried.
+func (r *Receipt) Done() <-chan struct{} {
+    return r.done
+}
+
+// Err blocks until delivery has finished and returns the error LogEvent
+// would have returned: nil if the event was delivered (or sampled out), or a
+// typed delivery error otherwise. To wait with a deadline, select on Done
+// first.
+func (r *Receipt) Err() error {
+    <-r.done
+    return r.err
+}
+
+func (r *Receipt) finish(err error) {
+    r.err = err
+    close(r.done)
+}
+
+// LogEventAsync sends an event in the background using the logger's default
+// timeout and returns immediately with a Receipt for the outcome, for callers
+// that don't want to block on the network but need to know whether a critical
+// event landed:
+//
+//   r := logger.LogEventAsync(map[string]any{"event": "license_activated"})
+//   // ...
+//   select {
+//   case <-r.Done():
+//       if err := r.Err(); err != nil { ... }
+//   case <-time.After(time.Second):
+//   }
+//
+// The event is filtered, enriched and sampled before LogEventAsync returns,
+// so properties may be reused afterwards, and events that are refused
+// (disabled, closed, or over the WithMaxPending limit) return an
+// already-finished Receipt. Flush and Close wait for background sends. See
+// WithBatching to send async events together. In serverless mode the event is
+// sent before LogEventAsync returns, without batching.
+func (s *ScarfEventLogger) LogEventAsync(properties map[string]any) *Receipt {
+    return s.LogEventAsyncContext(context.Background(), properties)
+}
+
+// LogEventAsyncContext is LogEventAsync with a context for the event's
+// enrichment and trace context and for its send, which keeps it from being
+// canceled: pass context.WithoutCancel(r.Context()) for events that should
+// outlive the request they describe.
+func (s *ScarfEventLogger) LogEventAsyncContext(ctx context.Context, properties map[string]any) *Receipt {
+    r := &Receipt{done: make(chan struct{})}
+    if s == nil {
+        r.finish(nil)
+        return r
+    }
+    if refused, err := s.refuse(); refused {
+        r.finish(err)
+        return r
+    }
+    if err := s.startSend(); err != nil {
+        r.finish(err)
+        return r
+    }
+    ev, ok := s.prepare(ctx, eventFromProperties(properties))
+    if !ok {
+        s.inflight.done()
+        r.finish(nil)
+        return r
+    }
+    if err := s.checkStrict(ev); err != nil {
+        s.inflight.done()
+        r.finish(err)
+        return r
+    }
+    if s.serverless {
+        // The environment may be frozen as soon as the handler returns, so
+        // serverless mode sends inline instead of in the background.
+        defer s.inflight.done()
+        r.finish(s.deliver(ctx, ev, s.defaultTimeout, s.dispatch))
+        return r
+    }
+    if s.batched(ev) {
+        s.enqueue(ev, r)
+        return r
+    }
+    go func() {
+        defer s.inflight.done()
+        r.finish(s.deliver(ctx, ev, s.defaultTimeout, s.dispatch))
+    }()
+    return r
+}
+
+// LogAsync logs an event through logger without blocking the caller, for
+// integrations that report every request or log record. A logger with a
+// LogEventAsyncContext method, such as *ScarfEventLogger, accounts for the
+// send before LogAsync returns, so Flush and Close wait for it and
+// WithMaxPending bounds how many are in flight; other EventLogger
+// implementations are called on a new goroutine.
+func LogAsync(ctx context.Context, logger EventLogger, properties map[string]any) {
+    if a, ok := logger.(interface {
+        LogEventAsyncContext(context.Context, map[string]any) *Receipt
+    }); ok {
+        a.LogEventAsyncContext(ctx, properties)
+        return
+    }
+    go logger.LogEventContext(ctx, properties)
+}
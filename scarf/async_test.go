@@ -0,0 +1,145 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestLogEventAsync(t *testing.T) {
+    release := make(chan struct{})
+    var fail atomic.Bool
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Query().Get("event") == "slow" {
+            <-release
+        }
+        if fail.Load() {
+            w.WriteHeader(http.StatusInternalServerError)
+        }
+    }))
+    defer srv.Close()
+    l := New(srv.URL)
+
+    props := map[string]any{"event": "slow"}
+    r := l.LogEventAsync(props)
+    props["event"] = "changed"
+    select {
+    case <-r.Done():
+        t.Fatal("expected LogEventAsync not to wait for delivery")
+    case <-time.After(20 * time.Millisecond):
+    }
+    if l.Pending() != 1 {
+        t.Fatalf("expected one pending send, got %d", l.Pending())
+    }
+    close(release)
+    if err := r.Err(); err != nil {
+        t.Fatalf("expected the event to be delivered, got %v", err)
+    }
+
+    fail.Store(true)
+    var epErr *EndpointError
+    if err := l.LogEventAsync(nil).Err(); !errors.As(err, &epErr) || epErr.StatusCode != http.StatusInternalServerError {
+        t.Fatalf("expected an EndpointError, got %v", err)
+    }
+}
+
+func TestLogEventAsyncFlush(t *testing.T) {
+    var got atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(10 * time.Millisecond)
+        got.Add(1)
+    }))
+    defer srv.Close()
+    l := New(srv.URL)
+
+    receipts := make([]*Receipt, 5)
+    for i := range receipts {
+        receipts[i] = l.LogEventAsync(map[string]any{"i": i})
+    }
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if got.Load() != 5 {
+        t.Fatalf("expected Close to wait for 5 sends, got %d", got.Load())
+    }
+    for _, r := range receipts {
+        select {
+        case <-r.Done():
+        default:
+            t.Fatal("expected every receipt to be finished after Close")
+        }
+    }
+    if err := l.LogEventAsync(nil).Err(); !errors.Is(err, ErrClosed) {
+        t.Fatalf("expected ErrClosed after Close, got %v", err)
+    }
+}
+
+func TestLogEventAsyncRefused(t *testing.T) {
+    l := New("http://127.0.0.1:1", WithEnabled(false))
+    r := l.LogEventAsync(nil)
+    select {
+    case <-r.Done():
+    default:
+        t.Fatal("expected a refused event's receipt to be finished")
+    }
+    if !errors.Is(r.Err(), ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", r.Err())
+    }
+    if err := NewNoopLogger().LogEventAsync(nil).Err(); err != nil {
+        t.Fatalf("expected nil from a no-op logger, got %v", err)
+    }
+    if err := (NoopLogger{}).LogEventAsync(nil).Err(); err != nil {
+        t.Fatalf("expected nil from NoopLogger, got %v", err)
+    }
+}
+
+// syncLogger is an EventLogger without LogEventAsyncContext.
+type syncLogger struct {
+    events chan map[string]any
+}
+
+func (l syncLogger) LogEvent(props map[string]any) error {
+    return l.LogEventContext(context.Background(), props)
+}
+
+func (l syncLogger) LogEventContext(_ context.Context, props map[string]any) error {
+    l.events <- props
+    return nil
+}
+
+func (syncLogger) Enabled() bool               { return true }
+func (syncLogger) Flush(context.Context) error { return nil }
+func (syncLogger) Close() error                { return nil }
+
+func TestLogAsync(t *testing.T) {
+    type key struct{}
+    var got []Event
+    var seen any
+    enricher := EnricherFunc(func(ctx context.Context) map[string]any {
+        seen = ctx.Value(key{})
+        return nil
+    })
+    l := New("", captureEvents(&got), WithEnrichers(enricher))
+    LogAsync(context.WithValue(context.Background(), key{}, "v"), l, map[string]any{"event": "x"})
+    if err := l.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if len(got) != 1 || seen != "v" {
+        t.Fatalf("expected one event enriched with the caller's context, got %d (%v)", len(got), seen)
+    }
+
+    other := syncLogger{events: make(chan map[string]any, 1)}
+    LogAsync(context.Background(), other, map[string]any{"event": "y"})
+    select {
+    case props := <-other.events:
+        if props["event"] != "y" {
+            t.Fatalf("unexpected event %v", props)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatal("expected other EventLogger implementations to be called")
+    }
+}
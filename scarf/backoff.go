@@ -0,0 +1,98 @@
+package scarf
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// ErrRateLimited is returned when the endpoint has asked the client to back
+// off (HTTP 429, or 503 with Retry-After). Until the requested time has
+// passed, further events fail fast with this error instead of being sent.
+var ErrRateLimited = errors.New("scarf: rate limited by endpoint")
+
+const (
+    // defaultRateLimitBackoff applies to 429 responses without a usable Retry-After.
+    defaultRateLimitBackoff = 30 * time.Second
+    // maxRetryAfter caps server-provided delays so a bad header can't silence
+    // telemetry for the lifetime of the process.
+    maxRetryAfter = time.Hour
+)
+
+// backoffGate pauses sending until a deadline requested by the endpoint.
+type backoffGate struct {
+    mu    sync.Mutex
+    until time.Time
+}
+
+// pausedUntil returns the time sending resumes, or the zero time if not paused.
+func (g *backoffGate) pausedUntil(now time.Time) time.Time {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if now.Before(g.until) {
+        return g.until
+    }
+    return time.Time{}
+}
+
+func (g *backoffGate) pause(now time.Time, d time.Duration) time.Time {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if until := now.Add(d); until.After(g.until) {
+        g.until = until
+    }
+    return g.until
+}
+
+// rateLimitDelay reports how long the endpoint asked us to wait, if resp is a
+// backoff signal.
+func rateLimitDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+    switch resp.StatusCode {
+    case http.StatusTooManyRequests:
+        if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+            return d, true
+        }
+        return defaultRateLimitBackoff, true
+    case http.StatusServiceUnavailable:
+        return parseRetryAfter(resp.Header.Get("Retry-After"), now)
+    }
+    return 0, false
+}
+
+// parseRetryAfter parses a Retry-After header value in either delay-seconds
+// or HTTP-date form, relative to now.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+    v = strings.TrimSpace(v)
+    if v == "" {
+        return 0, false
+    }
+    var d time.Duration
+    if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+        if secs < 0 {
+            return 0, false
+        }
+        if secs > int64(maxRetryAfter/time.Second) {
+            return maxRetryAfter, true
+        }
+        d = time.Duration(secs) * time.Second
+    } else if t, err := http.ParseTime(v); err == nil {
+        d = t.Sub(now)
+        if d < 0 {
+            d = 0
+        }
+    } else {
+        return 0, false
+    }
+    if d > maxRetryAfter {
+        d = maxRetryAfter
+    }
+    return d, true
+}
+
+func rateLimitedError(until time.Time) error {
+    return fmt.Errorf("%w; retry after %s", ErrRateLimited, until.UTC().Format(time.RFC3339))
+}
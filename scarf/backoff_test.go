@@ -0,0 +1,70 @@
+package scarf
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+    now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    cases := []struct {
+        in   string
+        want time.Duration
+        ok   bool
+    }{
+        {"", 0, false},
+        {"120", 2 * time.Minute, true},
+        {"0", 0, true},
+        {"-1", 0, false},
+        {"999999999", maxRetryAfter, true},
+        {now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+        {now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+        {"soon", 0, false},
+    }
+    for _, c := range cases {
+        got, ok := parseRetryAfter(c.in, now)
+        if got != c.want || ok != c.ok {
+            t.Errorf("parseRetryAfter(%q) = %s, %v; want %s, %v", c.in, got, ok, c.want, c.ok)
+        }
+    }
+}
+
+func TestRetryAfter_PausesSending(t *testing.T) {
+    var hits int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&hits, 1)
+        w.Header().Set("Retry-After", "3600")
+        w.WriteHeader(http.StatusTooManyRequests)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL)
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrRateLimited) {
+        t.Fatalf("expected ErrRateLimited, got %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrRateLimited) {
+        t.Fatalf("expected ErrRateLimited while paused, got %v", err)
+    }
+    if got := atomic.LoadInt32(&hits); got != 1 {
+        t.Fatalf("expected no requests while paused, got %d", got)
+    }
+}
+
+func TestServiceUnavailableWithoutRetryAfter(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusServiceUnavailable)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL)
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil || errors.Is(err, ErrRateLimited) {
+        t.Fatalf("expected plain non-success error, got %v", err)
+    }
+    if !l.backoff.pausedUntil(time.Now()).IsZero() {
+        t.Fatalf("503 without Retry-After should not pause sending")
+    }
+}
@@ -0,0 +1,265 @@
+package scarf
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+)
+
+// BatchFormat selects how LogEvents encodes a batch of events.
+type BatchFormat int
+
+const (
+    // BatchJSON sends a batch as a JSON array of event objects.
+    BatchJSON BatchFormat = iota
+    // BatchNDJSON sends a batch as newline-delimited JSON, one event object
+    // per line.
+    BatchNDJSON
+)
+
+// WithBatchFormat sets the encoding LogEvents uses. The default is BatchJSON.
+func WithBatchFormat(f BatchFormat) Option {
+    return func(s *ScarfEventLogger) {
+        s.batchFormat = f
+    }
+}
+
+// LogEvents sends events in a single request to the endpoint URL, each
+// encoded as a JSON object with the same fields as a body-mode event, as a
+// protobuf Batch under EncodingProtobuf, or as a sequence of MessagePack maps
+// under EncodingMessagePack. Every event goes through the same pipeline as
+// LogEvent, and the request fails over to WithFallbackEndpoints like
+// LogEvent's; events that are sampled out are left out of the batch, and
+// nothing is sent if none remain. With a custom Transport or routes, events
+// are delivered one by one instead.
+func (s *ScarfEventLogger) LogEvents(ctx context.Context, events []Event) error {
+    if s == nil {
+        return nil
+    }
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    i := 0
+    next := func() (Event, bool) {
+        if i >= len(events) {
+            return Event{}, false
+        }
+        i++
+        return events[i-1], true
+    }
+    return s.sendBatch(ctx, next, false)
+}
+
+// StreamEvents uploads the events produced by next, until it reports false,
+// as a single NDJSON request (or, under EncodingProtobuf or
+// EncodingMessagePack, in that encoding) whose body is encoded while it is
+// sent, so very large backlogs (e.g. a replayed offline spool) are never held
+// in memory. The upload is bounded by ctx rather than the logger's timeout.
+// The body can't be sent twice, so fallback endpoints are not tried, except
+// with WithRequestSigning, where the body is buffered to compute its
+// signature.
+func (s *ScarfEventLogger) StreamEvents(ctx context.Context, next func() (Event, bool)) error {
+    if s == nil {
+        return nil
+    }
+    return s.sendBatch(ctx, next, true)
+}
+
+func (s *ScarfEventLogger) sendBatch(ctx context.Context, next func() (Event, bool), stream bool) error {
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    if err := s.startSend(); err != nil {
+        return err
+    }
+    defer s.inflight.done()
+
+    var invalid []error
+    prepared := func() (Event, bool) {
+        for ev, ok := next(); ok; ev, ok = next() {
+            if ev, ok = s.prepare(ctx, ev); !ok {
+                continue
+            }
+            if err := s.checkStrict(ev); err != nil {
+                invalid = append(invalid, err)
+                continue
+            }
+            return ev, true
+        }
+        return Event{}, false
+    }
+    err := s.sendPrepared(ctx, prepared, stream)
+    if len(invalid) > 0 {
+        return errors.Join(append(invalid, err)...)
+    }
+    return err
+}
+
+// sendPrepared is sendBatch for events that have already been through
+// prepare.
+func (s *ScarfEventLogger) sendPrepared(ctx context.Context, next func() (Event, bool), stream bool) error {
+    if s.transport != nil || len(s.routes) > 0 {
+        var errs []error
+        for ev, ok := next(); ok; ev, ok = next() {
+            errs = append(errs, s.dispatch(ctx, ev, s.defaultTimeout))
+        }
+        return errors.Join(errs...)
+    }
+    if strings.TrimSpace(s.endpointURL) == "" {
+        s.error("no endpoint URL configured; aborting")
+        return ErrNoEndpoint
+    }
+
+    // Find the first event to send so an empty batch sends nothing.
+    first, found := next()
+    if !found {
+        s.debug("no events to send")
+        return nil
+    }
+
+    if !stream || s.signingKey != nil {
+        // Keep the events, not just the body, so a batch the endpoint
+        // refuses as MessagePack can be resent as JSON.
+        events := []Event{first}
+        for ev, ok := next(); ok; ev, ok = next() {
+            events = append(events, ev)
+        }
+        timeout := s.defaultTimeout
+        if stream {
+            timeout = 0
+        }
+        return s.failover(ctx, func(endpoint string) (int, error) {
+            return s.sendBatchTo(ctx, endpoint, events, stream, timeout)
+        })
+    }
+
+    enc := s.batchEncoding(stream)
+    pr, pw := io.Pipe()
+    written := make(chan struct{})
+    go func() {
+        defer close(written)
+        pw.CloseWithError(s.writeBatch(ctx, pw, enc, first, next))
+    }()
+    req, err := s.newStreamRequest(http.MethodPost, s.endpointURL, pr, enc.contentType)
+    if err != nil {
+        pr.Close()
+        <-written
+        s.error("failed to build request", "error", err)
+        return err
+    }
+    status, err := s.roundTrip(ctx, req, 0)
+    // Unblock the writer if the body wasn't fully consumed.
+    req.Body.Close()
+    pr.CloseWithError(io.ErrClosedPipe)
+    <-written
+    if s.rejectedMsgpack(status, enc.contentType) {
+        // The events have been consumed, so the stream can't be resent.
+        return fmt.Errorf("scarf: stream not delivered; later uploads will use JSON: %w", err)
+    }
+    return err
+}
+
+// sendBatchTo sends events as one request to endpoint, resending them as JSON
+// if the endpoint refuses MessagePack, and returns the response status code,
+// if any.
+func (s *ScarfEventLogger) sendBatchTo(ctx context.Context, endpoint string, events []Event, stream bool, timeout time.Duration) (int, error) {
+    enc := s.batchEncoding(stream)
+    var buf bytes.Buffer
+    i := 1
+    rest := func() (Event, bool) {
+        if i >= len(events) {
+            return Event{}, false
+        }
+        i++
+        return events[i-1], true
+    }
+    if err := s.writeBatch(ctx, &buf, enc, events[0], rest); err != nil {
+        return 0, err
+    }
+    req, err := s.newRequest(http.MethodPost, endpoint, buf.Bytes(), enc.contentType)
+    if err != nil {
+        s.error("failed to build request", "error", err)
+        return 0, err
+    }
+    status, err := s.roundTrip(ctx, req, timeout)
+    if s.rejectedMsgpack(status, enc.contentType) {
+        s.stats.retries.Add(1)
+        return s.sendBatchTo(ctx, endpoint, events, stream, timeout)
+    }
+    return status, err
+}
+
+// batchEncoding describes how a batch body is laid out: the Content-Type,
+// the bytes around and between events, and the encoding of each event.
+type batchEncoding struct {
+    contentType      string
+    open, sep, close string
+    encode           func(Event) ([]byte, error)
+}
+
+// batchEncoding returns the batch layout for the logger's encoding and batch
+// format. Streams can't use a JSON array.
+func (s *ScarfEventLogger) batchEncoding(stream bool) batchEncoding {
+    if s.encoding == EncodingProtobuf {
+        // A Batch message is just its events fields concatenated, so a
+        // one-event Batch per event streams as one large Batch.
+        return batchEncoding{contentType: pb.ContentType, encode: func(ev Event) ([]byte, error) {
+            return (&pb.Batch{Events: []pb.Event{ev.protobuf()}}).Marshal(), nil
+        }}
+    }
+    if s.useMsgpack() {
+        // MessagePack values are self-delimiting, so a batch is simply a
+        // sequence of event maps.
+        return batchEncoding{contentType: msgpackContentType, encode: Event.msgpackBody}
+    }
+    if stream || s.batchFormat == BatchNDJSON {
+        return batchEncoding{contentType: "application/x-ndjson", encode: func(ev Event) ([]byte, error) {
+            b, err := ev.jsonBody()
+            return append(b, '\n'), err
+        }}
+    }
+    return batchEncoding{contentType: "application/json", open: "[", sep: ",", close: "]", encode: Event.jsonBody}
+}
+
+// writeBatch encodes first and then every event from next to w using enc.
+// Events that can't be encoded are skipped.
+func (s *ScarfEventLogger) writeBatch(ctx context.Context, w io.Writer, enc batchEncoding, first Event, next func() (Event, bool)) error {
+    bw := bufio.NewWriter(w)
+    bw.WriteString(enc.open)
+    n := 0
+    write := func(ev Event) error {
+        b, err := enc.encode(ev)
+        if err != nil {
+            s.warn("skipping event that can't be encoded", "event", ev.Name, "error", err)
+            return nil
+        }
+        if n > 0 {
+            bw.WriteString(enc.sep)
+        }
+        bw.Write(b)
+        n++
+        // Flush per event so a streamed body is sent as it is produced.
+        return bw.Flush()
+    }
+    if err := write(first); err != nil {
+        return err
+    }
+    for ev, ok := next(); ok; ev, ok = next() {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+        if err := write(ev); err != nil {
+            return err
+        }
+    }
+    bw.WriteString(enc.close)
+    return bw.Flush()
+}
@@ -0,0 +1,140 @@
+package scarf
+
+import (
+    "bufio"
+    "compress/gzip"
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestLogEvents_JSON(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    l := New(srv.URL)
+    err := l.LogEvents(context.Background(), []Event{
+        {Name: "a", Properties: map[string]any{"n": 1}},
+        {Name: "b"},
+    })
+    if err != nil {
+        t.Fatalf("LogEvents: %v", err)
+    }
+    if len(got) != 1 || got[0].contentType != "application/json" {
+        t.Fatalf("expected one JSON request, got %+v", got)
+    }
+    var events []map[string]any
+    if err := json.Unmarshal(got[0].body, &events); err != nil {
+        t.Fatalf("invalid JSON array: %v (%s)", err, got[0].body)
+    }
+    if len(events) != 2 || events[0]["event"] != "a" || events[0]["n"] != 1.0 || events[1]["event"] != "b" || events[1]["event_id"] == nil {
+        t.Fatalf("unexpected batch: %v", events)
+    }
+}
+
+func TestLogEvents_NDJSONAndSampling(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    l := New(srv.URL, WithBatchFormat(BatchNDJSON), WithSampleKey(func(ev Event) string { return ev.Name }), WithSampleRate(0.5))
+    var events []Event
+    for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+        events = append(events, Event{Name: name})
+    }
+    if err := l.LogEvents(context.Background(), events); err != nil {
+        t.Fatalf("LogEvents: %v", err)
+    }
+    lines := strings.Split(strings.TrimSuffix(string(got[0].body), "\n"), "\n")
+    want := 0
+    for _, ev := range events {
+        if hashFraction(ev.Name) < 0.5 {
+            want++
+        }
+    }
+    if got[0].contentType != "application/x-ndjson" || len(lines) != want {
+        t.Fatalf("expected %d NDJSON lines, got %q (%s)", want, lines, got[0].contentType)
+    }
+
+    got = nil
+    if err := New(srv.URL, WithSampleRate(0)).LogEvents(context.Background(), events); err != nil || len(got) != 0 {
+        t.Fatalf("a batch with no events left should send nothing, got %v, %d requests", err, len(got))
+    }
+}
+
+func TestStreamEvents(t *testing.T) {
+    var lines int
+    var encoding string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        encoding = r.Header.Get("Content-Encoding")
+        var body io.Reader = r.Body
+        if encoding == "gzip" {
+            zr, err := gzip.NewReader(r.Body)
+            if err != nil {
+                w.WriteHeader(http.StatusBadRequest)
+                return
+            }
+            body = zr
+        }
+        sc := bufio.NewScanner(body)
+        for sc.Scan() {
+            var ev map[string]any
+            if json.Unmarshal(sc.Bytes(), &ev) == nil && ev["event"] == "replay" {
+                lines++
+            }
+        }
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+
+    const total = 5000
+    n := 0
+    next := func() (Event, bool) {
+        if n == total {
+            return Event{}, false
+        }
+        n++
+        return Event{Name: "replay", Properties: map[string]any{"i": n}}, true
+    }
+    l := New(srv.URL, WithGzipThreshold(0))
+    if err := l.StreamEvents(context.Background(), next); err != nil {
+        t.Fatalf("StreamEvents: %v", err)
+    }
+    if lines != total || encoding != "gzip" {
+        t.Fatalf("expected %d gzipped lines, got %d (encoding %q)", total, lines, encoding)
+    }
+}
+
+func TestStreamEvents_ServerError(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusInternalServerError)
+    }))
+    defer srv.Close()
+    next := func() (Event, bool) { return Event{Name: "endless"}, true }
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    if err := New(srv.URL).StreamEvents(ctx, next); err == nil {
+        t.Fatalf("expected error from failing server")
+    }
+}
+
+func TestLogEvents_Transport(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+    if err := l.LogEvents(context.Background(), []Event{{Name: "a"}, {Name: "b"}}); err != nil {
+        t.Fatalf("LogEvents: %v", err)
+    }
+    if len(got) != 2 {
+        t.Fatalf("expected events delivered one by one, got %d", len(got))
+    }
+}
+
+func BenchmarkLogEvents_Disabled(b *testing.B) {
+    l := New("https://example.com", WithEnabled(false))
+    events := []Event{{Name: "a", Properties: map[string]any{"n": 1}}, {Name: "b"}}
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        _ = l.LogEvents(context.Background(), events)
+    }
+}
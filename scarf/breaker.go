@@ -0,0 +1,87 @@
+package scarf
+
+import (
+    "errors"
+    "sync"
+    "time"
+)
+
+// ErrCircuitOpen is returned without contacting the endpoint while the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("scarf: circuit breaker open; endpoint recently failing")
+
+// WithCircuitBreaker stops attempting sends for cooldown after threshold
+// consecutive failures (network errors or 5xx responses). Once the cooldown
+// has elapsed a single probe request is let through: if it succeeds sending
+// resumes normally, otherwise the breaker opens for another cooldown.
+//
+// This keeps an unreachable endpoint from adding a full timeout to every
+// user operation.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+    return func(s *ScarfEventLogger) {
+        if threshold <= 0 || cooldown <= 0 {
+            s.breaker = nil
+            return
+        }
+        s.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+    }
+}
+
+type breakerState int
+
+const (
+    breakerClosed breakerState = iota
+    breakerOpen
+    breakerHalfOpen
+)
+
+type circuitBreaker struct {
+    threshold int
+    cooldown  time.Duration
+
+    mu       sync.Mutex
+    state    breakerState
+    failures int
+    openedAt time.Time
+}
+
+// allow reports whether a request may be attempted now. In the half-open
+// state only one probe is allowed until its outcome is recorded.
+func (b *circuitBreaker) allow(now time.Time) bool {
+    if b == nil {
+        return true
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    switch b.state {
+    case breakerOpen:
+        if now.Sub(b.openedAt) < b.cooldown {
+            return false
+        }
+        b.state = breakerHalfOpen
+        return true
+    case breakerHalfOpen:
+        return false
+    default:
+        return true
+    }
+}
+
+// record updates the breaker with the outcome of an attempted request.
+func (b *circuitBreaker) record(failed bool, now time.Time) {
+    if b == nil {
+        return
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    if !failed {
+        b.state = breakerClosed
+        b.failures = 0
+        return
+    }
+    b.failures++
+    if b.state == breakerHalfOpen || b.failures >= b.threshold {
+        b.state = breakerOpen
+        b.openedAt = now
+    }
+}
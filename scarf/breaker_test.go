@@ -0,0 +1,65 @@
+package scarf
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+    var status int32 = http.StatusInternalServerError
+    var hits int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&hits, 1)
+        w.WriteHeader(int(atomic.LoadInt32(&status)))
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithCircuitBreaker(2, 50*time.Millisecond))
+    for i := 0; i < 2; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x"}); err == nil || errors.Is(err, ErrCircuitOpen) {
+            t.Fatalf("attempt %d: expected endpoint failure, got %v", i, err)
+        }
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrCircuitOpen) {
+        t.Fatalf("expected ErrCircuitOpen, got %v", err)
+    }
+    if got := atomic.LoadInt32(&hits); got != 2 {
+        t.Fatalf("expected 2 requests while open, got %d", got)
+    }
+
+    // Half-open probe fails: breaker re-opens immediately.
+    time.Sleep(60 * time.Millisecond)
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil || errors.Is(err, ErrCircuitOpen) {
+        t.Fatalf("expected probe to reach endpoint and fail, got %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrCircuitOpen) {
+        t.Fatalf("expected breaker to re-open after failed probe, got %v", err)
+    }
+
+    // Half-open probe succeeds: breaker closes.
+    atomic.StoreInt32(&status, http.StatusOK)
+    time.Sleep(60 * time.Millisecond)
+    for i := 0; i < 3; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+            t.Fatalf("expected success after recovery, got %v", err)
+        }
+    }
+}
+
+func TestCircuitBreaker_ClientErrorsDoNotTrip(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusBadRequest)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithCircuitBreaker(1, time.Hour))
+    for i := 0; i < 3; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x"}); errors.Is(err, ErrCircuitOpen) {
+            t.Fatalf("4xx responses should not open the breaker")
+        }
+    }
+}
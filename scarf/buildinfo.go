@@ -0,0 +1,48 @@
+package scarf
+
+import (
+    "context"
+    "runtime/debug"
+    "sync"
+)
+
+// BuildInfoEnricher returns an Enricher describing the host binary from
+// debug.ReadBuildInfo: "module_path", "module_version" and, when the binary
+// was built from a VCS checkout, "vcs_revision" and "vcs_modified". Fields
+// that are unavailable are omitted.
+func BuildInfoEnricher() Enricher {
+    var once sync.Once
+    var props map[string]any
+    return EnricherFunc(func(context.Context) map[string]any {
+        once.Do(func() {
+            if bi, ok := debug.ReadBuildInfo(); ok {
+                props = buildInfoProperties(bi)
+            }
+        })
+        return props
+    })
+}
+
+// WithBuildInfo is shorthand for WithEnrichers(BuildInfoEnricher()).
+func WithBuildInfo() Option {
+    return WithEnrichers(BuildInfoEnricher())
+}
+
+func buildInfoProperties(bi *debug.BuildInfo) map[string]any {
+    props := map[string]any{}
+    if bi.Main.Path != "" {
+        props["module_path"] = bi.Main.Path
+    }
+    if bi.Main.Version != "" {
+        props["module_version"] = bi.Main.Version
+    }
+    for _, setting := range bi.Settings {
+        switch setting.Key {
+        case "vcs.revision":
+            props["vcs_revision"] = setting.Value
+        case "vcs.modified":
+            props["vcs_modified"] = setting.Value == "true"
+        }
+    }
+    return props
+}
@@ -0,0 +1,51 @@
+package scarf
+
+import (
+    "runtime/debug"
+    "testing"
+)
+
+func TestBuildInfoProperties(t *testing.T) {
+    bi := &debug.BuildInfo{
+        Main: debug.Module{Path: "example.com/tool", Version: "v1.4.2"},
+        Settings: []debug.BuildSetting{
+            {Key: "vcs", Value: "git"},
+            {Key: "vcs.revision", Value: "abc123"},
+            {Key: "vcs.modified", Value: "true"},
+        },
+    }
+    props := buildInfoProperties(bi)
+    want := map[string]any{
+        "module_path":    "example.com/tool",
+        "module_version": "v1.4.2",
+        "vcs_revision":   "abc123",
+        "vcs_modified":   true,
+    }
+    if len(props) != len(want) {
+        t.Fatalf("got %v, want %v", props, want)
+    }
+    for k, v := range want {
+        if props[k] != v {
+            t.Fatalf("%s = %v, want %v", k, props[k], v)
+        }
+    }
+}
+
+func TestBuildInfoEnricher(t *testing.T) {
+    bi, ok := debug.ReadBuildInfo()
+    if !ok {
+        t.Skip("no build info in test binary")
+    }
+    want := buildInfoProperties(bi)
+
+    var got []Event
+    l := New("", captureEvents(&got), WithBuildInfo())
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    for k, v := range want {
+        if got[0].Properties[k] != v {
+            t.Fatalf("%s = %v, want %v", k, got[0].Properties[k], v)
+        }
+    }
+}
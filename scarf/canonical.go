@@ -0,0 +1,67 @@
+package scarf
+
+import (
+    "encoding/base64"
+    "math"
+    "strconv"
+    "time"
+)
+
+// CanonicalValue returns the form in which property value v is encoded, the
+// same in query parameters and every body encoding: time.Time as an RFC 3339
+// timestamp in UTC, time.Duration as integer milliseconds, errors as their
+// message and []byte as standard base64. Values inside []any and
+// map[string]any are converted too. Other values are returned as is, to be
+// JSON-encoded (honoring json tags) where they aren't scalars. Transports
+// with their own wire format, such as those of the scarfgrpc and scarfotel
+// modules, call it so properties arrive in the same form.
+func CanonicalValue(v any) any {
+    switch vv := v.(type) {
+    case time.Time:
+        return vv.UTC().Format(time.RFC3339Nano)
+    case time.Duration:
+        return vv.Milliseconds()
+    case error:
+        return vv.Error()
+    case []byte:
+        return base64.StdEncoding.EncodeToString(vv)
+    case []any:
+        out := make([]any, len(vv))
+        for i, e := range vv {
+            out[i] = CanonicalValue(e)
+        }
+        return out
+    case map[string]any:
+        out := make(map[string]any, len(vv))
+        for k, e := range vv {
+            out[k] = CanonicalValue(e)
+        }
+        return out
+    }
+    return v
+}
+
+// appendFloat appends f formatted as encoding/json does: the shortest
+// representation that round-trips, with an exponent only for very large or
+// small magnitudes ("1e+21", "1e-7"). NaN and infinities, which JSON can't
+// represent, are spelled "NaN", "+Inf" and "-Inf".
+func appendFloat(b []byte, f float64, bits int) []byte {
+    if math.IsNaN(f) || math.IsInf(f, 0) {
+        return strconv.AppendFloat(b, f, 'g', -1, bits)
+    }
+    format := byte('f')
+    if abs := math.Abs(f); abs != 0 {
+        if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+            format = 'e'
+        }
+    }
+    b = strconv.AppendFloat(b, f, format, -1, bits)
+    if format == 'e' {
+        // Clean up e-09 to e-9.
+        if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+            b[n-2] = b[n-1]
+            b = b[:n-1]
+        }
+    }
+    return b
+}
@@ -0,0 +1,108 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "math"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+)
+
+func TestAppendFloat(t *testing.T) {
+    for _, f := range []float64{0, 1, -1.5, 0.1, 1e6, 123456789.125, 1e20, 1e21, 1e-6, 1e-7, -2.5e-9, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+        want, _ := json.Marshal(f)
+        if got := appendFloat(nil, f, 64); string(got) != string(want) {
+            t.Errorf("appendFloat(%v, 64) = %s, want %s", f, got, want)
+        }
+    }
+    for _, f := range []float32{0.1, 1e6, 3.4e38, 1e-7} {
+        want, _ := json.Marshal(f)
+        if got := appendFloat(nil, float64(f), 32); string(got) != string(want) {
+            t.Errorf("appendFloat(%v, 32) = %s, want %s", f, got, want)
+        }
+    }
+    if got := string(appendFloat(nil, math.Inf(-1), 64)); got != "-Inf" {
+        t.Errorf("expected -Inf, got %s", got)
+    }
+}
+
+func TestCanonicalEncoding(t *testing.T) {
+    at := time.Date(2024, 3, 1, 14, 30, 0, 500, time.FixedZone("CET", 3600))
+    ev := Event{Name: "x", Properties: map[string]any{
+        "at":    at,
+        "took":  1500 * time.Millisecond,
+        "err":   errors.New("boom"),
+        "ratio": 1e6,
+        "b":     1, "a": 2, "c": map[string]any{"z": 1, "y": 2},
+    }}
+
+    q := ev.queryValues()
+    if q.Get("at") != "2024-03-01T13:30:00.0000005Z" || q.Get("took") != "1500" || q.Get("err") != "boom" || q.Get("ratio") != "1000000" {
+        t.Fatalf("unexpected canonical query values %v", q)
+    }
+
+    body, err := ev.jsonBody()
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := `{"a":2,"at":"2024-03-01T13:30:00.0000005Z","b":1,"c":{"y":2,"z":1},"err":"boom","event":"x","ratio":1000000,"took":1500}`
+    if string(body) != want {
+        t.Fatalf("unexpected JSON body:\n got %s\nwant %s", body, want)
+    }
+
+    // Map iteration order must not leak into any encoding.
+    var endpoints endpointCache
+    p, err := endpoints.get("https://example.com/e")
+    if err != nil {
+        t.Fatal(err)
+    }
+    firstURL := p.queryURL(ev)
+    firstMsgpack, _ := ev.msgpackBody()
+    firstPB := (&pb.Batch{Events: []pb.Event{ev.protobuf()}}).Marshal()
+    for i := 0; i < 20; i++ {
+        mp, _ := ev.msgpackBody()
+        pbBody := (&pb.Batch{Events: []pb.Event{ev.protobuf()}}).Marshal()
+        if p.queryURL(ev) != firstURL || !bytes.Equal(mp, firstMsgpack) || !bytes.Equal(pbBody, firstPB) {
+            t.Fatalf("encoding is not deterministic")
+        }
+    }
+    if g := ev.protobuf().Properties["at"]; g.Kind != pb.KindString || g.String != "2024-03-01T13:30:00.0000005Z" {
+        t.Fatalf("unexpected protobuf timestamp %+v", g)
+    }
+}
+
+func TestRichValues(t *testing.T) {
+    ev := Event{Name: "x", Properties: map[string]any{
+        "blob":   []byte{1, 2, 3},
+        "phases": map[string]any{"compile": 2 * time.Second, "at": time.Unix(0, 0)},
+        "steps":  []any{time.Millisecond, errors.New("skipped")},
+    }}
+
+    q := ev.queryValues()
+    want := map[string]string{
+        "blob":   "AQID",
+        "phases": `{"at":"1970-01-01T00:00:00Z","compile":2000}`,
+        "steps":  `[1,"skipped"]`,
+    }
+    for k, v := range want {
+        if q.Get(k) != v {
+            t.Errorf("query %s = %q, want %q", k, q.Get(k), v)
+        }
+    }
+
+    var body map[string]any
+    raw, _ := ev.jsonBody()
+    if err := json.Unmarshal(raw, &body); err != nil {
+        t.Fatal(err)
+    }
+    if body["blob"] != "AQID" || body["phases"].(map[string]any)["compile"] != 2000.0 {
+        t.Fatalf("unexpected JSON body %s", raw)
+    }
+    mp, _ := ev.msgpackBody()
+    if !bytes.Contains(mp, []byte("\xa4AQID")) {
+        t.Fatalf("expected []byte as a base64 string in MessagePack too")
+    }
+}
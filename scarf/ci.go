@@ -0,0 +1,93 @@
+package scarf
+
+import (
+    "os"
+)
+
+// ciProviders maps CI providers to an environment variable they set. Order
+// matters: specific providers are checked before the generic CI variable.
+var ciProviders = []struct {
+    name string
+    env  string
+}{
+    {"github_actions", "GITHUB_ACTIONS"},
+    {"gitlab", "GITLAB_CI"},
+    {"circleci", "CIRCLECI"},
+    {"jenkins", "JENKINS_URL"},
+    {"travis", "TRAVIS"},
+    {"buildkite", "BUILDKITE"},
+    {"azure_pipelines", "TF_BUILD"},
+    {"bitbucket", "BITBUCKET_BUILD_NUMBER"},
+    {"teamcity", "TEAMCITY_VERSION"},
+    {"aws_codebuild", "CODEBUILD_BUILD_ID"},
+    {"drone", "DRONE"},
+    {"generic", "CI"},
+}
+
+// CIProvider returns the name of the CI system the process is running in
+// (e.g. "github_actions", "gitlab", "circleci", "jenkins"), "generic" if only
+// the conventional CI variable is set, or "" outside CI.
+func CIProvider() string {
+    for _, p := range ciProviders {
+        v := os.Getenv(p.env)
+        if v == "" || v == "0" || v == "false" {
+            continue
+        }
+        return p.name
+    }
+    return ""
+}
+
+// IsCI reports whether the process appears to be running in a CI environment.
+func IsCI() bool {
+    return CIProvider() != ""
+}
+
+// CIMode controls how events emitted from CI environments are handled.
+type CIMode int
+
+const (
+    // CIModeSend sends events from CI like any other. It is the default.
+    CIModeSend CIMode = iota
+    // CIModeTag sends events from CI with "ci=true" and "ci_provider" properties.
+    CIModeTag
+    // CIModeSuppress disables analytics when running in CI, so CI runs don't
+    // pollute adoption metrics.
+    CIModeSuppress
+)
+
+// WithCIMode sets how events emitted from CI are handled. CI is detected once,
+// when the logger is created.
+func WithCIMode(mode CIMode) Option {
+    return func(s *ScarfEventLogger) {
+        s.ciMode = mode
+    }
+}
+
+// applyCIMode resolves the CI mode against the environment at construction.
+func (s *ScarfEventLogger) applyCIMode() {
+    if s.ciMode == CIModeSend {
+        return
+    }
+    provider := CIProvider()
+    if provider == "" {
+        return
+    }
+    switch s.ciMode {
+    case CIModeTag:
+        s.ciProvider = provider
+    case CIModeSuppress:
+        if s.envDisabled == "" {
+            s.envDisabled = "running in CI (" + provider + ")"
+        }
+    }
+}
+
+// tagCI adds CI properties when running under CIModeTag.
+func (s *ScarfEventLogger) tagCI(props map[string]any) {
+    if s.ciProvider == "" {
+        return
+    }
+    props["ci"] = true
+    props["ci_provider"] = s.ciProvider
+}
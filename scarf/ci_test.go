@@ -0,0 +1,63 @@
+package scarf
+
+import (
+    "errors"
+    "testing"
+)
+
+// clearCIEnv hides the CI environment the tests themselves may run in.
+func clearCIEnv(t *testing.T) {
+    t.Helper()
+    for _, p := range ciProviders {
+        t.Setenv(p.env, "")
+    }
+}
+
+func TestCIProvider(t *testing.T) {
+    clearCIEnv(t)
+    if IsCI() {
+        t.Fatalf("expected no CI with a clean environment")
+    }
+
+    t.Setenv("CI", "true")
+    if got := CIProvider(); got != "generic" {
+        t.Fatalf("expected generic, got %q", got)
+    }
+    t.Setenv("GITLAB_CI", "true")
+    if got := CIProvider(); got != "gitlab" {
+        t.Fatalf("expected gitlab to take precedence over CI, got %q", got)
+    }
+    t.Setenv("GITLAB_CI", "")
+    t.Setenv("CI", "false")
+    if IsCI() {
+        t.Fatalf("CI=false should not count as CI")
+    }
+}
+
+func TestCIMode(t *testing.T) {
+    clearCIEnv(t)
+    t.Setenv("GITHUB_ACTIONS", "true")
+
+    var got []Event
+    tagged := New("", captureEvents(&got), WithCIMode(CIModeTag))
+    if err := tagged.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if got[0].Properties["ci"] != true || got[0].Properties["ci_provider"] != "github_actions" {
+        t.Fatalf("expected CI tags, got %v", got[0].Properties)
+    }
+
+    suppressed := New("", captureEvents(&got), WithCIMode(CIModeSuppress))
+    if suppressed.Enabled() || suppressed.DisabledReason() != "running in CI (github_actions)" {
+        t.Fatalf("expected logger to be disabled in CI, got reason %q", suppressed.DisabledReason())
+    }
+    if err := suppressed.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+
+    plain := New("", captureEvents(&got))
+    _ = plain.LogEvent(map[string]any{"event": "x"})
+    if _, ok := got[len(got)-1].Properties["ci"]; ok {
+        t.Fatalf("default mode must not tag events")
+    }
+}
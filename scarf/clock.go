@@ -0,0 +1,65 @@
+package scarf
+
+import "time"
+
+// Clock is a logger's source of time: event timestamps, Retry-After pauses,
+// circuit breaker cooldowns, session durations, Every schedules, batch
+// timers, and the heartbeat and metrics intervals. WithClock swaps in a fake
+// so tests can drive retry and scheduling behavior deterministically;
+// scarftest.Clock is one. Request timeouts and latencies always use real
+// time.
+type Clock interface {
+    Now() time.Time
+    // NewTimer returns a Timer that sends on its channel after d.
+    NewTimer(d time.Duration) Timer
+    // AfterFunc returns a Timer that calls f in its own goroutine after d.
+    // Its channel is nil.
+    AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a Clock's counterpart to *time.Timer.
+type Timer interface {
+    C() <-chan time.Time
+    Stop() bool
+    Reset(d time.Duration) bool
+}
+
+// WithClock sets the logger's Clock. The default is the system clock.
+func WithClock(c Clock) Option {
+    return func(s *ScarfEventLogger) {
+        if c != nil {
+            s.clock = c
+        }
+    }
+}
+
+// systemClock is the real Clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+    return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+    return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+    return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct {
+    t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+    return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+    return t.t.Stop()
+}
+
+func (t systemTimer) Reset(d time.Duration) bool {
+    return t.t.Reset(d)
+}
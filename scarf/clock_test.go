@@ -0,0 +1,50 @@
+package scarf
+
+import (
+    "fmt"
+    "testing"
+    "time"
+)
+
+// fixedClock is a Clock stopped at now, with real timers.
+type fixedClock struct {
+    systemClock
+    now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+    return c.now
+}
+
+func TestWithClock(t *testing.T) {
+    clk := &fixedClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+    var got []Event
+    l := New("", WithClock(clk), captureEvents(&got))
+
+    if err := l.StartSession(); err != nil {
+        t.Fatalf("StartSession: %v", err)
+    }
+    clk.now = clk.now.Add(90 * time.Second)
+    if err := l.EndSession(); err != nil {
+        t.Fatalf("EndSession: %v", err)
+    }
+    if !got[0].Timestamp.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+        t.Fatalf("expected the timestamp from the clock, got %v", got[0].Timestamp)
+    }
+    if got[1].Properties["duration_ms"] != int64(90000) {
+        t.Fatalf("expected the session duration from the clock, got %v", got[1].Properties["duration_ms"])
+    }
+    // A UUIDv7 starts with its time in milliseconds, in hex.
+    if ms := got[0].ID[:8] + got[0].ID[9:13]; ms != fmt.Sprintf("%012x", clk.now.Add(-90*time.Second).UnixMilli()) {
+        t.Fatalf("expected the event ID's time from the clock, got %s", got[0].ID)
+    }
+
+    b := &circuitBreaker{threshold: 1, cooldown: time.Minute}
+    b.record(true, clk.now)
+    if b.allow(clk.now.Add(59 * time.Second)) {
+        t.Fatalf("expected the breaker to stay open during the cooldown")
+    }
+    if !b.allow(clk.now.Add(time.Minute)) {
+        t.Fatalf("expected a probe after the cooldown")
+    }
+}
@@ -0,0 +1,153 @@
+package scarf
+
+import (
+    "context"
+    "net/http"
+    "os"
+    "sync"
+    "time"
+)
+
+// CloudProviderEnricher returns an opt-in Enricher that tags events with a
+// "cloud_provider" property ("aws", "gcp" or "azure") when running on one of
+// those clouds.
+//
+// Detection first uses environment variables set by managed runtimes (Lambda,
+// ECS, Cloud Run, Cloud Functions, App Service, ...). If that is inconclusive
+// and budget is positive, the instance metadata servers are probed once in
+// the background with budget as a strict timeout; events never wait longer
+// than budget for the probe. A zero budget disables probing entirely.
+func CloudProviderEnricher(budget time.Duration) Enricher {
+    return &cloudEnricher{
+        budget:   budget,
+        awsURL:   "http://169.254.169.254/latest/api/token",
+        gcpURL:   "http://metadata.google.internal/computeMetadata/v1/",
+        azureURL: "http://169.254.169.254/metadata/instance?api-version=2021-02-01",
+    }
+}
+
+type cloudEnricher struct {
+    budget                   time.Duration
+    awsURL, gcpURL, azureURL string
+
+    once     sync.Once
+    done     chan struct{}
+    provider string
+}
+
+// Enrich implements Enricher.
+func (c *cloudEnricher) Enrich(ctx context.Context) map[string]any {
+    c.once.Do(c.start)
+
+    select {
+    case <-c.done:
+    default:
+        wait := time.NewTimer(c.budget)
+        defer wait.Stop()
+        select {
+        case <-c.done:
+        case <-wait.C:
+            return nil
+        case <-ctx.Done():
+            return nil
+        }
+    }
+    if c.provider == "" {
+        return nil
+    }
+    return map[string]any{"cloud_provider": c.provider}
+}
+
+func (c *cloudEnricher) start() {
+    c.done = make(chan struct{})
+    if p := cloudProviderFromEnv(); p != "" || c.budget <= 0 {
+        c.provider = p
+        close(c.done)
+        return
+    }
+    go func() {
+        c.provider = c.probe()
+        close(c.done)
+    }()
+}
+
+// cloudProviderFromEnv detects managed cloud runtimes from their environment.
+func cloudProviderFromEnv() string {
+    has := func(keys ...string) bool {
+        for _, k := range keys {
+            if os.Getenv(k) != "" {
+                return true
+            }
+        }
+        return false
+    }
+    switch {
+    case has("AWS_EXECUTION_ENV", "AWS_LAMBDA_FUNCTION_NAME", "ECS_CONTAINER_METADATA_URI", "ECS_CONTAINER_METADATA_URI_V4"):
+        return "aws"
+    case has("K_SERVICE", "FUNCTION_TARGET", "GAE_SERVICE", "GOOGLE_CLOUD_PROJECT"):
+        return "gcp"
+    case has("WEBSITE_INSTANCE_ID", "FUNCTIONS_WORKER_RUNTIME", "AZURE_FUNCTIONS_ENVIRONMENT"):
+        return "azure"
+    }
+    return ""
+}
+
+// probe queries the metadata servers concurrently and returns the first
+// provider to answer, or "" if none does within the budget.
+func (c *cloudEnricher) probe() string {
+    if buildDisabled {
+        return ""
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), c.budget)
+    defer cancel()
+
+    // Metadata servers are link-local; never route probes through a proxy.
+    client := &http.Client{Transport: &http.Transport{Proxy: nil}}
+    defer client.CloseIdleConnections()
+
+    type check struct {
+        provider string
+        method   string
+        url      string
+        header   [2]string
+        ok       func(*http.Response) bool
+    }
+    checks := []check{
+        {"aws", http.MethodPut, c.awsURL, [2]string{"X-aws-ec2-metadata-token-ttl-seconds", "60"},
+            func(r *http.Response) bool { return r.StatusCode == http.StatusOK }},
+        {"gcp", http.MethodGet, c.gcpURL, [2]string{"Metadata-Flavor", "Google"},
+            func(r *http.Response) bool { return r.Header.Get("Metadata-Flavor") == "Google" }},
+        {"azure", http.MethodGet, c.azureURL, [2]string{"Metadata", "true"},
+            func(r *http.Response) bool { return r.StatusCode == http.StatusOK }},
+    }
+
+    found := make(chan string, len(checks))
+    for _, ch := range checks {
+        ch := ch
+        go func() {
+            req, err := http.NewRequestWithContext(ctx, ch.method, ch.url, nil)
+            if err != nil {
+                found <- ""
+                return
+            }
+            req.Header.Set(ch.header[0], ch.header[1])
+            resp, err := client.Do(req)
+            if err != nil {
+                found <- ""
+                return
+            }
+            _ = drainAndClose(resp)
+            if ch.ok(resp) {
+                found <- ch.provider
+                return
+            }
+            found <- ""
+        }()
+    }
+    for range checks {
+        if p := <-found; p != "" {
+            return p
+        }
+    }
+    return ""
+}
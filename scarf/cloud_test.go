@@ -0,0 +1,98 @@
+package scarf
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func clearCloudEnv(t *testing.T) {
+    t.Helper()
+    for _, k := range []string{
+        "AWS_EXECUTION_ENV", "AWS_LAMBDA_FUNCTION_NAME", "ECS_CONTAINER_METADATA_URI", "ECS_CONTAINER_METADATA_URI_V4",
+        "K_SERVICE", "FUNCTION_TARGET", "GAE_SERVICE", "GOOGLE_CLOUD_PROJECT",
+        "WEBSITE_INSTANCE_ID", "FUNCTIONS_WORKER_RUNTIME", "AZURE_FUNCTIONS_ENVIRONMENT",
+    } {
+        t.Setenv(k, "")
+    }
+}
+
+func TestCloudProviderEnricher_Env(t *testing.T) {
+    clearCloudEnv(t)
+    t.Setenv("K_SERVICE", "my-service")
+
+    var got []Event
+    l := New("", captureEvents(&got), WithEnrichers(CloudProviderEnricher(0)))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if got[0].Properties["cloud_provider"] != "gcp" {
+        t.Fatalf("expected gcp, got %v", got[0].Properties)
+    }
+}
+
+func TestCloudProviderEnricher_NoProbeWithZeroBudget(t *testing.T) {
+    clearCloudEnv(t)
+    if props := CloudProviderEnricher(0).Enrich(context.Background()); props != nil {
+        t.Fatalf("expected no provider, got %v", props)
+    }
+}
+
+func TestCloudProviderEnricher_Probe(t *testing.T) {
+    clearCloudEnv(t)
+    gcp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("Metadata-Flavor") != "Google" {
+            w.WriteHeader(http.StatusForbidden)
+            return
+        }
+        w.Header().Set("Metadata-Flavor", "Google")
+    }))
+    defer gcp.Close()
+    down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusNotFound)
+    }))
+    defer down.Close()
+
+    e := CloudProviderEnricher(time.Second).(*cloudEnricher)
+    e.awsURL, e.gcpURL, e.azureURL = down.URL, gcp.URL, down.URL
+    if props := e.Enrich(context.Background()); props["cloud_provider"] != "gcp" {
+        t.Fatalf("expected gcp from probe, got %v", props)
+    }
+}
+
+func TestCloudProviderEnricher_Budget(t *testing.T) {
+    clearCloudEnv(t)
+    block := make(chan struct{})
+    slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        select {
+        case <-block:
+        case <-r.Context().Done():
+        }
+    }))
+    defer slow.Close()
+    defer close(block)
+
+    e := CloudProviderEnricher(50 * time.Millisecond).(*cloudEnricher)
+    e.awsURL, e.gcpURL, e.azureURL = slow.URL, slow.URL, slow.URL
+    start := time.Now()
+    if props := e.Enrich(context.Background()); props != nil {
+        t.Fatalf("expected no provider, got %v", props)
+    }
+    if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+        t.Fatalf("enricher exceeded its budget: %s", elapsed)
+    }
+}
+
+func TestEnrichers_CallerWins(t *testing.T) {
+    var got []Event
+    e := EnricherFunc(func(context.Context) map[string]any {
+        return map[string]any{"a": "enriched", "b": "enriched"}
+    })
+    l := New("", captureEvents(&got), WithEnrichers(e))
+    _ = l.LogEvent(map[string]any{"a": "caller"})
+    if got[0].Properties["a"] != "caller" || got[0].Properties["b"] != "enriched" {
+        t.Fatalf("unexpected properties %v", got[0].Properties)
+    }
+}
@@ -0,0 +1,382 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Config holds logger settings that can live alongside an application's own
+// configuration. Zero values mean "use the default".
+type Config struct {
+    EndpointURL string   `json:"endpoint_url"`
+    APIKey      string   `json:"api_key,omitempty"`
+    Timeout     Duration `json:"timeout,omitempty"`
+    // SampleRate is the fraction of events to send; nil sends everything.
+    SampleRate *float64 `json:"sample_rate,omitempty"`
+    // Verbose overrides SCARF_VERBOSE when set.
+    Verbose *bool `json:"verbose,omitempty"`
+    // LogLevel overrides Verbose and SCARF_VERBOSE when set.
+    LogLevel *LogLevel `json:"log_level,omitempty"`
+    // Policy is "opt-in" or "opt-out" (the default).
+    Policy string `json:"policy,omitempty"`
+    // Enabled turns analytics on or off, overriding DO_NOT_TRACK and
+    // SCARF_NO_ANALYTICS; only set it to true for the user's own explicit
+    // choice. Code options take precedence over it.
+    Enabled *bool `json:"enabled,omitempty"`
+    // Disabled turns analytics off, taking precedence over Enabled.
+    Disabled bool `json:"disabled,omitempty"`
+}
+
+// Duration is a time.Duration that decodes from a Go duration string ("5s")
+// or a number of seconds.
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+    if bytes.Equal(b, []byte("null")) {
+        return nil
+    }
+    var s string
+    if err := json.Unmarshal(b, &s); err != nil {
+        s = string(b)
+    }
+    return d.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+    v, err := parseTimeout(strings.TrimSpace(string(b)))
+    if err != nil {
+        return fmt.Errorf("invalid duration %q: %w", string(b), err)
+    }
+    *d = Duration(v)
+    return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+    return json.Marshal(time.Duration(d).String())
+}
+
+// LoadConfig reads a Config from a JSON (.json), YAML (.yaml, .yml) or TOML
+// (.toml) file. Settings may sit at the top level or in a "scarf" section, so
+// they can share a file with other application settings; unknown keys are
+// ignored.
+//
+// YAML and TOML support is limited to the flat key/value subset needed by
+// Config: scalars (strings, numbers, booleans) and comments.
+func LoadConfig(path string) (Config, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return Config{}, fmt.Errorf("scarf: read config: %w", err)
+    }
+
+    var cfg Config
+    switch ext := strings.ToLower(filepath.Ext(path)); ext {
+    case ".json":
+        err = decodeJSONConfig(data, &cfg)
+    case ".yaml", ".yml":
+        var values map[string]string
+        if values, err = parseYAMLSubset(data); err == nil {
+            err = cfg.setValues(values)
+        }
+    case ".toml":
+        var values map[string]string
+        if values, err = parseTOMLSubset(data); err == nil {
+            err = cfg.setValues(values)
+        }
+    default:
+        return Config{}, fmt.Errorf("scarf: unsupported config format %q", ext)
+    }
+    if err != nil {
+        return Config{}, fmt.Errorf("scarf: parse config %s: %w", path, err)
+    }
+    return cfg, nil
+}
+
+// NewFromConfig creates a logger from cfg. opts are applied after cfg and take
+// precedence over it.
+func NewFromConfig(cfg Config, opts ...Option) (*ScarfEventLogger, error) {
+    return newFromLayers([]configLayer{{SourceConfig, cfg}}, opts...)
+}
+
+// validateValues checks the settings c sets, without requiring any.
+func (c Config) validateValues() error {
+    if c.Timeout < 0 {
+        return errors.New("scarf: timeout must be positive")
+    }
+    if c.SampleRate != nil && (*c.SampleRate < 0 || *c.SampleRate > 1) {
+        return fmt.Errorf("scarf: sample rate %v must be between 0 and 1", *c.SampleRate)
+    }
+    if c.Policy != "" {
+        if _, err := ParsePolicy(c.Policy); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (c Config) options() []Option {
+    var opts []Option
+    if c.APIKey != "" {
+        opts = append(opts, WithAPIKey(c.APIKey))
+    }
+    if c.Timeout > 0 {
+        opts = append(opts, WithTimeout(time.Duration(c.Timeout)))
+    }
+    if c.SampleRate != nil {
+        opts = append(opts, WithSampleRate(*c.SampleRate))
+    }
+    if c.Verbose != nil {
+        opts = append(opts, WithVerbose(*c.Verbose))
+    }
+    if c.LogLevel != nil {
+        opts = append(opts, WithLogLevel(*c.LogLevel))
+    }
+    if c.Policy != "" {
+        if p, err := ParsePolicy(c.Policy); err == nil {
+            opts = append(opts, WithPolicy(p))
+        }
+    }
+    if c.Disabled || c.Enabled != nil {
+        enabled := !c.Disabled && *c.Enabled
+        opts = append(opts, func(s *ScarfEventLogger) { s.configEnabled = settingOf(enabled) })
+    }
+    return opts
+}
+
+// setValues applies string-typed settings as produced by the YAML and TOML
+// readers. Unknown keys are ignored.
+func (c *Config) setValues(values map[string]string) error {
+    for k, v := range values {
+        switch k {
+        case "endpoint_url":
+            c.EndpointURL = v
+        case "api_key":
+            c.APIKey = v
+        case "timeout":
+            if err := c.Timeout.UnmarshalText([]byte(v)); err != nil {
+                return fmt.Errorf("timeout: %w", err)
+            }
+        case "sample_rate":
+            rate, err := strconv.ParseFloat(v, 64)
+            if err != nil {
+                return fmt.Errorf("sample_rate: %w", err)
+            }
+            c.SampleRate = &rate
+        case "verbose":
+            b, err := strconv.ParseBool(v)
+            if err != nil {
+                return fmt.Errorf("verbose: %w", err)
+            }
+            c.Verbose = &b
+        case "log_level":
+            var level LogLevel
+            if err := level.UnmarshalText([]byte(v)); err != nil {
+                return fmt.Errorf("log_level: %w", err)
+            }
+            c.LogLevel = &level
+        case "policy":
+            c.Policy = v
+        case "enabled":
+            b, err := strconv.ParseBool(v)
+            if err != nil {
+                return fmt.Errorf("enabled: %w", err)
+            }
+            c.Enabled = &b
+        case "disabled":
+            b, err := strconv.ParseBool(v)
+            if err != nil {
+                return fmt.Errorf("disabled: %w", err)
+            }
+            c.Disabled = b
+        }
+    }
+    return nil
+}
+
+func decodeJSONConfig(data []byte, cfg *Config) error {
+    var sections map[string]json.RawMessage
+    if err := json.Unmarshal(data, &sections); err != nil {
+        return err
+    }
+    if section, ok := sections["scarf"]; ok {
+        data = section
+    }
+    return json.Unmarshal(data, cfg)
+}
+
+// parseYAMLSubset extracts top-level scalar keys, or the keys of a "scarf"
+// mapping if present. Other nested content, including mappings nested under
+// "scarf", is skipped.
+func parseYAMLSubset(data []byte) (map[string]string, error) {
+    root := map[string]string{}
+    section := map[string]string{}
+    hasSection := false
+    inSection := false
+    indent := "" // of the keys directly under "scarf", once seen
+
+    for n, line := range strings.Split(string(data), "\n") {
+        line = strings.TrimRight(line, " \t\r")
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+            continue
+        }
+        indented := line[0] == ' ' || line[0] == '\t'
+        if indented && !inSection {
+            continue
+        }
+        if strings.HasPrefix(trimmed, "- ") {
+            continue
+        }
+        if indented {
+            prefix := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+            if indent == "" {
+                indent = prefix
+            }
+            if prefix != indent {
+                continue
+            }
+        }
+        key, value, ok := strings.Cut(trimmed, ":")
+        if !ok {
+            return nil, fmt.Errorf("line %d: expected \"key: value\"", n+1)
+        }
+        key = strings.TrimSpace(key)
+        value = unquote(stripComment(strings.TrimSpace(value)))
+
+        if !indented {
+            inSection = key == "scarf" && value == ""
+            if inSection {
+                indent = ""
+                hasSection = true
+                continue
+            }
+            root[key] = value
+            continue
+        }
+        section[key] = value
+    }
+    if hasSection {
+        return section, nil
+    }
+    return root, nil
+}
+
+// parseTOMLSubset extracts top-level keys, or the keys of a [scarf] table if
+// present. Other tables are skipped without being parsed, as are multi-line
+// arrays and strings.
+func parseTOMLSubset(data []byte) (map[string]string, error) {
+    root := map[string]string{}
+    section := map[string]string{}
+    hasSection := false
+    table := ""
+    closer := "" // ends the multi-line string being skipped
+    depth := 0   // of the multi-line array being skipped
+
+    for n, line := range strings.Split(string(data), "\n") {
+        trimmed := strings.TrimSpace(line)
+        switch {
+        case closer != "":
+            if strings.Contains(trimmed, closer) {
+                closer = ""
+            }
+            continue
+        case depth > 0:
+            depth += tomlBrackets(stripComment(trimmed))
+            continue
+        }
+        if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+            continue
+        }
+        if strings.HasPrefix(trimmed, "[") {
+            table = strings.TrimSpace(strings.Trim(stripComment(trimmed), "[]"))
+            if table == "scarf" {
+                hasSection = true
+            }
+            continue
+        }
+        if _, rest, ok := strings.Cut(trimmed, "="); ok {
+            rest = strings.TrimSpace(stripComment(rest))
+            closer = tomlMultilineString(rest)
+            if strings.HasPrefix(rest, "[") {
+                depth = tomlBrackets(rest)
+            }
+            if closer != "" || depth > 0 {
+                continue
+            }
+        }
+        if table != "" && table != "scarf" {
+            continue
+        }
+        key, value, ok := strings.Cut(trimmed, "=")
+        if !ok {
+            return nil, fmt.Errorf("line %d: expected \"key = value\"", n+1)
+        }
+        key = unquote(strings.TrimSpace(key))
+        value = unquote(stripComment(strings.TrimSpace(value)))
+        switch table {
+        case "":
+            root[key] = value
+        case "scarf":
+            section[key] = value
+        }
+    }
+    if hasSection {
+        return section, nil
+    }
+    return root, nil
+}
+
+// tomlMultilineString returns the delimiter that ends v if v starts a
+// multi-line string, or "".
+func tomlMultilineString(v string) string {
+    for _, q := range []string{`"""`, `'''`} {
+        if strings.HasPrefix(v, q) && !strings.Contains(v[len(q):], q) {
+            return q
+        }
+    }
+    return ""
+}
+
+// tomlBrackets returns how many more arrays v opens than it closes.
+func tomlBrackets(v string) int {
+    return strings.Count(v, "[") - strings.Count(v, "]")
+}
+
+// stripComment removes a trailing "# comment" outside of quotes.
+func stripComment(v string) string {
+    var quote byte
+    for i := 0; i < len(v); i++ {
+        switch c := v[i]; {
+        case quote != 0:
+            if c == quote {
+                quote = 0
+            }
+        case c == '"' || c == '\'':
+            quote = c
+        case c == '#' && (i == 0 || v[i-1] == ' ' || v[i-1] == '\t'):
+            return strings.TrimSpace(v[:i])
+        }
+    }
+    return v
+}
+
+func unquote(v string) string {
+    if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+        if v[0] == '"' {
+            if s, err := strconv.Unquote(v); err == nil {
+                return s
+            }
+        }
+        return v[1 : len(v)-1]
+    }
+    return v
+}
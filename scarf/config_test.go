@@ -0,0 +1,154 @@
+package scarf
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatalf("write config: %v", err)
+    }
+    return path
+}
+
+func TestLoadConfig_Formats(t *testing.T) {
+    files := map[string]string{
+        "flat.json": `{"endpoint_url": "https://example.com/e", "api_key": "k", "timeout": "2s", "sample_rate": 0.5, "verbose": true}`,
+        "nested.json": `{"app": {"port": 8080}, "scarf": {"endpoint_url": "https://example.com/e", "api_key": "k", "timeout": 2, "sample_rate": 0.5, "verbose": true}}`,
+        "flat.yaml": `
+# telemetry
+endpoint_url: "https://example.com/e"
+api_key: k   # inline comment
+timeout: 2s
+sample_rate: 0.5
+verbose: true
+`,
+        "nested.yml": `
+server:
+  port: 8080
+  endpoint_url: https://wrong.example.com
+hosts:
+  - a
+  - b
+scarf:
+  endpoint_url: https://example.com/e
+  api_key: 'k'
+  nested:
+    timeout: bogus
+  timeout: 2
+  sample_rate: 0.5
+  verbose: true
+`,
+        "flat.toml": `
+endpoint_url = "https://example.com/e"
+api_key = "k"
+timeout = "2s"
+sample_rate = 0.5
+verbose = true
+`,
+        "nested.toml": `
+name = "myapp"
+authors = [
+  "a",
+]
+
+[server]
+endpoint_url = "https://wrong.example.com"
+
+[tool]
+deps = [
+  ["a", "b"],
+  "c",
+]
+description = """
+[scarf]
+endpoint_url = "https://wrong.example.com"
+"""
+
+[scarf]
+endpoint_url = "https://example.com/e" # collector
+api_key = "k"
+timeout = 2
+sample_rate = 0.5
+verbose = true
+`,
+    }
+    for name, content := range files {
+        t.Run(name, func(t *testing.T) {
+            cfg, err := LoadConfig(writeConfig(t, name, content))
+            if err != nil {
+                t.Fatalf("LoadConfig: %v", err)
+            }
+            if cfg.EndpointURL != "https://example.com/e" || cfg.APIKey != "k" {
+                t.Fatalf("unexpected endpoint/key: %+v", cfg)
+            }
+            if time.Duration(cfg.Timeout) != 2*time.Second {
+                t.Fatalf("expected 2s timeout, got %s", time.Duration(cfg.Timeout))
+            }
+            if cfg.SampleRate == nil || *cfg.SampleRate != 0.5 {
+                t.Fatalf("expected sample rate 0.5, got %v", cfg.SampleRate)
+            }
+            if cfg.Verbose == nil || !*cfg.Verbose {
+                t.Fatalf("expected verbose=true, got %v", cfg.Verbose)
+            }
+        })
+    }
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+    if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+        t.Fatalf("expected error for missing file")
+    }
+    if _, err := LoadConfig(writeConfig(t, "c.ini", "x=1")); err == nil {
+        t.Fatalf("expected error for unsupported extension")
+    }
+    if _, err := LoadConfig(writeConfig(t, "c.toml", `timeout = "soon"`)); err == nil {
+        t.Fatalf("expected error for invalid timeout")
+    }
+}
+
+func TestNewFromConfig(t *testing.T) {
+    if _, err := NewFromConfig(Config{}); err == nil {
+        t.Fatalf("expected error without endpoint")
+    }
+    bad := 1.5
+    if _, err := NewFromConfig(Config{EndpointURL: "https://example.com", SampleRate: &bad}); err == nil {
+        t.Fatalf("expected error for out-of-range sample rate")
+    }
+
+    verbose := true
+    l, err := NewFromConfig(Config{
+        EndpointURL: "https://example.com",
+        Timeout:     Duration(4 * time.Second),
+        Verbose:     &verbose,
+        Disabled:    true,
+    })
+    if err != nil {
+        t.Fatalf("NewFromConfig: %v", err)
+    }
+    if l.defaultTimeout != 4*time.Second || l.logLevel != LogDebug || l.Enabled() {
+        t.Fatalf("config not applied: timeout=%s log level=%s enabled=%v", l.defaultTimeout, l.logLevel, l.Enabled())
+    }
+}
+
+func TestConfigLogLevel(t *testing.T) {
+    cfg, err := LoadConfig(writeConfig(t, "c.yaml", "endpoint_url: https://example.com/e\nverbose: true\nlog_level: trace\n"))
+    if err != nil {
+        t.Fatalf("LoadConfig: %v", err)
+    }
+    l, err := NewFromConfig(cfg)
+    if err != nil {
+        t.Fatalf("NewFromConfig: %v", err)
+    }
+    if l.logLevel != LogTrace {
+        t.Fatalf("expected log_level to override verbose, got %s", l.logLevel)
+    }
+    if _, err := LoadConfig(writeConfig(t, "c.json", `{"log_level": "loud"}`)); err == nil {
+        t.Fatalf("expected error for invalid log level")
+    }
+}
@@ -0,0 +1,288 @@
+package scarf
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf/statedir"
+)
+
+// ErrNoConsent is returned when the user has declined telemetry, or has not
+// yet granted it under PolicyOptIn.
+var ErrNoConsent = errors.New("scarf: telemetry consent not granted")
+
+// ConsentState is the user's recorded telemetry decision.
+type ConsentState int
+
+const (
+    // ConsentUnknown means the user has not made a decision yet.
+    ConsentUnknown ConsentState = iota
+    // ConsentGranted means the user agreed to telemetry.
+    ConsentGranted
+    // ConsentDenied means the user declined telemetry.
+    ConsentDenied
+)
+
+func (c ConsentState) String() string {
+    switch c {
+    case ConsentGranted:
+        return "granted"
+    case ConsentDenied:
+        return "denied"
+    default:
+        return "unknown"
+    }
+}
+
+// WithAppName identifies the application embedding the SDK. It namespaces
+// state the SDK persists on the user's machine, such as the consent decision.
+func WithAppName(name string) Option {
+    return func(s *ScarfEventLogger) {
+        s.appName = strings.TrimSpace(name)
+    }
+}
+
+// WithConsentFile overrides where the consent decision is persisted. By
+// default it is stored under the user's config directory (XDG_CONFIG_HOME,
+// ~/Library/Application Support or %AppData%) in scarf/<app name>/consent.json,
+// which requires WithAppName, or in the state directory when WithStateDir or
+// SCARF_STATE_DIR is set. Without any of these, consent is kept in memory
+// only.
+func WithConsentFile(path string) Option {
+    return func(s *ScarfEventLogger) {
+        s.consent.path = path
+    }
+}
+
+// ConsentState returns the user's recorded decision, loading it from disk on
+// first use.
+func (s *ScarfEventLogger) ConsentState() ConsentState {
+    if s == nil {
+        return ConsentUnknown
+    }
+    return s.consent.get()
+}
+
+// SetConsent records the user's decision and persists it, so it applies to
+// future runs. The in-memory decision takes effect even if persisting fails.
+func (s *ScarfEventLogger) SetConsent(granted bool) error {
+    if s == nil {
+        return nil
+    }
+    state := ConsentDenied
+    if granted {
+        state = ConsentGranted
+    }
+    return s.consent.set(state)
+}
+
+// AskConsent writes prompt to out followed by " [y/N] ", reads a single line
+// answer from in, without reading past it, and records it with SetConsent.
+// Anything other than "y" or "yes" counts as declining. An empty prompt uses
+// a default question.
+func (s *ScarfEventLogger) AskConsent(in io.Reader, out io.Writer, prompt string) (bool, error) {
+    if s == nil {
+        return false, nil
+    }
+    if prompt == "" {
+        name := s.appName
+        if name == "" {
+            name = "this tool"
+        }
+        prompt = fmt.Sprintf("Help improve %s by sending anonymous usage statistics?", name)
+    }
+    if _, err := fmt.Fprintf(out, "%s [y/N] ", prompt); err != nil {
+        return false, fmt.Errorf("scarf: write consent prompt: %w", err)
+    }
+
+    line, err := readLine(in)
+    if err != nil && !errors.Is(err, io.EOF) {
+        return false, fmt.Errorf("scarf: read consent answer: %w", err)
+    }
+    answer := strings.ToLower(strings.TrimSpace(line))
+    granted := answer == "y" || answer == "yes"
+    return granted, s.SetConsent(granted)
+}
+
+// readLine reads up to and including the next '\n' from r. It never reads
+// past the newline, so the caller can keep reading r, e.g. a CLI's stdin.
+func readLine(r io.Reader) (string, error) {
+    br, ok := r.(io.ByteReader)
+    if !ok {
+        br = byteReader{r}
+    }
+    var line []byte
+    for {
+        c, err := br.ReadByte()
+        if err != nil {
+            return string(line), err
+        }
+        line = append(line, c)
+        if c == '\n' {
+            return string(line), nil
+        }
+    }
+}
+
+// byteReader reads an io.Reader one byte at a time.
+type byteReader struct {
+    r io.Reader
+}
+
+func (b byteReader) ReadByte() (byte, error) {
+    var c [1]byte
+    for {
+        n, err := b.r.Read(c[:])
+        if n == 1 {
+            return c[0], nil
+        }
+        if err != nil {
+            return 0, err
+        }
+    }
+}
+
+// checkConsent returns ErrNoConsent if events must not be sent.
+func (s *ScarfEventLogger) checkConsent() error {
+    switch s.ConsentState() {
+    case ConsentGranted:
+        return nil
+    case ConsentDenied:
+        return ErrNoConsent
+    default:
+        if s.policy == PolicyOptIn {
+            return ErrNoConsent
+        }
+        return nil
+    }
+}
+
+// consentPaths returns the file consent is persisted to and, when an
+// explicit state directory moved it there, the file it was persisted to
+// before, which is still read so a decision made earlier isn't lost. New
+// resolves them once, keeping the lookups off the send path.
+func (s *ScarfEventLogger) consentPaths() (path, legacy string) {
+    if s.consent.path != "" {
+        return s.consent.path, ""
+    }
+    legacy = s.legacyConsentPath()
+    // An explicit state directory holds all persisted state.
+    if s.stateDir != "" || statedir.Overridden() {
+        dir, err := s.appStateDir()
+        if err != nil || dir == "" {
+            return "", legacy
+        }
+        path = filepath.Join(dir, "consent.json")
+        if path == legacy {
+            legacy = ""
+        }
+        return path, legacy
+    }
+    return legacy, ""
+}
+
+// legacyConsentPath returns the consent file under the user config
+// directory, where consent is persisted without a state directory.
+func (s *ScarfEventLogger) legacyConsentPath() string {
+    if s.appName == "" {
+        return ""
+    }
+    dir, err := os.UserConfigDir()
+    if err != nil {
+        return ""
+    }
+    return filepath.Join(dir, "scarf", s.appName, "consent.json")
+}
+
+// consentStore caches the consent decision and persists it to a file.
+type consentStore struct {
+    path   string // WithConsentFile's until New resolves it
+    legacy string
+
+    mu     sync.Mutex
+    loaded bool
+    state  ConsentState
+}
+
+type consentFile struct {
+    Granted   bool      `json:"granted"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// get returns the decision persisted at path or, if there is none, at
+// legacy. A denial at either path wins, so a user who declined before a
+// state directory was configured is never asked to be opted back in.
+func (c *consentStore) get() ConsentState {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if !c.loaded {
+        c.loaded = true
+        c.state = readConsentFile(c.path)
+        if old := readConsentFile(c.legacy); old == ConsentDenied || c.state == ConsentUnknown {
+            c.state = old
+        }
+    }
+    return c.state
+}
+
+// set persists state to path and, if a decision is persisted there, to
+// legacy too, so the two never disagree.
+func (c *consentStore) set(state ConsentState) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.loaded = true
+    c.state = state
+    if c.path == "" {
+        return nil
+    }
+    if err := writeConsentFile(c.path, state); err != nil {
+        return err
+    }
+    if c.legacy != "" {
+        if _, err := os.Stat(c.legacy); err == nil {
+            return writeConsentFile(c.legacy, state)
+        }
+    }
+    return nil
+}
+
+func writeConsentFile(path string, state ConsentState) error {
+    data, err := json.Marshal(consentFile{Granted: state == ConsentGranted, UpdatedAt: time.Now().UTC()})
+    if err != nil {
+        return fmt.Errorf("scarf: encode consent: %w", err)
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return fmt.Errorf("scarf: persist consent: %w", err)
+    }
+    if err := os.WriteFile(path, data, 0o600); err != nil {
+        return fmt.Errorf("scarf: persist consent: %w", err)
+    }
+    return nil
+}
+
+// readConsentFile returns the persisted decision, or ConsentUnknown if there
+// is none or it cannot be read.
+func readConsentFile(path string) ConsentState {
+    if path == "" {
+        return ConsentUnknown
+    }
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return ConsentUnknown
+    }
+    var f consentFile
+    if err := json.Unmarshal(data, &f); err != nil {
+        return ConsentUnknown
+    }
+    if f.Granted {
+        return ConsentGranted
+    }
+    return ConsentDenied
+}
@@ -0,0 +1,136 @@
+package scarf
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "io"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func countingTransport(n *int) Transport {
+    return TransportFunc(func(context.Context, Event) error {
+        *n++
+        return nil
+    })
+}
+
+func TestConsent_OptInRequiresGrant(t *testing.T) {
+    t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+    sent := 0
+    l := New("", WithTransport(countingTransport(&sent)), WithAppName("mytool"), WithOptIn())
+
+    if got := l.ConsentState(); got != ConsentUnknown {
+        t.Fatalf("expected unknown consent, got %s", got)
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrNoConsent) {
+        t.Fatalf("expected ErrNoConsent before grant, got %v", err)
+    }
+    if err := l.SetConsent(true); err != nil {
+        t.Fatalf("SetConsent: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("expected success after grant, got %v", err)
+    }
+    if sent != 1 {
+        t.Fatalf("expected 1 event sent, got %d", sent)
+    }
+
+    // The decision is persisted for future runs.
+    again := New("", WithTransport(countingTransport(&sent)), WithAppName("mytool"), WithOptIn())
+    if got := again.ConsentState(); got != ConsentGranted {
+        t.Fatalf("expected persisted consent, got %s", got)
+    }
+}
+
+func TestConsent_DeniedBlocksOptOut(t *testing.T) {
+    sent := 0
+    path := filepath.Join(t.TempDir(), "consent.json")
+    l := New("", WithTransport(countingTransport(&sent)), WithConsentFile(path))
+
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("opt-out mode should send without a decision, got %v", err)
+    }
+    if err := l.SetConsent(false); err != nil {
+        t.Fatalf("SetConsent: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrNoConsent) {
+        t.Fatalf("expected ErrNoConsent after denial, got %v", err)
+    }
+    if got := New("", WithConsentFile(path)).ConsentState(); got != ConsentDenied {
+        t.Fatalf("expected persisted denial, got %s", got)
+    }
+}
+
+func TestAskConsent(t *testing.T) {
+    cases := map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false}
+    for in, want := range cases {
+        l := New("", WithAppName("mytool"), WithConsentFile(filepath.Join(t.TempDir(), "c.json")))
+        var out bytes.Buffer
+        got, err := l.AskConsent(strings.NewReader(in), &out, "")
+        if err != nil {
+            t.Fatalf("AskConsent(%q): %v", in, err)
+        }
+        if got != want {
+            t.Fatalf("AskConsent(%q) = %v, want %v", in, got, want)
+        }
+        if !strings.Contains(out.String(), "mytool") || !strings.HasSuffix(out.String(), "[y/N] ") {
+            t.Fatalf("unexpected prompt %q", out.String())
+        }
+        wantState := ConsentDenied
+        if want {
+            wantState = ConsentGranted
+        }
+        if l.ConsentState() != wantState {
+            t.Fatalf("AskConsent(%q) recorded %s", in, l.ConsentState())
+        }
+    }
+}
+
+func TestAskConsent_LeavesRestOfInput(t *testing.T) {
+    in := struct{ io.Reader }{strings.NewReader("y\nnext command\n")}
+    l := New("", WithConsentFile(filepath.Join(t.TempDir(), "c.json")))
+    if got, err := l.AskConsent(in, io.Discard, "ok?"); err != nil || !got {
+        t.Fatalf("AskConsent = %v, %v", got, err)
+    }
+    if rest, _ := io.ReadAll(in); string(rest) != "next command\n" {
+        t.Fatalf("expected the input after the answer to be left unread, got %q", rest)
+    }
+}
+
+func TestConsent_InMemoryWithoutPath(t *testing.T) {
+    l := New("", WithOptIn())
+    if err := l.SetConsent(true); err != nil {
+        t.Fatalf("SetConsent without a path should not fail, got %v", err)
+    }
+    if l.ConsentState() != ConsentGranted {
+        t.Fatalf("expected in-memory grant")
+    }
+}
+
+func TestConsent_StateDirKeepsEarlierDecision(t *testing.T) {
+    t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+    t.Setenv("HOME", t.TempDir())
+    t.Setenv("SCARF_STATE_DIR", "")
+    if err := New("", WithAppName("mytool")).SetConsent(false); err != nil {
+        t.Fatalf("SetConsent: %v", err)
+    }
+
+    t.Setenv("SCARF_STATE_DIR", t.TempDir())
+    l := New("", WithAppName("mytool"))
+    if got := l.ConsentState(); got != ConsentDenied || l.DisabledReason() == "" {
+        t.Fatalf("expected the earlier denial to apply with a state directory, got %s (reason %q)", got, l.DisabledReason())
+    }
+
+    // A later decision updates both files, so it sticks.
+    if err := l.SetConsent(true); err != nil {
+        t.Fatalf("SetConsent: %v", err)
+    }
+    for _, l := range []*ScarfEventLogger{New("", WithAppName("mytool")), New("", WithAppName("mytool"), WithStateDir(t.TempDir()))} {
+        if got := l.ConsentState(); got != ConsentGranted {
+            t.Fatalf("expected the later grant to apply, got %s", got)
+        }
+    }
+}
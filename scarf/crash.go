@@ -0,0 +1,72 @@
+package scarf
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "runtime"
+    "strings"
+)
+
+// WithSwallowPanics makes Recover swallow the panics it reports instead of
+// re-panicking.
+func WithSwallowPanics() Option {
+    return func(s *ScarfEventLogger) {
+        s.swallowPanics = true
+    }
+}
+
+// Recover reports a panic in progress as a "crash" event and then re-panics
+// with the same value (or, with WithSwallowPanics, lets the function return
+// normally). It must be deferred directly:
+//
+//   defer logger.Recover(map[string]any{"command": "build"})
+//
+// The event carries props plus panic_type, the Go type of the panic value,
+// and stack_signature, a hash of the function names on the panicking
+// goroutine's stack. Neither the panic message nor the trace is sent, so
+// crashes can be grouped without shipping file paths or user data.
+func (s *ScarfEventLogger) Recover(props map[string]any) {
+    if s == nil {
+        return
+    }
+    v := recover()
+    if v == nil {
+        return
+    }
+    if refused, _ := s.refuse(); !refused {
+        ev := eventFromProperties(copyProperties(props))
+        if ev.Name == "" {
+            ev.Name = "crash"
+        }
+        ev.Properties["panic_type"] = fmt.Sprintf("%T", v)
+        ev.Properties["stack_signature"] = stackSignature(3)
+        if err := s.LogEventStruct(ev); err != nil {
+            s.debug("crash event not sent", "error", err)
+        }
+    }
+    if !s.swallowPanics {
+        panic(v)
+    }
+}
+
+// stackSignature hashes the function names on the current goroutine's stack,
+// skipping the given number of frames and runtime internals, into a short
+// stable identifier.
+func stackSignature(skip int) string {
+    pcs := make([]uintptr, 64)
+    n := runtime.Callers(skip, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+    h := sha256.New()
+    for {
+        f, more := frames.Next()
+        if f.Function != "" && !strings.HasPrefix(f.Function, "runtime.") {
+            h.Write([]byte(f.Function))
+            h.Write([]byte{'\n'})
+        }
+        if !more {
+            break
+        }
+    }
+    return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,65 @@
+package scarf
+
+import (
+    "strings"
+    "testing"
+)
+
+func panicky(l *ScarfEventLogger) {
+    defer l.Recover(map[string]any{"command": "build"})
+    panic("secret /home/alice/file")
+}
+
+func TestRecover_ReportsAndRepanics(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+
+    func() {
+        defer func() {
+            if r := recover(); r != "secret /home/alice/file" {
+                t.Fatalf("expected original panic value to be re-raised, got %v", r)
+            }
+        }()
+        panicky(l)
+    }()
+
+    if len(got) != 1 {
+        t.Fatalf("expected 1 crash event, got %d", len(got))
+    }
+    ev := got[0]
+    if ev.Name != "crash" || ev.Properties["command"] != "build" || ev.Properties["panic_type"] != "string" {
+        t.Fatalf("unexpected crash event: %+v", ev)
+    }
+    sig, _ := ev.Properties["stack_signature"].(string)
+    if len(sig) != 16 {
+        t.Fatalf("expected 16-character stack signature, got %q", sig)
+    }
+    for _, v := range ev.Properties {
+        if s, ok := v.(string); ok && strings.Contains(s, "alice") {
+            t.Fatalf("panic message leaked into event: %+v", ev.Properties)
+        }
+    }
+}
+
+func TestRecover_StableSignatureAndSwallow(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithSwallowPanics())
+    for i := 0; i < 2; i++ {
+        panicky(l)
+    }
+    if len(got) != 2 {
+        t.Fatalf("expected 2 crash events, got %d", len(got))
+    }
+    if got[0].Properties["stack_signature"] != got[1].Properties["stack_signature"] {
+        t.Fatalf("expected the same panic site to hash identically: %v vs %v", got[0].Properties, got[1].Properties)
+    }
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+    func() { defer l.Recover(nil) }()
+    if len(got) != 0 {
+        t.Fatalf("expected no event without a panic, got %d", len(got))
+    }
+}
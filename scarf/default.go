@@ -0,0 +1,34 @@
+package scarf
+
+import (
+    "errors"
+    "sync/atomic"
+)
+
+// ErrNoDefaultLogger is returned by the package-level helpers when no default
+// logger has been set with SetDefault.
+var ErrNoDefaultLogger = errors.New("scarf: no default logger configured")
+
+var defaultLogger atomic.Pointer[ScarfEventLogger]
+
+// SetDefault makes l the logger used by the package-level Log helpers, so
+// libraries can emit telemetry without threading a logger through every call
+// path. Passing nil clears the default. It is safe for concurrent use.
+func SetDefault(l *ScarfEventLogger) {
+    defaultLogger.Store(l)
+}
+
+// Default returns the logger set with SetDefault, or nil if none is set.
+func Default() *ScarfEventLogger {
+    return defaultLogger.Load()
+}
+
+// Log sends a named event through the default logger.
+// It returns ErrNoDefaultLogger if SetDefault has not been called.
+func Log(event string, properties map[string]any) error {
+    l := Default()
+    if l == nil {
+        return ErrNoDefaultLogger
+    }
+    return l.LogEventStruct(Event{Name: event, Properties: properties})
+}
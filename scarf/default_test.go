@@ -0,0 +1,35 @@
+package scarf
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestDefaultLogger(t *testing.T) {
+    t.Cleanup(func() { SetDefault(nil) })
+
+    SetDefault(nil)
+    if err := Log("startup", nil); !errors.Is(err, ErrNoDefaultLogger) {
+        t.Fatalf("expected ErrNoDefaultLogger, got %v", err)
+    }
+
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        if q.Get("event") != "startup" || q.Get("version") != "1.2.3" {
+            t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+        }
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL)
+    SetDefault(l)
+    if Default() != l {
+        t.Fatalf("Default() did not return the logger passed to SetDefault")
+    }
+    if err := Log("startup", map[string]any{"version": "1.2.3"}); err != nil {
+        t.Fatalf("expected success, got %v", err)
+    }
+}
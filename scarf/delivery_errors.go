@@ -0,0 +1,129 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "time"
+)
+
+// ErrNoEndpoint is returned when an event has no destination: the endpoint
+// URL is empty and no transport or route applies.
+var ErrNoEndpoint = errors.New("scarf: endpoint URL is required")
+
+// ErrQueueFull is returned without sending when the number of sends in
+// progress has reached the limit set with WithMaxPending.
+var ErrQueueFull = errors.New("scarf: too many sends in progress")
+
+// EndpointError is returned when the endpoint answers with a non-2xx status.
+// If the endpoint asked the client to back off (429, or 503 with
+// Retry-After), RetryAfter is set and the error also matches ErrRateLimited:
+//
+//   var epErr *scarf.EndpointError
+//   switch {
+//   case errors.As(err, &epErr) && epErr.StatusCode == http.StatusUnauthorized:
+//       // misconfigured endpoint
+//   case errors.Is(err, scarf.ErrRateLimited), errors.Is(err, scarf.ErrQueueFull):
+//       // shed load
+//   }
+type EndpointError struct {
+    StatusCode int
+    // Status is the response status line, e.g. "503 Service Unavailable".
+    Status string
+    // RetryAfter is when the endpoint allows sending again, if it said so.
+    RetryAfter time.Time
+}
+
+func (e *EndpointError) Error() string {
+    if !e.RetryAfter.IsZero() {
+        return fmt.Sprintf("%v (%s); retry after %s", ErrRateLimited, e.Status, e.RetryAfter.UTC().Format(time.RFC3339))
+    }
+    return "scarf: non-success status: " + e.Status
+}
+
+// Unwrap returns ErrRateLimited if the endpoint asked the client to back off.
+func (e *EndpointError) Unwrap() error {
+    if !e.RetryAfter.IsZero() {
+        return ErrRateLimited
+    }
+    return nil
+}
+
+// ErrTimeout matches errors for sends that ran past the logger's timeout
+// (WithTimeout): the endpoint, or a custom Transport, was too slow.
+var ErrTimeout = errors.New("scarf: timed out waiting for the endpoint")
+
+// ErrDeadlineExceeded matches errors for sends cut short by the deadline of
+// the caller's context, which was shorter than the logger's timeout. Unlike
+// ErrTimeout, it says nothing about the endpoint's health.
+var ErrDeadlineExceeded = errors.New("scarf: context deadline exceeded before the endpoint responded")
+
+// NetworkError is returned when no response was received from the endpoint,
+// e.g. because of a DNS, connection or TLS failure or a timeout. Timeouts
+// also match ErrTimeout or ErrDeadlineExceeded, depending on which deadline
+// ran out.
+type NetworkError struct {
+    // URL is the request URL, without the query string.
+    URL string
+    Err error
+
+    deadline error // ErrTimeout, ErrDeadlineExceeded or nil
+}
+
+func (e *NetworkError) Error() string {
+    if e.deadline != nil {
+        return e.deadline.Error() + ": " + e.Err.Error()
+    }
+    return "scarf: request failed: " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() []error {
+    if e.deadline != nil {
+        return []error{e.deadline, e.Err}
+    }
+    return []error{e.Err}
+}
+
+// Timeout reports whether the request timed out.
+func (e *NetworkError) Timeout() bool {
+    var t interface{ Timeout() bool }
+    return errors.As(e.Err, &t) && t.Timeout()
+}
+
+// newNetworkError builds the error for a request to u, made under the
+// caller's context parent, that failed with err. The query is dropped from the
+// reported URL to keep event properties out of the message, which callers are
+// likely to log.
+func newNetworkError(parent context.Context, u *url.URL, err error) *NetworkError {
+    stripped := *u
+    stripped.RawQuery = ""
+    var urlErr *url.Error
+    if errors.As(err, &urlErr) {
+        urlErr.URL = stripped.String()
+    }
+    return &NetworkError{URL: stripped.String(), Err: err, deadline: deadlineCause(parent, err)}
+}
+
+// deadlineCause tells which deadline a send that failed with err ran out of:
+// ErrDeadlineExceeded if parent, the caller's context, expired, or ErrTimeout
+// for the logger's own timeout. It returns nil for other failures.
+func deadlineCause(parent context.Context, err error) error {
+    switch {
+    case errors.Is(parent.Err(), context.DeadlineExceeded):
+        return ErrDeadlineExceeded
+    case parent.Err() != nil:
+        return nil
+    }
+    var t interface{ Timeout() bool }
+    if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &t) && t.Timeout() {
+        return ErrTimeout
+    }
+    return nil
+}
+
+// newEndpointError builds the error for a non-2xx response.
+func newEndpointError(resp *http.Response) *EndpointError {
+    return &EndpointError{StatusCode: resp.StatusCode, Status: resp.Status}
+}
@@ -0,0 +1,133 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestEndpointError(t *testing.T) {
+    status := http.StatusUnauthorized
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if status == http.StatusTooManyRequests {
+            w.Header().Set("Retry-After", "30")
+        }
+        w.WriteHeader(status)
+    }))
+    defer srv.Close()
+    l := New(srv.URL)
+
+    err := l.LogEvent(map[string]any{"event": "x"})
+    var epErr *EndpointError
+    if !errors.As(err, &epErr) || epErr.StatusCode != http.StatusUnauthorized || errors.Is(err, ErrRateLimited) {
+        t.Fatalf("expected a 401 EndpointError, got %#v", err)
+    }
+    if err.Error() != "scarf: non-success status: 401 Unauthorized" {
+        t.Fatalf("unexpected message %q", err)
+    }
+
+    status = http.StatusTooManyRequests
+    err = l.LogEvent(map[string]any{"event": "x"})
+    if !errors.As(err, &epErr) || epErr.StatusCode != http.StatusTooManyRequests || !errors.Is(err, ErrRateLimited) {
+        t.Fatalf("expected a rate-limited EndpointError, got %#v", err)
+    }
+    if d := time.Until(epErr.RetryAfter); d < 20*time.Second || d > 30*time.Second {
+        t.Fatalf("unexpected RetryAfter %s", epErr.RetryAfter)
+    }
+}
+
+func TestNetworkError(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(200 * time.Millisecond)
+    }))
+    defer srv.Close()
+
+    err := New(srv.URL+"/e?pkg=x", WithTimeout(20*time.Millisecond)).LogEvent(map[string]any{"event": "x"})
+    var netErr *NetworkError
+    if !errors.As(err, &netErr) || !netErr.Timeout() || netErr.URL != srv.URL+"/e" {
+        t.Fatalf("expected a timed-out NetworkError, got %#v", err)
+    }
+}
+
+func TestDeadlineClassification(t *testing.T) {
+    release := make(chan struct{})
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        select {
+        case <-release:
+        case <-time.After(2 * time.Second):
+        }
+    }))
+    defer srv.Close()
+    defer close(release)
+
+    // The caller's deadline is shorter than the logger's timeout.
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    start := time.Now()
+    err := New(srv.URL, WithTimeout(5*time.Second)).LogEventContext(ctx, map[string]any{"event": "x"})
+    if !errors.Is(err, ErrDeadlineExceeded) || errors.Is(err, ErrTimeout) {
+        t.Fatalf("expected ErrDeadlineExceeded, got %v", err)
+    }
+    if time.Since(start) > time.Second {
+        t.Fatalf("expected the caller's deadline to apply, took %s", time.Since(start))
+    }
+
+    // The logger's timeout runs out first.
+    err = New(srv.URL, WithTimeout(20*time.Millisecond)).LogEventContext(context.Background(), map[string]any{"event": "x"})
+    if !errors.Is(err, ErrTimeout) || errors.Is(err, ErrDeadlineExceeded) {
+        t.Fatalf("expected ErrTimeout, got %v", err)
+    }
+    var netErr *NetworkError
+    if !errors.As(err, &netErr) || !netErr.Timeout() {
+        t.Fatalf("expected a timed-out NetworkError, got %#v", err)
+    }
+
+    // Custom transports are classified the same way.
+    slow := WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        <-ctx.Done()
+        return ctx.Err()
+    }))
+    ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    if err := New("", slow, WithTimeout(5*time.Second)).LogEventContext(ctx, nil); !errors.Is(err, ErrDeadlineExceeded) {
+        t.Fatalf("expected ErrDeadlineExceeded from the transport, got %v", err)
+    }
+    if err := New("", slow, WithTimeout(20*time.Millisecond)).LogEvent(nil); !errors.Is(err, ErrTimeout) {
+        t.Fatalf("expected ErrTimeout from the transport, got %v", err)
+    }
+}
+
+func TestErrNoEndpoint(t *testing.T) {
+    if err := New("").LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrNoEndpoint) {
+        t.Fatalf("expected ErrNoEndpoint, got %v", err)
+    }
+}
+
+func TestWithMaxPending(t *testing.T) {
+    release := make(chan struct{})
+    started := make(chan struct{})
+    l := New("", WithMaxPending(1), WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        if ev.Name == "slow" {
+            close(started)
+            <-release
+        }
+        return nil
+    })))
+    go func() { _ = l.LogEvent(map[string]any{"event": "slow"}) }()
+    <-started
+
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrQueueFull) {
+        t.Fatalf("expected ErrQueueFull, got %v", err)
+    }
+    close(release)
+    l.Flush(context.Background())
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("expected a free slot after the send finished, got %v", err)
+    }
+    if st := l.Stats(); st.Dropped[DropQueueFull] != 1 {
+        t.Fatalf("expected the drop to be counted, got %v", st.Dropped)
+    }
+}
@@ -0,0 +1,122 @@
+package scarf
+
+import (
+    "context"
+    "net"
+    "net/url"
+    "sync"
+    "time"
+)
+
+// Resolver looks up the addresses of a host. *net.Resolver satisfies it.
+type Resolver interface {
+    LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// WithDNSCache caches the endpoints' DNS lookups for ttl and resolves their
+// hosts in the background as soon as the logger is created, so the first
+// event of a short-lived CLI doesn't wait on a slow resolver. When a refresh
+// fails, the expired addresses keep being used.
+//
+// Like WithProxy it configures the client the SDK constructs; it has no effect
+// together with WithHTTPClient, or in the browser, where fetch resolves hosts.
+func WithDNSCache(ttl time.Duration) Option {
+    return func(s *ScarfEventLogger) {
+        s.dnsCache().ttl = ttl
+    }
+}
+
+// WithResolver looks up endpoint hosts with r instead of the system
+// resolver, e.g. a *net.Resolver pointed at a specific DNS server. Combine it
+// with WithDNSCache to cache the results.
+func WithResolver(r Resolver) Option {
+    return func(s *ScarfEventLogger) {
+        if r != nil {
+            s.dnsCache().resolver = r
+        }
+    }
+}
+
+func (s *ScarfEventLogger) dnsCache() *dnsCache {
+    if s.dns == nil {
+        s.dns = &dnsCache{resolver: net.DefaultResolver, entries: make(map[string]dnsEntry)}
+    }
+    return s.dns
+}
+
+// dnsCache resolves hosts for the SDK's dialer, caching results for ttl if
+// it is positive.
+type dnsCache struct {
+    ttl      time.Duration
+    resolver Resolver
+
+    mu      sync.Mutex
+    entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+    addrs   []string
+    expires time.Time
+}
+
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+    c.mu.Lock()
+    e, cached := c.entries[host]
+    c.mu.Unlock()
+    if cached && time.Now().Before(e.expires) {
+        return e.addrs, nil
+    }
+    addrs, err := c.resolver.LookupHost(ctx, host)
+    if err != nil {
+        if cached {
+            return e.addrs, nil
+        }
+        return nil, err
+    }
+    if c.ttl > 0 {
+        c.mu.Lock()
+        c.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+        c.mu.Unlock()
+    }
+    return addrs, nil
+}
+
+// dialContext wraps dial so host names are resolved through the cache. The
+// addresses are tried in order until one connects.
+func (c *dnsCache) dialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+    return func(ctx context.Context, network, addr string) (net.Conn, error) {
+        host, port, err := net.SplitHostPort(addr)
+        if err != nil || net.ParseIP(host) != nil {
+            return dial(ctx, network, addr)
+        }
+        addrs, err := c.lookup(ctx, host)
+        if err != nil {
+            return nil, err
+        }
+        var firstErr error
+        for _, a := range addrs {
+            conn, err := dial(ctx, network, net.JoinHostPort(a, port))
+            if err == nil {
+                return conn, nil
+            }
+            if firstErr == nil {
+                firstErr = err
+            }
+            if ctx.Err() != nil {
+                break
+            }
+        }
+        return nil, firstErr
+    }
+}
+
+// prefetch resolves the hosts of endpoints, warming the cache.
+func (c *dnsCache) prefetch(ctx context.Context, endpoints []string) {
+    for _, endpoint := range endpoints {
+        u, err := url.Parse(endpoint)
+        if err != nil || u.Hostname() == "" || net.ParseIP(u.Hostname()) != nil {
+            continue
+        }
+        _, _ = c.lookup(ctx, u.Hostname())
+    }
+}
@@ -0,0 +1,100 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+    "time"
+)
+
+type fakeResolver struct {
+    mu      sync.Mutex
+    lookups int
+    err     error
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.lookups++
+    if r.err != nil {
+        return nil, r.err
+    }
+    if host != "telemetry.test" {
+        return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+    }
+    return []string{"127.0.0.1"}, nil
+}
+
+func (r *fakeResolver) count() int {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return r.lookups
+}
+
+func TestDNSCache(t *testing.T) {
+    srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+    // Force a new dial for every request.
+    srv.Config.SetKeepAlivesEnabled(false)
+    srv.Start()
+    defer srv.Close()
+    _, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+    r := &fakeResolver{}
+    l := New("http://telemetry.test:"+port+"/", WithDNSCache(time.Minute), WithResolver(r))
+
+    // The endpoint host is resolved in the background by New.
+    deadline := time.Now().Add(2 * time.Second)
+    for r.count() == 0 && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond)
+    }
+    if got := r.count(); got != 1 {
+        t.Fatalf("expected New to pre-resolve the endpoint once, got %d lookups", got)
+    }
+    for i := 0; i < 3; i++ {
+        if err := l.LogEvent(map[string]any{"i": i}); err != nil {
+            t.Fatalf("LogEvent: %v", err)
+        }
+    }
+    if got := r.count(); got != 1 {
+        t.Fatalf("expected cached lookups, got %d", got)
+    }
+
+    // An expired entry is resolved again...
+    expire := func() {
+        l.dns.mu.Lock()
+        e := l.dns.entries["telemetry.test"]
+        e.expires = time.Now().Add(-time.Second)
+        l.dns.entries["telemetry.test"] = e
+        l.dns.mu.Unlock()
+    }
+    expire()
+    if err := l.LogEvent(nil); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if got := r.count(); got != 2 {
+        t.Fatalf("expected an expired entry to be re-resolved, got %d lookups", got)
+    }
+
+    // ...but kept if the resolver fails.
+    expire()
+    r.mu.Lock()
+    r.err = errors.New("resolver down")
+    r.mu.Unlock()
+    if err := l.LogEvent(nil); err != nil {
+        t.Fatalf("expected the stale entry to be used, got %v", err)
+    }
+}
+
+func TestDNSCacheNoPrefetchWhenDisabled(t *testing.T) {
+    r := &fakeResolver{}
+    New("http://telemetry.test/", WithDNSCache(time.Minute), WithResolver(r), WithEnabled(false))
+    time.Sleep(20 * time.Millisecond)
+    if got := r.count(); got != 0 {
+        t.Fatalf("expected no lookups from a disabled logger, got %d", got)
+    }
+}
@@ -0,0 +1,25 @@
+//go:build js && wasm
+
+package scarf
+
+import (
+    "syscall/js"
+)
+
+// platformDoNotTrack describes the browser's Do Not Track or Global Privacy
+// Control setting if either is on; they take the place of the DO_NOT_TRACK
+// environment variable when running in a browser. Outside a browser (e.g.
+// under Node.js) the navigator object is absent and this reports "".
+func platformDoNotTrack() string {
+    nav := js.Global().Get("navigator")
+    if nav.IsUndefined() || nav.IsNull() {
+        return ""
+    }
+    if dnt := nav.Get("doNotTrack"); dnt.Type() == js.TypeString && dnt.String() == "1" {
+        return "browser Do Not Track is enabled"
+    }
+    if gpc := nav.Get("globalPrivacyControl"); gpc.Type() == js.TypeBoolean && gpc.Bool() {
+        return "browser Global Privacy Control is enabled"
+    }
+    return ""
+}
@@ -0,0 +1,10 @@
+//go:build !(js && wasm)
+
+package scarf
+
+// platformDoNotTrack describes the platform-level Do Not Track setting that
+// is turned on, if any, beyond the environment. Only browsers have one; see
+// dnt_js.go.
+func platformDoNotTrack() string {
+    return ""
+}
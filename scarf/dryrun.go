@@ -0,0 +1,142 @@
+package scarf
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "sync"
+)
+
+// maxDryRunRecords bounds the number of requests kept in memory in dry-run mode.
+const maxDryRunRecords = 100
+
+// PreparedRequest is a fully built request that dry-run mode recorded instead
+// of sending. For an event bound for a custom Transport, Method and URL are
+// empty and Body holds the event as a JSON object.
+type PreparedRequest struct {
+    Method string
+    URL    string
+    Header http.Header
+    Body   []byte
+}
+
+// WithDryRun builds every event exactly as it would be sent (URL, headers,
+// payload) but never transmits it, over HTTP or through a custom or route
+// Transport. Each request is logged at info level, regardless of verbose
+// mode, and the most recent ones are available from DryRunRequests. LogEvent
+// returns nil for recorded events.
+//
+// Use it to audit what would leave the machine before enabling telemetry.
+func WithDryRun(enabled bool) Option {
+    return func(s *ScarfEventLogger) {
+        s.dryRun = enabled
+    }
+}
+
+type dryRunLog struct {
+    mu       sync.Mutex
+    requests []PreparedRequest
+}
+
+func (d *dryRunLog) add(r PreparedRequest) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    if len(d.requests) == maxDryRunRecords {
+        copy(d.requests, d.requests[1:])
+        d.requests = d.requests[:len(d.requests)-1]
+    }
+    d.requests = append(d.requests, r)
+}
+
+// DryRunRequests returns the most recent requests recorded in dry-run mode,
+// oldest first.
+func (s *ScarfEventLogger) DryRunRequests() []PreparedRequest {
+    if s == nil {
+        return nil
+    }
+    s.dryRunLog.mu.Lock()
+    defer s.dryRunLog.mu.Unlock()
+    out := make([]PreparedRequest, len(s.dryRunLog.requests))
+    copy(out, s.dryRunLog.requests)
+    return out
+}
+
+// PrepareRequest builds the request LogEventStruct would send for ev to the
+// endpoint URL, through the same pipeline (defaults, filtering, enrichment,
+// timestamp and ID) and encoding, without sending it, e.g. to snapshot the
+// wire format in tests. Routes, fallback endpoints and a custom Transport are
+// ignored. It fails like LogEvent when events are refused, and with
+// ErrSampledOut if ev is sampled out.
+func (s *ScarfEventLogger) PrepareRequest(ctx context.Context, ev Event) (PreparedRequest, error) {
+    if refused, err := s.refuse(); refused {
+        if err == nil {
+            err = ErrDisabled
+        }
+        return PreparedRequest{}, err
+    }
+    if strings.TrimSpace(s.endpointURL) == "" {
+        return PreparedRequest{}, ErrNoEndpoint
+    }
+    ev, ok := s.prepare(ctx, ev)
+    if !ok {
+        return PreparedRequest{}, ErrSampledOut
+    }
+    if err := s.checkStrict(ev); err != nil {
+        return PreparedRequest{}, err
+    }
+    rawURL, body, contentType, err := s.encodeHTTP(s.endpointURL, ev)
+    if err != nil {
+        return PreparedRequest{}, err
+    }
+    req, err := s.newRequest(http.MethodPost, rawURL, body, contentType)
+    if err != nil {
+        return PreparedRequest{}, fmt.Errorf("scarf: build request: %w", err)
+    }
+    req = req.WithContext(ctx)
+    s.setTraceparent(ctx, req)
+    return capturePrepared(req)
+}
+
+// capturePrepared copies req, leaving its body readable.
+func capturePrepared(req *http.Request) (PreparedRequest, error) {
+    p := PreparedRequest{
+        Method: req.Method,
+        URL:    req.URL.String(),
+        Header: req.Header.Clone(),
+    }
+    if req.Body != nil {
+        body, err := io.ReadAll(req.Body)
+        if err != nil {
+            return PreparedRequest{}, err
+        }
+        p.Body = body
+        req.Body = io.NopCloser(bytes.NewReader(body))
+    }
+    return p, nil
+}
+
+// recordDryRun captures req in place of sending it.
+func (s *ScarfEventLogger) recordDryRun(req *http.Request) error {
+    p, err := capturePrepared(req)
+    if err != nil {
+        return err
+    }
+    s.dryRunLog.add(p)
+    s.logger.Info("dry run: event not sent", "method", p.Method, "url", p.URL, "user_agent", p.Header.Get("User-Agent"), "body_bytes", len(p.Body))
+    return nil
+}
+
+// recordDryRunEvent captures ev in place of handing it to a Transport.
+func (s *ScarfEventLogger) recordDryRunEvent(ev Event) error {
+    body, err := ev.jsonBody()
+    if err != nil {
+        return err
+    }
+    p := PreparedRequest{Header: http.Header{"Content-Type": {"application/json"}}, Body: body}
+    s.dryRunLog.add(p)
+    s.logger.Info("dry run: event not sent", "event", ev.Name, "body_bytes", len(body))
+    return nil
+}
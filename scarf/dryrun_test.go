@@ -0,0 +1,111 @@
+package scarf
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestDryRun_RecordsWithoutSending(t *testing.T) {
+    hit := false
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        hit = true
+    }))
+    defer srv.Close()
+
+    var buf bytes.Buffer
+    l := New(srv.URL, WithDryRun(true), WithAPIKey("k"), WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+    for i := 0; i < 2; i++ {
+        if err := l.LogEvent(map[string]any{"event": "audit", "n": i}); err != nil {
+            t.Fatalf("expected nil in dry run, got %v", err)
+        }
+    }
+    if hit {
+        t.Fatalf("dry run must not contact the endpoint")
+    }
+
+    reqs := l.DryRunRequests()
+    if len(reqs) != 2 {
+        t.Fatalf("expected 2 recorded requests, got %d", len(reqs))
+    }
+    r := reqs[1]
+    if r.Method != http.MethodPost || !strings.HasPrefix(r.URL, srv.URL) || !strings.Contains(r.URL, "event=audit") || !strings.Contains(r.URL, "n=1") {
+        t.Fatalf("unexpected recorded request: %+v", r)
+    }
+    if r.Header.Get("Authorization") != "Bearer k" || !strings.HasPrefix(r.Header.Get("User-Agent"), "scarf-go/") {
+        t.Fatalf("expected headers to be recorded, got %v", r.Header)
+    }
+    if !strings.Contains(buf.String(), "dry run: event not sent") {
+        t.Fatalf("expected dry run to be logged without verbose, got %q", buf.String())
+    }
+}
+
+func TestDryRun_Bounded(t *testing.T) {
+    l := New("https://example.com", WithDryRun(true), WithLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))))
+    for i := 0; i < maxDryRunRecords+5; i++ {
+        _ = l.LogEvent(map[string]any{"event": "x"})
+    }
+    if got := len(l.DryRunRequests()); got != maxDryRunRecords {
+        t.Fatalf("expected %d records, got %d", maxDryRunRecords, got)
+    }
+}
+
+func TestDryRun_Transport(t *testing.T) {
+    sent := 0
+    tr := TransportFunc(func(ctx context.Context, ev Event) error {
+        sent++
+        return nil
+    })
+    l := New("", WithDryRun(true), WithTransport(tr), WithLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))),
+        WithRoutes(Route{Pattern: "routed", Transport: tr}))
+    for _, name := range []string{"plain", "routed"} {
+        if err := l.LogEvent(map[string]any{"event": name}); err != nil {
+            t.Fatalf("expected nil in dry run, got %v", err)
+        }
+    }
+    if err := l.LogEvents(context.Background(), []Event{{Name: "batched"}}); err != nil {
+        t.Fatalf("expected nil in dry run, got %v", err)
+    }
+    if sent != 0 {
+        t.Fatalf("dry run must not call the transport, called %d times", sent)
+    }
+    reqs := l.DryRunRequests()
+    if len(reqs) != 3 || reqs[0].URL != "" || !strings.Contains(string(reqs[1].Body), `"event":"routed"`) {
+        t.Fatalf("expected the events to be recorded, got %+v", reqs)
+    }
+}
+
+func TestPrepareRequest(t *testing.T) {
+    hit := false
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        hit = true
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithAPIKey("k"), WithEncoding(EncodingJSON))
+    r, err := l.PrepareRequest(context.Background(), Event{Name: "install", Properties: map[string]any{"v": "1"}})
+    if err != nil {
+        t.Fatalf("PrepareRequest: %v", err)
+    }
+    if hit {
+        t.Fatalf("PrepareRequest must not contact the endpoint")
+    }
+    if r.Method != http.MethodPost || r.URL != srv.URL || r.Header.Get("Authorization") != "Bearer k" || !bytes.Contains(r.Body, []byte(`"event":"install"`)) || !bytes.Contains(r.Body, []byte(`"event_id":`)) {
+        t.Fatalf("unexpected prepared request: %+v %s", r, r.Body)
+    }
+
+    if _, err := New(srv.URL, WithSampleRate(0)).PrepareRequest(context.Background(), Event{Name: "x"}); !errors.Is(err, ErrSampledOut) {
+        t.Fatalf("expected ErrSampledOut, got %v", err)
+    }
+    if _, err := New(srv.URL, WithEnabled(false)).PrepareRequest(context.Background(), Event{Name: "x"}); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+    if _, err := New("").PrepareRequest(context.Background(), Event{Name: "x"}); !errors.Is(err, ErrNoEndpoint) {
+        t.Fatalf("expected ErrNoEndpoint, got %v", err)
+    }
+}
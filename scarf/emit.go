@@ -0,0 +1,55 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+)
+
+// ErrInvalidPayload is returned by Emit for payloads that don't encode to a
+// JSON object.
+var ErrInvalidPayload = errors.New("scarf: payload must encode to a JSON object")
+
+// Emit sends an event named name whose properties are payload's fields,
+// encoded as by encoding/json: json tags rename and omit fields, and nested
+// values are sent JSON-encoded. It gives events a compile-time shape:
+//
+//   type BuildEvent struct {
+//       Target   string `json:"target"`
+//       Duration int    `json:"duration_ms"`
+//       Cached   bool   `json:"cached,omitempty"`
+//   }
+//   err := scarf.Emit(logger, "build", BuildEvent{Target: "linux/amd64", Duration: 1200})
+//
+// payload must be a struct, a map or a pointer to one. A field named "event"
+// is overridden by name.
+func Emit[T any](l EventLogger, name string, payload T) error {
+    if s, ok := l.(*ScarfEventLogger); ok {
+        if refused, err := s.refuse(); refused {
+            return err
+        }
+    }
+    props, err := payloadProperties(payload)
+    if err != nil {
+        return err
+    }
+    props["event"] = name
+    return l.LogEvent(props)
+}
+
+// payloadProperties converts payload to a property map via JSON, keeping
+// numbers exact.
+func payloadProperties(payload any) (map[string]any, error) {
+    b, err := json.Marshal(payload)
+    if err != nil {
+        return nil, fmt.Errorf("scarf: encode payload: %w", err)
+    }
+    dec := json.NewDecoder(bytes.NewReader(b))
+    dec.UseNumber()
+    var props map[string]any
+    if err := dec.Decode(&props); err != nil || props == nil {
+        return nil, ErrInvalidPayload
+    }
+    return props, nil
+}
@@ -0,0 +1,74 @@
+package scarf
+
+import (
+    "errors"
+    "testing"
+)
+
+type buildEvent struct {
+    Target   string            `json:"target"`
+    Duration int64             `json:"duration_ms"`
+    Cached   bool              `json:"cached,omitempty"`
+    Labels   map[string]string `json:"labels,omitempty"`
+    internal string
+}
+
+func TestEmit(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+    err := Emit(l, "build", buildEvent{Target: "linux/amd64", Duration: 9007199254740993, Labels: map[string]string{"ci": "gh"}, internal: "x"})
+    if err != nil {
+        t.Fatalf("Emit: %v", err)
+    }
+    ev := got[0]
+    if ev.Name != "build" || ev.Properties["target"] != "linux/amd64" {
+        t.Fatalf("unexpected event: %+v", ev)
+    }
+    if _, ok := ev.Properties["cached"]; ok {
+        t.Fatalf("omitempty field should be omitted: %v", ev.Properties)
+    }
+    if _, ok := ev.Properties["internal"]; ok {
+        t.Fatalf("unexported field should be omitted: %v", ev.Properties)
+    }
+    q := ev.queryValues()
+    if q.Get("duration_ms") != "9007199254740993" || q.Get("labels") != `{"ci":"gh"}` {
+        t.Fatalf("unexpected encoding: %v", q)
+    }
+
+    if err := Emit(l, "ptr", &buildEvent{Target: "x"}); err != nil {
+        t.Fatalf("Emit pointer: %v", err)
+    }
+    if got[1].Name != "ptr" {
+        t.Fatalf("event name should override payload: %+v", got[1])
+    }
+}
+
+func TestEmit_InvalidPayload(t *testing.T) {
+    l := New("", captureEvents(new([]Event)))
+    for _, payload := range []any{42, "str", []int{1}, (*buildEvent)(nil)} {
+        if err := Emit(l, "x", payload); !errors.Is(err, ErrInvalidPayload) {
+            t.Errorf("Emit(%v): expected ErrInvalidPayload, got %v", payload, err)
+        }
+    }
+    if err := Emit(l, "x", map[string]any{"ch": make(chan int)}); err == nil {
+        t.Errorf("expected encoding error")
+    }
+}
+
+func TestEmit_NoopLogger(t *testing.T) {
+    if err := Emit(NoopLogger{}, "x", buildEvent{}); err != nil {
+        t.Fatalf("Emit: %v", err)
+    }
+}
+
+func BenchmarkEmit_Disabled(b *testing.B) {
+    type buildEvent struct {
+        Target string `json:"target"`
+        Cached bool   `json:"cached"`
+    }
+    l := New("https://example.com", WithEnabled(false))
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        _ = Emit(l, "build", buildEvent{Target: "linux", Cached: true})
+    }
+}
@@ -0,0 +1,152 @@
+package scarf
+
+// SettingSource identifies the layer a setting came from (see
+// ResolvedConfig). Most settings follow defaults < config file < environment <
+// code, but for whether analytics are enabled the first layer with an
+// opinion wins:
+//
+//   1. code: WithEnabled, or SetEnabled at runtime
+//   2. config file: Config.Enabled or Config.Disabled
+//   3. environment: DO_NOT_TRACK, SCARF_NO_ANALYTICS, the browser's Do Not
+//      Track setting, or CI suppression (WithCIMode(CIModeSuppress))
+//   4. default: enabled
+//
+// Consent is separate: a denied or missing consent stops sending whatever
+// the layers decide, and only the user can change it.
+type SettingSource int
+
+const (
+    SourceDefault SettingSource = iota // nothing overrode the default
+    SourceEnv                          // environment variables or platform settings
+    SourceConfig                       // a config file or the Config passed to NewFromConfig
+    SourceCode                         // options passed to New, or setters such as SetEnabled
+)
+
+func (s SettingSource) String() string {
+    switch s {
+    case SourceEnv:
+        return "env"
+    case SourceConfig:
+        return "config"
+    case SourceCode:
+        return "code"
+    default:
+        return "default"
+    }
+}
+
+// setting is one layer's opinion.
+type setting int32
+
+const (
+    settingUnset setting = iota
+    settingOn
+    settingOff
+)
+
+func settingOf(enabled bool) setting {
+    if enabled {
+        return settingOn
+    }
+    return settingOff
+}
+
+// WithEnabled turns analytics on or off in code. It takes precedence over
+// Config and over DO_NOT_TRACK and SCARF_NO_ANALYTICS, so only use
+// WithEnabled(true) for a choice the user made explicitly, e.g. a
+// --telemetry=on flag.
+func WithEnabled(enabled bool) Option {
+    return func(s *ScarfEventLogger) {
+        s.codeEnabled.Store(int32(settingOf(enabled)))
+    }
+}
+
+// decision resolves the layers, returning whether analytics are enabled, the
+// deciding layer and, if disabled, why.
+func (s *ScarfEventLogger) decision() (bool, SettingSource, string) {
+    switch setting(s.codeEnabled.Load()) {
+    case settingOn:
+        return true, SourceCode, ""
+    case settingOff:
+        return false, SourceCode, "disabled in code"
+    }
+    switch s.configEnabled {
+    case settingOn:
+        return true, SourceConfig, ""
+    case settingOff:
+        return false, SourceConfig, "disabled by configuration"
+    }
+    if s.envDisabled != "" {
+        return false, SourceEnv, s.envDisabled
+    }
+    return true, SourceDefault, ""
+}
+
+// Enabled reports whether analytics are enabled.
+func (s *ScarfEventLogger) Enabled() bool {
+    if s == nil {
+        return false
+    }
+    enabled, _, _ := s.decision()
+    return enabled && !s.noop
+}
+
+// EnabledBy reports which layer decided the result of Enabled.
+func (s *ScarfEventLogger) EnabledBy() SettingSource {
+    if s == nil {
+        return SourceDefault
+    }
+    _, src, _ := s.decision()
+    return src
+}
+
+// SetEnabled turns sending off or on at runtime, e.g. after the user changes
+// a setting, replacing any WithEnabled. Like WithEnabled it overrides config
+// and environment. It is safe to call concurrently with sends: events logged
+// after SetEnabled(false) returns are dropped with ErrDisabled, while sends
+// already in progress complete.
+func (s *ScarfEventLogger) SetEnabled(enabled bool) {
+    if s == nil {
+        return
+    }
+    if setting(s.codeEnabled.Swap(int32(settingOf(enabled)))) != settingOf(enabled) {
+        s.info("analytics toggled at runtime", "enabled", enabled)
+    }
+}
+
+// DisabledReason explains why events are not being sent, e.g. "DO_NOT_TRACK
+// is set" or "telemetry consent denied", so tools can tell their users why
+// telemetry is off. It returns "" if events are sent. Unlike Enabled, it also
+// reports missing consent.
+func (s *ScarfEventLogger) DisabledReason() string {
+    if s == nil {
+        return "nil logger"
+    }
+    if buildDisabled {
+        return "built with the scarf_disabled tag"
+    }
+    if s.noop {
+        return "no-op logger"
+    }
+    if enabled, _, reason := s.decision(); !enabled {
+        return reason
+    }
+    if s.checkConsent() != nil {
+        if s.ConsentState() == ConsentDenied {
+            return "telemetry consent denied"
+        }
+        return "telemetry consent not granted (opt-in policy)"
+    }
+    return ""
+}
+
+// envDisabledReason reports which environment setting, if any, turns
+// analytics off.
+func envDisabledReason() string {
+    for _, key := range []string{"DO_NOT_TRACK", "SCARF_NO_ANALYTICS"} {
+        if envBool(key) {
+            return key + " is set"
+        }
+    }
+    return platformDoNotTrack()
+}
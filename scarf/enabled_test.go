@@ -0,0 +1,111 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "path/filepath"
+    "sync"
+    "sync/atomic"
+    "testing"
+)
+
+func TestEnabledPrecedence(t *testing.T) {
+    t.Setenv("DO_NOT_TRACK", "")
+    if l := New(""); !l.Enabled() || l.EnabledBy() != SourceDefault {
+        t.Fatalf("expected enabled by default, got %v from %s", l.Enabled(), l.EnabledBy())
+    }
+
+    t.Setenv("DO_NOT_TRACK", "1")
+    if l := New(""); l.Enabled() || l.EnabledBy() != SourceEnv {
+        t.Fatalf("expected DO_NOT_TRACK to disable, got %v from %s", l.Enabled(), l.EnabledBy())
+    }
+
+    on, off := true, false
+    cfg := Config{EndpointURL: "https://example.com", Enabled: &on}
+    l, err := NewFromConfig(cfg)
+    if err != nil {
+        t.Fatalf("NewFromConfig: %v", err)
+    }
+    if !l.Enabled() || l.EnabledBy() != SourceConfig {
+        t.Fatalf("expected config to override the environment, got %v from %s", l.Enabled(), l.EnabledBy())
+    }
+
+    cfg.Enabled = &off
+    l, _ = NewFromConfig(cfg, WithEnabled(true))
+    if !l.Enabled() || l.EnabledBy() != SourceCode {
+        t.Fatalf("expected code to override config, got %v from %s", l.Enabled(), l.EnabledBy())
+    }
+    l.SetEnabled(false)
+    if l.Enabled() || l.DisabledReason() != "disabled in code" {
+        t.Fatalf("expected SetEnabled to replace WithEnabled, reason %q", l.DisabledReason())
+    }
+
+    cfg.Enabled, cfg.Disabled = &on, true
+    if l, _ := NewFromConfig(cfg); l.Enabled() {
+        t.Fatalf("expected Disabled to win over Enabled")
+    }
+}
+
+func TestDisabledReason(t *testing.T) {
+    t.Setenv("DO_NOT_TRACK", "")
+    t.Setenv("SCARF_NO_ANALYTICS", "1")
+    if got := New("").DisabledReason(); got != "SCARF_NO_ANALYTICS is set" {
+        t.Fatalf("unexpected reason %q", got)
+    }
+
+    t.Setenv("SCARF_NO_ANALYTICS", "")
+    path := filepath.Join(t.TempDir(), "consent.json")
+    l := New("", WithConsentFile(path), WithOptIn())
+    if got := l.DisabledReason(); got != "telemetry consent not granted (opt-in policy)" {
+        t.Fatalf("unexpected reason %q", got)
+    }
+    _ = l.SetConsent(false)
+    if got := l.DisabledReason(); got != "telemetry consent denied" {
+        t.Fatalf("unexpected reason %q", got)
+    }
+    _ = l.SetConsent(true)
+    if got := l.DisabledReason(); got != "" {
+        t.Fatalf("expected no reason once consent is granted, got %q", got)
+    }
+    if got := NewNoopLogger().DisabledReason(); got != "no-op logger" {
+        t.Fatalf("unexpected reason %q", got)
+    }
+}
+
+func TestSetEnabled(t *testing.T) {
+    var sent atomic.Int64
+    l := New("", WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        sent.Add(1)
+        return nil
+    })))
+
+    l.SetEnabled(false)
+    if l.Enabled() || l.DisabledReason() != "disabled in code" {
+        t.Fatalf("expected logger to be disabled, reason %q", l.DisabledReason())
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+    l.SetEnabled(true)
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil || sent.Load() != 1 {
+        t.Fatalf("expected the event to be sent after re-enabling, got %v", err)
+    }
+
+    // Toggle while sending; run with -race.
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            for j := 0; j < 50; j++ {
+                if i == 0 {
+                    l.SetEnabled(j%2 == 0)
+                    continue
+                }
+                _ = l.LogEvent(map[string]any{"event": "x"})
+            }
+        }(i)
+    }
+    wg.Wait()
+
+}
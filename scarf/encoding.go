@@ -0,0 +1,83 @@
+package scarf
+
+import (
+    "net/http"
+
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+)
+
+// Encoding selects how events are encoded on the wire.
+type Encoding int
+
+const (
+    // EncodingQuery sends events as URL query parameters, moving oversized
+    // events into a JSON body (see WithMaxPayloadSize). It is the default.
+    EncodingQuery Encoding = iota
+    // EncodingJSON always sends events as a JSON object body.
+    EncodingJSON
+    // EncodingProtobuf sends events and batches as Protocol Buffers messages
+    // (application/x-protobuf), as defined in scarf/pb/event.proto. It suits
+    // high-volume self-hosted collectors.
+    EncodingProtobuf
+    // EncodingMessagePack sends events as MessagePack maps
+    // (application/msgpack) with the same fields as a JSON body, and batches
+    // as a sequence of such maps. It suits bandwidth-sensitive deployments
+    // such as IoT fleets. If the endpoint answers 415 Unsupported Media Type,
+    // the logger falls back to JSON for the rest of its lifetime.
+    EncodingMessagePack
+)
+
+// WithEncoding sets the wire encoding for events and batches.
+func WithEncoding(e Encoding) Option {
+    return func(s *ScarfEventLogger) {
+        s.encoding = e
+    }
+}
+
+// encodeBody encodes ev as a request body in the logger's body encoding,
+// returning the body and its Content-Type.
+func (s *ScarfEventLogger) encodeBody(ev Event) ([]byte, string, error) {
+    if s.encoding == EncodingProtobuf {
+        e := ev.protobuf()
+        return e.Marshal(), pb.ContentType, nil
+    }
+    if s.useMsgpack() {
+        b, err := ev.msgpackBody()
+        return b, msgpackContentType, err
+    }
+    b, err := ev.jsonBody()
+    return b, "application/json", err
+}
+
+// useMsgpack reports whether bodies should be MessagePack-encoded.
+func (s *ScarfEventLogger) useMsgpack() bool {
+    return s.encoding == EncodingMessagePack && !s.msgpackRejected.Load()
+}
+
+// rejectedMsgpack reports whether a request with the given Content-Type was
+// refused because the endpoint doesn't accept MessagePack, and if so switches
+// the logger to JSON.
+func (s *ScarfEventLogger) rejectedMsgpack(status int, contentType string) bool {
+    if status != http.StatusUnsupportedMediaType || contentType != msgpackContentType {
+        return false
+    }
+    if !s.msgpackRejected.Swap(true) {
+        s.warn("endpoint does not accept MessagePack; falling back to JSON")
+    }
+    return true
+}
+
+// protobuf converts ev to its protobuf form.
+func (e Event) protobuf() pb.Event {
+    out := pb.Event{Name: e.Name, ID: e.ID}
+    if !e.Timestamp.IsZero() {
+        out.TimestampUnixNano = e.Timestamp.UnixNano()
+    }
+    if len(e.Properties) > 0 {
+        out.Properties = make(map[string]pb.Value, len(e.Properties))
+        for k, v := range e.Properties {
+            out.Properties[k] = pb.ValueOf(CanonicalValue(v))
+        }
+    }
+    return out
+}
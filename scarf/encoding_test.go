@@ -0,0 +1,141 @@
+package scarf
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+)
+
+func TestWithEncoding_Protobuf(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    l := New(srv.URL, WithEncoding(EncodingProtobuf))
+    ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+    if err := l.LogEventStruct(Event{Name: "x", Timestamp: ts, ID: "id", Properties: map[string]any{"n": 3, "tags": []string{"a"}}}); err != nil {
+        t.Fatalf("LogEventStruct: %v", err)
+    }
+    if got[0].contentType != pb.ContentType || got[0].url != "/" {
+        t.Fatalf("unexpected request: %+v", got[0])
+    }
+    var ev pb.Event
+    if err := ev.Unmarshal(got[0].body); err != nil {
+        t.Fatalf("Unmarshal: %v", err)
+    }
+    if ev.Name != "x" || ev.ID != "id" || ev.TimestampUnixNano != ts.UnixNano() {
+        t.Fatalf("unexpected event: %+v", ev)
+    }
+    if ev.Properties["n"] != (pb.Value{Kind: pb.KindInt, Int: 3}) || ev.Properties["tags"] != (pb.Value{Kind: pb.KindJSON, String: `["a"]`}) {
+        t.Fatalf("unexpected properties: %+v", ev.Properties)
+    }
+
+    if err := l.LogEvents(context.Background(), []Event{{Name: "a"}, {Name: "b"}}); err != nil {
+        t.Fatalf("LogEvents: %v", err)
+    }
+    var batch pb.Batch
+    if err := batch.Unmarshal(got[1].body); err != nil {
+        t.Fatalf("Unmarshal batch: %v", err)
+    }
+    if len(batch.Events) != 2 || batch.Events[0].Name != "a" || batch.Events[1].Name != "b" {
+        t.Fatalf("unexpected batch: %+v", batch)
+    }
+}
+
+func TestWithEncoding_JSON(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    if err := New(srv.URL, WithEncoding(EncodingJSON)).LogEvent(map[string]any{"event": "x", "n": 1}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    var fields map[string]any
+    if err := json.Unmarshal(got[0].body, &fields); err != nil || fields["event"] != "x" || fields["n"] != 1.0 {
+        t.Fatalf("expected a JSON body, got %s (%v)", got[0].body, err)
+    }
+}
+
+func TestWithEncoding_MessagePack(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    l := New(srv.URL, WithEncoding(EncodingMessagePack))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if got[0].contentType != msgpackContentType || got[0].body[0] != 0x83 {
+        t.Fatalf("expected a MessagePack map, got %+v", got[0])
+    }
+
+    if err := l.LogEvents(context.Background(), []Event{{Name: "a"}, {Name: "b"}}); err != nil {
+        t.Fatalf("LogEvents: %v", err)
+    }
+    // Two stamped event maps, each starting with its "event" field.
+    body := got[1].body
+    if got[1].contentType != msgpackContentType || body[0] != 0x83 || bytes.Count(body, []byte{0x83, 0xa5, 'e', 'v', 'e', 'n', 't'}) != 2 {
+        t.Fatalf("expected a sequence of MessagePack maps, got %+v", got[1])
+    }
+}
+
+func TestWithEncoding_MessagePackFallback(t *testing.T) {
+    var got []capturedRequest
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        got = append(got, capturedRequest{contentType: r.Header.Get("Content-Type"), body: body})
+        if r.Header.Get("Content-Type") == msgpackContentType {
+            w.WriteHeader(http.StatusUnsupportedMediaType)
+        }
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithEncoding(EncodingMessagePack))
+    for i := 0; i < 2; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+            t.Fatalf("LogEvent: %v", err)
+        }
+    }
+    if len(got) != 3 || got[0].contentType != msgpackContentType || got[1].contentType != "application/json" || got[2].contentType != "application/json" {
+        t.Fatalf("expected one MessagePack attempt then JSON, got %+v", got)
+    }
+}
+
+func TestWithEncoding_MessagePackFallbackBatch(t *testing.T) {
+    var got []capturedRequest
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        got = append(got, capturedRequest{contentType: r.Header.Get("Content-Type"), body: body})
+        if r.Header.Get("Content-Type") == msgpackContentType {
+            w.WriteHeader(http.StatusUnsupportedMediaType)
+        }
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithEncoding(EncodingMessagePack))
+    if err := l.LogEvents(context.Background(), []Event{{Name: "a"}, {Name: "b"}}); err != nil {
+        t.Fatalf("LogEvents: %v", err)
+    }
+    if len(got) != 2 || got[1].contentType != "application/json" || !strings.Contains(string(got[1].body), `"event":"b"`) {
+        t.Fatalf("expected the batch to be resent as JSON, got %+v", got)
+    }
+
+    got = nil
+    l = New(srv.URL, WithEncoding(EncodingMessagePack))
+    sent := false
+    next := func() (Event, bool) {
+        if sent {
+            return Event{}, false
+        }
+        sent = true
+        return Event{Name: "a"}, true
+    }
+    if err := l.StreamEvents(context.Background(), next); err == nil || !strings.Contains(err.Error(), "stream not delivered") {
+        t.Fatalf("expected a clear error for the refused stream, got %v", err)
+    }
+    if len(got) != 1 || !l.msgpackRejected.Load() {
+        t.Fatalf("expected one refused stream and a switch to JSON, got %+v", got)
+    }
+}
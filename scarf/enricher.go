@@ -0,0 +1,48 @@
+package scarf
+
+import (
+    "context"
+)
+
+// Enricher contributes additional properties to every event. Enrichers run
+// before WithAllowedKeys, WithRedactedKeys and WithPIIScrubbing are applied,
+// so their output is filtered like the caller's properties, which take
+// precedence over enriched ones.
+//
+// Enrich is called on the sending goroutine and must respect ctx; it should
+// cache anything expensive to compute.
+type Enricher interface {
+    Enrich(ctx context.Context) map[string]any
+}
+
+// EnricherFunc adapts a function to the Enricher interface.
+type EnricherFunc func(ctx context.Context) map[string]any
+
+// Enrich calls f(ctx).
+func (f EnricherFunc) Enrich(ctx context.Context) map[string]any {
+    return f(ctx)
+}
+
+// WithEnrichers adds enrichers, applied in order.
+func WithEnrichers(enrichers ...Enricher) Option {
+    return func(s *ScarfEventLogger) {
+        for _, e := range enrichers {
+            if e != nil {
+                s.enrichers = append(s.enrichers, e)
+            }
+        }
+    }
+}
+
+// enrich merges enricher output into props without overriding existing keys.
+func (s *ScarfEventLogger) enrich(ctx context.Context, props map[string]any) {
+    for _, e := range s.enrichers {
+        var extra map[string]any
+        s.guard("enricher", func() { extra = e.Enrich(ctx) })
+        for k, v := range extra {
+            if _, ok := props[k]; !ok {
+                props[k] = v
+            }
+        }
+    }
+}
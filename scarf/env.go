@@ -0,0 +1,72 @@
+package scarf
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// NewFromEnv creates a logger configured entirely from environment variables,
+// so operators can set up telemetry without code changes:
+//
+//   - SCARF_ENDPOINT_URL (required): event collection endpoint
+//   - SCARF_API_KEY: sent as a bearer token
+//   - SCARF_TIMEOUT: default timeout, as a Go duration ("5s") or seconds ("2.5")
+//   - SCARF_SAMPLE_RATE: fraction of events to send, between 0 and 1
+//   - SCARF_VERBOSE (a log level), DO_NOT_TRACK, SCARF_NO_ANALYTICS: as for NewScarfEventLogger
+//
+// opts are applied after the environment and take precedence over it.
+func NewFromEnv(opts ...Option) (*ScarfEventLogger, error) {
+    cfg, err := configFromEnv()
+    if err != nil {
+        return nil, err
+    }
+    if strings.TrimSpace(cfg.EndpointURL) == "" {
+        return nil, errors.New("scarf: SCARF_ENDPOINT_URL is not set")
+    }
+    return newFromLayers([]configLayer{{SourceEnv, cfg}}, opts...)
+}
+
+// configFromEnv reads the SCARF_* variables understood by NewFromEnv.
+func configFromEnv() (Config, error) {
+    cfg := Config{
+        EndpointURL: strings.TrimSpace(os.Getenv("SCARF_ENDPOINT_URL")),
+        APIKey:      strings.TrimSpace(os.Getenv("SCARF_API_KEY")),
+    }
+    if v := strings.TrimSpace(os.Getenv("SCARF_TIMEOUT")); v != "" {
+        d, err := parseTimeout(v)
+        if err != nil {
+            return Config{}, fmt.Errorf("scarf: invalid SCARF_TIMEOUT %q: %w", v, err)
+        }
+        cfg.Timeout = Duration(d)
+    }
+    if v := strings.TrimSpace(os.Getenv("SCARF_SAMPLE_RATE")); v != "" {
+        rate, err := strconv.ParseFloat(v, 64)
+        if err != nil || rate < 0 || rate > 1 {
+            return Config{}, fmt.Errorf("scarf: invalid SCARF_SAMPLE_RATE %q: must be a number between 0 and 1", v)
+        }
+        cfg.SampleRate = &rate
+    }
+    return cfg, nil
+}
+
+// parseTimeout accepts a Go duration string or a plain number of seconds.
+func parseTimeout(v string) (time.Duration, error) {
+    if secs, err := strconv.ParseFloat(v, 64); err == nil {
+        if secs <= 0 {
+            return 0, errors.New("must be positive")
+        }
+        return time.Duration(secs * float64(time.Second)), nil
+    }
+    d, err := time.ParseDuration(v)
+    if err != nil {
+        return 0, err
+    }
+    if d <= 0 {
+        return 0, errors.New("must be positive")
+    }
+    return d, nil
+}
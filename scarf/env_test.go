@@ -0,0 +1,78 @@
+package scarf
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+            t.Fatalf("expected bearer token, got %q", got)
+        }
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+
+    t.Setenv("SCARF_ENDPOINT_URL", srv.URL)
+    t.Setenv("SCARF_API_KEY", "secret")
+    t.Setenv("SCARF_TIMEOUT", "1.5")
+    t.Setenv("SCARF_SAMPLE_RATE", "1")
+
+    l, err := NewFromEnv()
+    if err != nil {
+        t.Fatalf("NewFromEnv: %v", err)
+    }
+    if l.defaultTimeout != 1500*time.Millisecond {
+        t.Fatalf("expected 1.5s timeout, got %s", l.defaultTimeout)
+    }
+    if err := l.LogEvent(map[string]any{"event": "env"}); err != nil {
+        t.Fatalf("expected success, got %v", err)
+    }
+
+    // Code options take precedence over the environment.
+    l, err = NewFromEnv(WithTimeout(7 * time.Second))
+    if err != nil {
+        t.Fatalf("NewFromEnv: %v", err)
+    }
+    if l.defaultTimeout != 7*time.Second {
+        t.Fatalf("expected option to override env timeout, got %s", l.defaultTimeout)
+    }
+}
+
+func TestNewFromEnv_Errors(t *testing.T) {
+    t.Setenv("SCARF_ENDPOINT_URL", "")
+    if _, err := NewFromEnv(); err == nil {
+        t.Fatalf("expected error without SCARF_ENDPOINT_URL")
+    }
+
+    t.Setenv("SCARF_ENDPOINT_URL", "https://example.com")
+    for _, env := range []struct{ key, val string }{
+        {"SCARF_TIMEOUT", "soon"},
+        {"SCARF_TIMEOUT", "-1s"},
+        {"SCARF_SAMPLE_RATE", "2"},
+    } {
+        t.Run(env.key+"="+env.val, func(t *testing.T) {
+            t.Setenv(env.key, env.val)
+            if _, err := NewFromEnv(); err == nil {
+                t.Fatalf("expected error for %s=%s", env.key, env.val)
+            }
+        })
+    }
+}
+
+func TestParseTimeout(t *testing.T) {
+    for in, want := range map[string]time.Duration{
+        "5":     5 * time.Second,
+        "0.25":  250 * time.Millisecond,
+        "750ms": 750 * time.Millisecond,
+        "1m":    time.Minute,
+    } {
+        got, err := parseTimeout(in)
+        if err != nil || got != want {
+            t.Errorf("parseTimeout(%q) = %s, %v; want %s", in, got, err, want)
+        }
+    }
+}
@@ -0,0 +1,47 @@
+package scarf
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// LogError sends an "error" event for err with an error_type property
+// describing its type chain, e.g. "*fs.PathError > syscall.Errno", built by
+// following errors.Unwrap. The wrappers created by fmt.Errorf are left out of
+// the chain. The error message itself is never sent, so failure categories
+// can be counted without shipping paths or user data. Set props["event"] to
+// use a different event name. A nil err sends nothing and returns nil.
+func (s *ScarfEventLogger) LogError(err error, props map[string]any) error {
+    if s == nil {
+        return nil
+    }
+    if err == nil {
+        return nil
+    }
+    if refused, refuseErr := s.refuse(); refused {
+        return refuseErr
+    }
+    ev := eventFromProperties(copyProperties(props))
+    if ev.Name == "" {
+        ev.Name = "error"
+    }
+    ev.Properties["error_type"] = ErrorType(err)
+    return s.LogEventStruct(ev)
+}
+
+// ErrorType returns the normalized type chain of err used by LogError.
+func ErrorType(err error) string {
+    var types []string
+    for ; err != nil; err = errors.Unwrap(err) {
+        t := fmt.Sprintf("%T", err)
+        if t == "*fmt.wrapError" || t == "*fmt.wrapErrors" {
+            continue
+        }
+        if n := len(types); n > 0 && types[n-1] == t {
+            continue
+        }
+        types = append(types, t)
+    }
+    return strings.Join(types, " > ")
+}
@@ -0,0 +1,48 @@
+package scarf
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "testing"
+)
+
+func TestErrorType(t *testing.T) {
+    _, openErr := os.Open("/definitely/not/here")
+    cases := []struct {
+        err  error
+        want string
+    }{
+        {errors.New("boom"), "*errors.errorString"},
+        {fmt.Errorf("loading config: %w", openErr), "*fs.PathError > syscall.Errno"},
+        {fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", ErrDisabled)), "*errors.errorString"},
+        {fmt.Errorf("not wrapped: %v", openErr), "*errors.errorString"},
+    }
+    for _, c := range cases {
+        if got := ErrorType(c.err); got != c.want {
+            t.Errorf("ErrorType(%v) = %q, want %q", c.err, got, c.want)
+        }
+    }
+}
+
+func TestLogError(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+    if err := l.LogError(nil, nil); err != nil || len(got) != 0 {
+        t.Fatalf("expected nil error to send nothing, got %v, %d events", err, len(got))
+    }
+
+    _, openErr := os.Open("/secret/path")
+    if err := l.LogError(fmt.Errorf("open: %w", openErr), map[string]any{"command": "sync"}); err != nil {
+        t.Fatalf("LogError: %v", err)
+    }
+    ev := got[0]
+    if ev.Name != "error" || ev.Properties["command"] != "sync" || ev.Properties["error_type"] != "*fs.PathError > syscall.Errno" {
+        t.Fatalf("unexpected error event: %+v", ev)
+    }
+    for _, v := range ev.Properties {
+        if v == openErr.Error() {
+            t.Fatalf("error message leaked into event: %+v", ev.Properties)
+        }
+    }
+}
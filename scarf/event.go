@@ -0,0 +1,84 @@
+package scarf
+
+import (
+    "context"
+    "net/url"
+    "time"
+)
+
+// Event is a single telemetry event.
+//
+// Name, Timestamp and ID are sent as the reserved "event", "timestamp" and
+// "event_id" parameters respectively, taking precedence over properties of
+// the same name. Zero-valued fields are omitted.
+type Event struct {
+    Name       string
+    Timestamp  time.Time
+    Properties map[string]any
+    ID         string
+}
+
+// eventFromProperties wraps a raw property map, lifting a string "event"
+// property into Name.
+func eventFromProperties(properties map[string]any) Event {
+    ev := Event{Properties: properties}
+    if name, ok := properties["event"].(string); ok {
+        ev.Name = name
+    }
+    return ev
+}
+
+// queryValues encodes the event as URL query parameters.
+func (e Event) queryValues() url.Values {
+    q := url.Values{}
+    for k, v := range e.Properties {
+        q.Set(k, stringifyParam(v))
+    }
+    if e.Name != "" {
+        q.Set("event", e.Name)
+    }
+    if !e.Timestamp.IsZero() {
+        q.Set("timestamp", e.Timestamp.UTC().Format(time.RFC3339Nano))
+    }
+    if e.ID != "" {
+        q.Set("event_id", e.ID)
+    }
+    return q
+}
+
+// jsonBody encodes the event as a JSON object, with the same fields as
+// queryValues but keeping property values' JSON types.
+func (e Event) jsonBody() ([]byte, error) {
+    fields := make(map[string]any, len(e.Properties)+3)
+    for k, v := range e.Properties {
+        fields[k] = CanonicalValue(v)
+    }
+    if e.Name != "" {
+        fields["event"] = e.Name
+    }
+    if !e.Timestamp.IsZero() {
+        fields["timestamp"] = e.Timestamp.UTC().Format(time.RFC3339Nano)
+    }
+    if e.ID != "" {
+        fields["event_id"] = e.ID
+    }
+    return marshalObject(fields)
+}
+
+// LogEventStruct sends a typed event using the logger's default timeout.
+// Returns nil if the request completed successfully with a 2xx status code.
+func (s *ScarfEventLogger) LogEventStruct(ev Event) error {
+    if s == nil {
+        return nil
+    }
+    return s.logEventInternal(context.Background(), ev, s.defaultTimeout)
+}
+
+// copyProperties returns a shallow copy of props; nil yields an empty map.
+func copyProperties(props map[string]any) map[string]any {
+    out := make(map[string]any, len(props))
+    for k, v := range props {
+        out[k] = v
+    }
+    return out
+}
@@ -1,15 +1,16 @@
 package scarf
 
 import (
-    "encoding/json"
+    "context"
     "errors"
     "fmt"
-    "log"
     "net/http"
     "net/url"
     "os"
     "runtime"
     "strings"
+    "sync"
+    "sync/atomic"
     "time"
 )
 
@@ -20,148 +21,511 @@ const (
 // sdkVersion is the SDK version embedded in the User-Agent.
 // It can be overridden at build time via:
 //   go build -ldflags "-X github.com/scarf-sh/scarf-go/scarf.sdkVersion=v1.2.3"
-var sdkVersion = "0.1.1"
+var sdkVersion = "0.2.0"
 
 // ScarfEventLogger provides a simple API to send telemetry events to a Scarf endpoint.
+//
+// A ScarfEventLogger is safe for concurrent use once New returns: the Log
+// methods, Count and Gauge, Flush, Close, Stats and the setters
+// (SetEnabled, SetConsent, SetDefaultProperty) may be called from any number
+// of goroutines at once. Options only take effect during New. Property maps
+// are copied before a Log method returns, so callers may reuse them
+// afterwards but must not modify them during the call.
 type ScarfEventLogger struct {
     endpointURL    string
     defaultTimeout time.Duration
-    disabled       bool
-    verbose        bool
+    envDisabled    string       // why the environment turns analytics off, if it does
+    configEnabled  setting      // Config.Enabled / Config.Disabled
+    codeEnabled    atomic.Int32 // a setting, from WithEnabled or SetEnabled
+    defaults       defaultProperties
+    endpoints      endpointCache
+    logLevel       LogLevel
     httpClient     *http.Client
-    logger         *log.Logger
+    logger         Logger
+    observers      []Observer
+    sampling       bool
+    sampleRate     float64
+    sampleKey      func(Event) string
+    newID          func() string
+    gzipThreshold  int
+    breaker        *circuitBreaker
+    backoff        backoffGate
+    apiKey         string
+    dryRun         bool
+    dryRunLog      dryRunLog
+    transport      Transport
+    closed         atomic.Bool
+    closeOnce      sync.Once
+    done           chan struct{}
+    inflight       inflightTracker
+    noop           bool
+    appName        string
+    appVersion     string
+    uaProduct      string
+    uaExtras       string
+    uaRejected     []string
+    strict         bool
+    clock          Clock
+    optSource      SettingSource
+    sources        map[string]SettingSource
+    stateDir       string
+    policy         Policy
+    consent        consentStore
+    installID      installIDCache
+    firstRun       firstRunCache
+
+    attachInstallID bool
+    allowedKeys     map[string]bool
+    redactedKeys    map[string]bool
+    scrubPII        bool
+    ciMode          CIMode
+    ciProvider      string
+    enrichers       []Enricher
+    serverless      bool
+    metrics         aggregator
+    metricsInterval time.Duration
+    metricsOnce     sync.Once
+    swallowPanics   bool
+    fallbackURLs    []string
+    routes          []Route
+    proxy           func(*http.Request) (*url.URL, error)
+    dns             *dnsCache
+    batchInterval   time.Duration
+    batchMax        int
+    highPriority    map[string]bool
+    queue           eventQueue
+    session         atomic.Pointer[session]
+    tls             tlsOptions
+    signingKey      []byte
+    keyNorm         *KeyNormalization
+    maxPayload      int
+    oversize        OversizeStrategy
+    batchFormat     BatchFormat
+    encoding        Encoding
+    msgpackRejected atomic.Bool
+    traceExtract    TraceExtractor
+    traceparent     bool
+    stats           statsCounters
+    maxPending      int
 }
 
-// ErrDisabled is returned when analytics are disabled via environment settings.
-var ErrDisabled = errors.New("scarf: analytics disabled by environment")
+// ErrDisabled is returned when analytics are disabled, by code, a config
+// file, the environment or the build; DisabledReason says which.
+var ErrDisabled = errors.New("scarf: analytics disabled")
 
 // NewScarfEventLogger creates a new logger with the required endpoint URL.
 //
 // Optionally pass a timeout to override the default (3 seconds).
 //   logger := NewScarfEventLogger("https://your-endpoint", 5*time.Second)
+//
+// Use New for access to the full set of options.
 func NewScarfEventLogger(endpointURL string, timeout ...time.Duration) *ScarfEventLogger {
-    t := defaultTimeout
-    if len(timeout) > 0 && timeout[0] > 0 {
-        t = timeout[0]
+    var opts []Option
+    if len(timeout) > 0 {
+        opts = append(opts, WithTimeout(timeout[0]))
     }
+    return New(endpointURL, opts...)
+}
 
-    verbose := envBool("SCARF_VERBOSE")
-    disabled := envBool("DO_NOT_TRACK") || envBool("SCARF_NO_ANALYTICS")
+// New creates a new logger with the required endpoint URL, configured by opts.
+//   logger := New("https://your-endpoint", WithTimeout(5*time.Second))
+func New(endpointURL string, opts ...Option) *ScarfEventLogger {
+    // An unparsable SCARF_VERBOSE leaves logging off rather than failing New.
+    logLevel, logLevelErr := ParseLogLevel(os.Getenv("SCARF_VERBOSE"))
 
-    l := log.New(os.Stderr, "[scarf] ", log.LstdFlags)
+    l := newDefaultLogger()
 
-    return &ScarfEventLogger{
+    s := &ScarfEventLogger{
         endpointURL:    endpointURL,
-        defaultTimeout: t,
-        disabled:       disabled,
-        verbose:        verbose,
-        httpClient: &http.Client{
-            Timeout: t,
-        },
-        logger: l,
+        defaultTimeout: defaultTimeout,
+        envDisabled:    envDisabledReason(),
+        logLevel:       logLevel,
+        logger:         l,
+        clock:          systemClock{},
+        gzipThreshold:  -1,
+        maxPayload:     defaultMaxPayload,
+        oversize:       OversizeBody,
+        done:           make(chan struct{}),
+        optSource:      SourceCode,
     }
-}
-
-// Enabled reports whether analytics are enabled.
-func (s *ScarfEventLogger) Enabled() bool {
-    return !s.disabled
+    if logLevelErr == nil && os.Getenv("SCARF_VERBOSE") != "" {
+        s.sources = map[string]SettingSource{"log_level": SourceEnv}
+    }
+    if endpointURL != "" {
+        s.mark("endpoint_url")
+    }
+    for _, opt := range opts {
+        if opt != nil {
+            opt(s)
+        }
+    }
+    s.applyCIMode()
+    s.consent.path, s.consent.legacy = s.consentPaths()
+    if buildDisabled {
+        s.noop = true
+        return s
+    }
+    if len(s.uaRejected) > 0 {
+        s.warn("ignoring invalid User-Agent extras", "keys", strings.Join(s.uaRejected, ","))
+    }
+    if reason := s.DisabledReason(); reason != "" {
+        s.info("analytics disabled", "reason", reason, "decided_by", s.EnabledBy().String())
+    }
+    if s.httpClient == nil {
+        s.httpClient = &http.Client{Transport: s.newTransport()}
+        // Only warm the cache if events will actually be sent: a lookup is
+        // network activity too.
+        if s.dns != nil && s.dns.ttl > 0 && s.wouldSend() {
+            go func() {
+                ctx, cancel := context.WithTimeout(context.Background(), s.defaultTimeout)
+                defer cancel()
+                s.dns.prefetch(ctx, append([]string{s.endpointURL}, s.fallbackURLs...))
+            }()
+        }
+    }
+    return s
 }
 
 // LogEvent sends an event using the logger's default timeout.
 // Returns nil if the request completed successfully with a 2xx status code.
 func (s *ScarfEventLogger) LogEvent(properties map[string]any) error {
-    return s.logEventInternal(properties, s.defaultTimeout)
+    if s == nil {
+        return nil
+    }
+    return s.logEventInternal(context.Background(), eventFromProperties(properties), s.defaultTimeout)
+}
+
+// LogEventContext sends an event using the logger's default timeout, aborting
+// early if ctx is canceled.
+// Returns nil if the request completed successfully with a 2xx status code.
+func (s *ScarfEventLogger) LogEventContext(ctx context.Context, properties map[string]any) error {
+    if s == nil {
+        return nil
+    }
+    return s.logEventInternal(ctx, eventFromProperties(properties), s.defaultTimeout)
 }
 
 // LogEventWithTimeout sends an event using a custom timeout for this call.
 // Returns nil if the request completed successfully with a 2xx status code.
 func (s *ScarfEventLogger) LogEventWithTimeout(properties map[string]any, timeout time.Duration) error {
+    if s == nil {
+        return nil
+    }
     if timeout <= 0 {
         timeout = s.defaultTimeout
     }
-    return s.logEventInternal(properties, timeout)
+    return s.logEventInternal(context.Background(), eventFromProperties(properties), timeout)
 }
 
-func (s *ScarfEventLogger) logEventInternal(properties map[string]any, timeout time.Duration) error {
-    if s.disabled {
-        if s.verbose {
-            s.logger.Println("analytics disabled via env; not sending event")
+func (s *ScarfEventLogger) logEventInternal(ctx context.Context, ev Event, timeout time.Duration) error {
+    return s.process(ctx, ev, timeout, s.dispatch)
+}
+
+// process runs ev through the event pipeline (gating, filtering, enrichment,
+// stamping and sampling) and hands the result to send.
+func (s *ScarfEventLogger) process(ctx context.Context, ev Event, timeout time.Duration, send func(context.Context, Event, time.Duration) error) error {
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    if err := s.startSend(); err != nil {
+        return err
+    }
+    defer s.inflight.done()
+
+    ev, ok := s.prepare(ctx, ev)
+    if !ok {
+        return nil
+    }
+    if err := s.checkStrict(ev); err != nil {
+        return err
+    }
+    return s.deliver(ctx, ev, timeout, send)
+}
+
+// deliver hands an event that has been through prepare to send, unless it
+// would run past the invocation deadline.
+func (s *ScarfEventLogger) deliver(ctx context.Context, ev Event, timeout time.Duration, send func(context.Context, Event, time.Duration) error) error {
+    if s.serverless {
+        var err error
+        if timeout, err = serverlessBudget(ctx, timeout); err != nil {
+            s.warn("skipping event near invocation deadline", "error", err)
+            s.notifyDrop(DropDeadline)
+            return err
+        }
+    }
+
+    return send(ctx, ev, timeout)
+}
+
+// refuse reports whether events are refused outright (a no-op or closed
+// logger, analytics disabled or no consent) and, if so, the error to return.
+// Entry points that do work to build an event call it first, so a refused
+// event costs nothing.
+func (s *ScarfEventLogger) refuse() (bool, error) {
+    if buildDisabled || s == nil || s.noop {
+        return true, nil
+    }
+    if s.closed.Load() {
+        s.debug("logger closed; not sending event")
+        s.notifyDrop(DropClosed)
+        return true, ErrClosed
+    }
+    if err := s.checkGates(); err != nil {
+        return true, err
+    }
+    return false, nil
+}
+
+// wouldSend is refuse without logging or counting a drop, for callers that
+// only buffer data for a later send.
+func (s *ScarfEventLogger) wouldSend() bool {
+    return s.Enabled() && !s.closed.Load() && s.checkConsent() == nil
+}
+
+// checkGates reports whether analytics are disabled or lack consent.
+func (s *ScarfEventLogger) checkGates() error {
+    if !s.Enabled() {
+        if s.logLevel >= LogDebug {
+            s.debug("analytics disabled; not sending event", "reason", s.DisabledReason())
         }
+        s.notifyDrop(DropDisabled)
         return ErrDisabled
     }
 
-    if strings.TrimSpace(s.endpointURL) == "" {
-        if s.verbose {
-            s.logger.Println("no endpoint URL configured; aborting")
+    if err := s.checkConsent(); err != nil {
+        if s.logLevel >= LogDebug {
+            s.debug("telemetry consent not granted; not sending event", "reason", s.DisabledReason())
         }
-        return errors.New("scarf: endpoint URL is required")
+        s.notifyDrop(DropConsent)
+        return err
     }
+    return nil
+}
 
-    if properties == nil {
-        properties = map[string]any{}
+// prepare filters, enriches and stamps ev, reporting false if it is sampled
+// out.
+func (s *ScarfEventLogger) prepare(ctx context.Context, ev Event) (Event, bool) {
+    // Random sampling doesn't depend on the event, so decide it before
+    // paying for copying and enrichment.
+    if s.sampleKey == nil && !s.sampledIn(ev) {
+        s.debug("event sampled out; not sending")
+        s.notifyDrop(DropSampled)
+        return ev, false
     }
 
-    // Build URL with query parameters from properties
-    u, err := url.Parse(s.endpointURL)
-    if err != nil {
-        if s.verbose {
-            s.logger.Printf("invalid endpoint URL: %v\n", err)
+    // Copy properties so the SDK's additions never leak into the caller's map.
+    if s.keyNorm != nil {
+        ev.Properties = s.keyNorm.normalizeProperties(s.withDefaults(ev.Properties))
+    } else {
+        ev.Properties = s.copyWithDefaults(ev.Properties)
+    }
+    // Enricher output is the application's too, so it is filtered and
+    // scrubbed like the caller's properties.
+    s.enrich(ctx, ev.Properties)
+    s.filterProperties(ev.Properties)
+    if s.scrubPII {
+        scrubProperties(ev.Properties)
+    }
+    s.tagCI(ev.Properties)
+    s.tagTrace(ctx, ev.Properties)
+    s.tagSession(ev.Properties)
+    if s.attachInstallID {
+        if _, ok := ev.Properties["install_id"]; !ok {
+            if id, err := s.InstallID(); err == nil {
+                ev.Properties["install_id"] = id
+            } else {
+                s.warn("install ID unavailable", "error", err)
+            }
         }
-        return fmt.Errorf("scarf: invalid endpoint URL: %w", err)
     }
+    ev = s.stampEvent(ev)
+
+    if s.sampleKey != nil && !s.sampledIn(ev) {
+        s.debug("event sampled out; not sending")
+        s.notifyDrop(DropSampled)
+        return ev, false
+    }
+    if s.serverless {
+        ev.Properties["cold_start"] = coldStart.CompareAndSwap(false, true)
+    }
+    return ev, true
+}
+
+// dispatch delivers ev through the custom transport, if any, or to the
+// endpoint URL.
+func (s *ScarfEventLogger) dispatch(ctx context.Context, ev Event, timeout time.Duration) error {
+    if buildDisabled {
+        return ErrDisabled
+    }
+    if r, ok := s.route(ev.Name); ok {
+        return s.sendRoute(ctx, r, ev, timeout)
+    }
+    if s.transport != nil {
+        return s.sendTransport(ctx, s.transport, ev, timeout)
+    }
+    if strings.TrimSpace(s.endpointURL) == "" {
+        s.error("no endpoint URL configured; aborting")
+        return ErrNoEndpoint
+    }
+    return s.sendHTTP(ctx, ev, timeout)
+}
 
-    q := u.Query()
-    for k, v := range properties {
-        str := stringifyParam(v)
-        q.Set(k, str)
+// admit reports whether a delivery attempt may be made now, given any
+// endpoint-requested backoff and the circuit breaker.
+func (s *ScarfEventLogger) admit() error {
+    if until := s.backoff.pausedUntil(s.clock.Now()); !until.IsZero() {
+        s.debug("backing off; not sending event", "until", until.Format(time.RFC3339))
+        s.stats.rejected.Add(1)
+        return rateLimitedError(until)
     }
-    u.RawQuery = q.Encode()
 
-    if s.verbose {
-        s.logger.Printf("payload (query): %s\n", u.RawQuery)
+    if !s.breaker.allow(s.clock.Now()) {
+        s.debug("circuit breaker open; not sending event")
+        s.stats.rejected.Add(1)
+        return ErrCircuitOpen
     }
+    return nil
+}
 
-    req, err := http.NewRequest(http.MethodPost, u.String(), nil)
+// sendTransport delivers ev through custom Transport t.
+func (s *ScarfEventLogger) sendTransport(ctx context.Context, t Transport, ev Event, timeout time.Duration) error {
+    if s.dryRun {
+        return s.recordDryRunEvent(ev)
+    }
+    if err := s.admit(); err != nil {
+        return err
+    }
+
+    parent := ctx
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    s.debug("sending event via transport", "event", ev.Name, "timeout", timeout)
+
+    start := time.Now()
+    var err error
+    if v := s.guard("transport", func() { err = t.Send(ctx, ev) }); v != nil {
+        err = errPanic("transport", v)
+    }
+    latency := time.Since(start)
+    s.breaker.record(err != nil, s.clock.Now())
     if err != nil {
-        if s.verbose {
-            s.logger.Printf("failed to build request: %v\n", err)
+        s.warn("transport failed", "error", err)
+        if cause := deadlineCause(parent, err); cause != nil {
+            err = fmt.Errorf("%w: %w", cause, err)
+        } else {
+            err = fmt.Errorf("scarf: transport failed: %w", err)
         }
-        return fmt.Errorf("scarf: build request: %w", err)
     }
-    req.Header.Set("User-Agent", buildUserAgent())
+    s.notifyObservers(Delivery{Latency: latency, Err: err})
+    return err
+}
+
+// sendHTTP delivers ev to the endpoint URL, moving on to each fallback
+// endpoint in turn while attempts fail with network errors or 5xx responses.
+func (s *ScarfEventLogger) sendHTTP(ctx context.Context, ev Event, timeout time.Duration) error {
+    return s.failover(ctx, func(endpoint string) (int, error) {
+        return s.sendHTTPTo(ctx, endpoint, ev, timeout)
+    })
+}
+
+// sendHTTPTo delivers ev to endpoint with properties encoded as query
+// parameters or, depending on the encoding and payload size, as a body,
+// returning the response status code, if any.
+func (s *ScarfEventLogger) sendHTTPTo(ctx context.Context, endpoint string, ev Event, timeout time.Duration) (int, error) {
+    rawURL, body, contentType, err := s.encodeHTTP(endpoint, ev)
+    if err != nil {
+        s.error("failed to encode event", "error", err)
+        return 0, err
+    }
+
+    if body != nil {
+        s.debug("payload (body)", "content_type", contentType, "bytes", len(body))
+    } else {
+        s.debug("payload (query)", "url", s.logURL(rawURL))
+    }
+
+    req, err := s.newRequest(http.MethodPost, rawURL, body, contentType)
+    if err != nil {
+        s.error("failed to build request", "error", err)
+        return 0, fmt.Errorf("scarf: build request: %w", err)
+    }
+    status, err := s.roundTrip(ctx, req, timeout)
+    if s.rejectedMsgpack(status, contentType) {
+        s.stats.retries.Add(1)
+        return s.sendHTTPTo(ctx, endpoint, ev, timeout)
+    }
+    return status, err
+}
+
+// roundTrip sends req, honoring dry-run mode, backoff and the circuit
+// breaker, and notifies observers of the outcome. It returns the response
+// status code, or 0 if no response was received.
+func (s *ScarfEventLogger) roundTrip(ctx context.Context, req *http.Request, timeout time.Duration) (int, error) {
+    if buildDisabled {
+        return 0, ErrDisabled
+    }
+    if s.dryRun {
+        req = req.WithContext(ctx)
+        s.setTraceparent(ctx, req)
+        return 0, s.recordDryRun(req)
+    }
 
-    // Use per-call timeout without mutating the shared client.
-    client := *s.httpClient
-    client.Timeout = timeout
+    // The timeout is applied per request through the context, so every send
+    // shares one client and its pool of kept-alive connections. cancel runs
+    // after the body has been drained below. A shorter deadline on the
+    // caller's context still applies.
+    parent := ctx
+    if timeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, timeout)
+        defer cancel()
+    }
+    req = req.WithContext(ctx)
+    s.setTraceparent(ctx, req)
 
-    if s.verbose {
-        s.logger.Printf("sending event to %s (timeout=%s)\n", req.URL.String(), timeout)
+    if s.tls.err != nil {
+        s.error("TLS configuration invalid", "error", s.tls.err)
+        return 0, s.tls.err
     }
 
-    resp, err := client.Do(req)
+    if err := s.admit(); err != nil {
+        return 0, err
+    }
+
+    s.debug("sending event", "url", s.logURL(req.URL.String()), "timeout", timeout)
+
+    start := time.Now()
+    resp, err := s.httpClient.Do(req)
+    latency := time.Since(start)
     if err != nil {
-        if s.verbose {
-            s.logger.Printf("request failed: %v\n", err)
-        }
-        return fmt.Errorf("scarf: request failed: %w", err)
+        s.breaker.record(true, s.clock.Now())
+        err = newNetworkError(parent, req.URL, err)
+        s.warn("request failed", "error", err)
+        s.notifyObservers(Delivery{Latency: latency, Err: err})
+        return 0, err
     }
     defer func() {
         // Read and close the body defensively to allow connection reuse.
         // We don't need the response body content, so just ensure closure.
         _ = drainAndClose(resp)
     }()
+    s.breaker.record(resp.StatusCode >= 500, s.clock.Now())
 
     if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-        if s.verbose {
-            s.logger.Printf("event logged successfully: %s\n", resp.Status)
-        }
-        return nil
+        s.debug("event logged successfully", "status", resp.Status)
+        s.notifyObservers(Delivery{StatusCode: resp.StatusCode, Latency: latency})
+        return resp.StatusCode, nil
     }
 
-    if s.verbose {
-        s.logger.Printf("non-success status: %s\n", resp.Status)
+    s.warn("non-success status", "status", resp.Status)
+    epErr := newEndpointError(resp)
+    if delay, ok := rateLimitDelay(resp, s.clock.Now()); ok {
+        epErr.RetryAfter = s.backoff.pause(s.clock.Now(), delay)
+        s.warn("endpoint requested backoff", "until", epErr.RetryAfter.Format(time.RFC3339))
     }
-    return fmt.Errorf("scarf: non-success status: %s", resp.Status)
+    s.notifyObservers(Delivery{StatusCode: resp.StatusCode, Latency: latency, Err: epErr})
+    return resp.StatusCode, epErr
 }
 
 func envBool(key string) bool {
@@ -189,45 +553,18 @@ func drainAndClose(resp *http.Response) error {
 // Validate configuration at runtime if needed.
 func (s *ScarfEventLogger) validate() error {
     if strings.TrimSpace(s.endpointURL) == "" {
-        return errors.New("scarf: endpoint URL is required")
+        return ErrNoEndpoint
     }
     return nil
 }
 
-func buildUserAgent() string {
-    osName := runtime.GOOS
-    if osName == "darwin" {
-        osName = "macOS"
-    }
+func buildUserAgent(extras string) string {
+    osName := platformName()
     v := sdkVersion
     if strings.TrimSpace(v) == "" {
         v = "dev"
     }
     // Example: scarf-go/v1.2.3 (platform=macOS; arch=arm64; go=1.22.3)
     goVer := strings.TrimPrefix(runtime.Version(), "go")
-    return fmt.Sprintf("scarf-go/%s (platform=%s; arch=%s; go=%s)", v, osName, runtime.GOARCH, goVer)
-}
-
-// stringifyParam converts a property value into a string suitable for URL query parameters.
-// Simple types use fmt.Sprint; complex types are JSON-encoded.
-func stringifyParam(v any) string {
-    switch vv := v.(type) {
-    case string:
-        return vv
-    case fmt.Stringer:
-        return vv.String()
-    default:
-        // Try to JSON-encode complex types for stability.
-        b, err := json.Marshal(v)
-        if err == nil {
-            // Use the JSON as-is for objects/arrays, but avoid quoting simple scalars twice.
-            // If result is a quoted string, trim quotes for more natural query values.
-            s := string(b)
-            if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
-                return s[1 : len(s)-1]
-            }
-            return s
-        }
-        return fmt.Sprint(v)
-    }
+    return fmt.Sprintf("scarf-go/%s (platform=%s; arch=%s; go=%s%s)", v, osName, runtime.GOARCH, goVer, extras)
 }
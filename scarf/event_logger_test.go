@@ -1,12 +1,16 @@
 package scarf
 
 import (
+    "context"
+    "errors"
     "fmt"
     "net/http"
     "net/http/httptest"
     "os"
+    "path/filepath"
     "encoding/json"
     "strings"
+    "sync"
     "testing"
     "time"
 )
@@ -22,6 +26,43 @@ func TestDisabledViaEnv(t *testing.T) {
     }
 }
 
+// TestConcurrentUse exercises the public API from many goroutines at once;
+// it is meant to be run with -race.
+func TestConcurrentUse(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+    l := New(srv.URL, WithConsentFile(filepath.Join(t.TempDir(), "consent.json")), WithSampleRate(0.9), WithMetricsInterval(time.Millisecond))
+
+    var wg sync.WaitGroup
+    run := func(fn func(i int)) {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < 20; i++ {
+                fn(i)
+            }
+        }()
+    }
+    props := map[string]any{"event": "shared", "n": 1}
+    for i := 0; i < 4; i++ {
+        run(func(int) { _ = l.LogEvent(props) })
+    }
+    run(func(i int) { _ = l.LogEvents(context.Background(), []Event{{Name: "batch"}, {Name: "batch"}}) })
+    run(func(i int) { l.Count("c", 1); l.Gauge("g", float64(i)) })
+    run(func(i int) { l.SetEnabled(i%3 != 0) })
+    run(func(i int) { _ = l.SetConsent(i%4 != 0) })
+    run(func(i int) { l.SetDefaultProperty("i", i) })
+    run(func(int) { _, _ = l.Stats(), l.Pending(); _ = l.DisabledReason() })
+    run(func(int) { _ = l.Flush(context.Background()) })
+    wg.Wait()
+
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+}
+
 func TestLogEvent_Success(t *testing.T) {
     srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         if r.Method != http.MethodPost {
@@ -122,3 +163,58 @@ func TestLogEvent_NonSuccessStatus(t *testing.T) {
         t.Fatalf("expected error on non-2xx status")
     }
 }
+
+func TestDroppedEventsDoNotAllocate(t *testing.T) {
+    props := map[string]any{"event": "x", "version": "1.2.3"}
+    sampled := New("https://example.com", WithSampleRate(0))
+    if n := testing.AllocsPerRun(100, func() { _ = sampled.LogEvent(props) }); n != 0 {
+        t.Errorf("sampled out: %v allocations per event, want 0", n)
+    }
+    // Consent file paths are resolved once, not per event.
+    t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+    named := New("https://example.com", WithSampleRate(0), WithAppName("mytool"))
+    if n := testing.AllocsPerRun(100, func() { _ = named.LogEvent(props) }); n != 0 {
+        t.Errorf("sampled out with an app name: %v allocations per event, want 0", n)
+    }
+
+    // Every entry point must bail out before building the event.
+    l := New("https://example.com", WithEnabled(false))
+    errBoom := errors.New("boom")
+    events := []Event{{Name: "x", Properties: props}}
+    payload := struct {
+        Target string `json:"target"`
+    }{"linux"}
+    for name, fn := range map[string]func(){
+        "LogEvent":        func() { _ = l.LogEvent(props) },
+        "LogEventStruct":  func() { _ = l.LogEventStruct(events[0]) },
+        "LogEvents":       func() { _ = l.LogEvents(context.Background(), events) },
+        "LogError":        func() { _ = l.LogError(errBoom, props) },
+        "LogStartupEvent": func() { _ = l.LogStartupEvent(props) },
+        "TrackPixel":      func() { _ = l.TrackPixel("px", props) },
+        "Emit":            func() { _ = Emit(l, "build", payload) },
+        "Count":           func() { l.Count("c", 1) },
+        "Gauge":           func() { l.Gauge("g", 1) },
+    } {
+        if n := testing.AllocsPerRun(100, fn); n != 0 {
+            t.Errorf("%s: %v allocations when disabled, want 0", name, n)
+        }
+    }
+}
+
+func BenchmarkLogEvent_Disabled(b *testing.B) {
+    l := New("https://example.com", WithEnabled(false))
+    props := map[string]any{"event": "x", "version": "1.2.3", "os": "linux"}
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        _ = l.LogEvent(props)
+    }
+}
+
+func BenchmarkLogEvent_SampledOut(b *testing.B) {
+    l := New("https://example.com", WithSampleRate(0))
+    props := map[string]any{"event": "x", "version": "1.2.3", "os": "linux"}
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        _ = l.LogEvent(props)
+    }
+}
@@ -0,0 +1,50 @@
+package scarf
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestLogEventStruct(t *testing.T) {
+    ts := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        if got := q.Get("event"); got != "install" {
+            t.Fatalf("expected event=install, got %q", got)
+        }
+        if got := q.Get("timestamp"); got != "2024-05-01T10:30:00Z" {
+            t.Fatalf("expected UTC RFC3339 timestamp, got %q", got)
+        }
+        if got := q.Get("event_id"); got != "abc" {
+            t.Fatalf("expected event_id=abc, got %q", got)
+        }
+        if got := q.Get("version"); got != "1.0.0" {
+            t.Fatalf("expected version=1.0.0, got %q", got)
+        }
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL)
+    err := l.LogEventStruct(Event{
+        Name:       "install",
+        Timestamp:  ts,
+        ID:         "abc",
+        Properties: map[string]any{"event": "ignored", "version": "1.0.0"},
+    })
+    if err != nil {
+        t.Fatalf("expected success, got %v", err)
+    }
+}
+
+func TestEventFromProperties(t *testing.T) {
+    ev := eventFromProperties(map[string]any{"event": "startup", "n": 1})
+    if ev.Name != "startup" {
+        t.Fatalf("expected Name=startup, got %q", ev.Name)
+    }
+    if ev := eventFromProperties(map[string]any{"event": 5}); ev.Name != "" {
+        t.Fatalf("non-string event property should not become Name, got %q", ev.Name)
+    }
+}
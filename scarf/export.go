@@ -0,0 +1,301 @@
+package scarf
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "hash/crc32"
+    "io"
+    "strconv"
+    "time"
+)
+
+// ExportVersion is the version of the export format written by
+// ExportEvents, EventWriter and FileTransport.
+//
+// An export is newline-delimited JSON. It starts with a header line naming
+// the format and version, followed by one record per event carrying the
+// event object (with the same fields as a body-mode event) and its CRC-32C
+// checksum, and ends with a trailer line counting the records:
+//
+//   {"format":"scarf-events","version":1}
+//   {"crc32c":"1c291ca3","data":{"event":"install","event_id":"…","timestamp":"…"}}
+//   {"count":1}
+//
+// Exports may be concatenated. The trailer is optional, so a file that is
+// still being appended to, such as a FileTransport spool, is also valid.
+const ExportVersion = 1
+
+// exportFormat names the format in the header line.
+const exportFormat = "scarf-events"
+
+var (
+    // ErrExportVersion is returned when reading an export written in a
+    // newer version of the format than ExportVersion.
+    ErrExportVersion = errors.New("scarf: unsupported export version")
+    // ErrExportChecksum is reported for a record whose checksum doesn't
+    // match its event, or a trailer whose count doesn't match the records
+    // before it.
+    ErrExportChecksum = errors.New("scarf: export checksum mismatch")
+)
+
+// ExportLineError is returned by EventReader for a line of an export that
+// can't be read. Reading may continue with the next line.
+type ExportLineError struct {
+    // Line is the 1-based line number.
+    Line int
+    Err  error
+}
+
+func (e *ExportLineError) Error() string {
+    return fmt.Sprintf("scarf: export line %d: %v", e.Line, e.Err)
+}
+
+func (e *ExportLineError) Unwrap() error {
+    return e.Err
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// checksum returns the CRC-32C of data as 8 hex digits.
+func checksum(data []byte) string {
+    return fmt.Sprintf("%08x", crc32.Checksum(data, castagnoli))
+}
+
+// exportHeaderPrefix starts every header line.
+const exportHeaderPrefix = `{"format":"` + exportFormat + `"`
+
+// isExportHeader reports whether line is a header line.
+func isExportHeader(line []byte) bool {
+    return bytes.HasPrefix(line, []byte(exportHeaderPrefix))
+}
+
+// exportHeader returns the header line of the current format version.
+func exportHeader() []byte {
+    return []byte(exportHeaderPrefix + `,"version":` + strconv.Itoa(ExportVersion) + "}\n")
+}
+
+// appendExportRecord appends the record line for ev to b.
+func appendExportRecord(b []byte, ev Event) ([]byte, error) {
+    data, err := ev.jsonBody()
+    if err != nil {
+        return b, err
+    }
+    b = append(b, `{"crc32c":"`...)
+    b = append(b, checksum(data)...)
+    b = append(b, `","data":`...)
+    b = append(b, data...)
+    return append(b, "}\n"...), nil
+}
+
+// ExportEvents writes events to w in the export format, for moving them
+// between machines, e.g. out of an air-gapped network, and reading them back
+// with ImportEvents or the scarf-replay command.
+func ExportEvents(w io.Writer, events []Event) error {
+    ew := NewEventWriter(w)
+    for _, ev := range events {
+        if err := ew.Write(ev); err != nil {
+            return err
+        }
+    }
+    return ew.Close()
+}
+
+// ImportEvents reads all the events in an export from r. It also accepts
+// files of plain event objects, one per line, as written before the format
+// was versioned. On error it returns the events read so far.
+func ImportEvents(r io.Reader) ([]Event, error) {
+    er := NewEventReader(r)
+    var events []Event
+    for {
+        ev, err := er.Next()
+        if err == io.EOF {
+            return events, nil
+        }
+        if err != nil {
+            return events, err
+        }
+        events = append(events, ev)
+    }
+}
+
+// EventWriter writes an export one event at a time, for exports too large
+// to hold in memory. It is not safe for concurrent use.
+type EventWriter struct {
+    w      io.Writer
+    buf    []byte
+    header bool
+    count  int
+}
+
+// NewEventWriter returns an EventWriter writing to w.
+func NewEventWriter(w io.Writer) *EventWriter {
+    return &EventWriter{w: w}
+}
+
+// Write writes ev, preceded by the header if it is the first event.
+func (ew *EventWriter) Write(ev Event) error {
+    ew.buf = ew.buf[:0]
+    if !ew.header {
+        ew.buf = append(ew.buf, exportHeader()...)
+    }
+    b, err := appendExportRecord(ew.buf, ev)
+    if err != nil {
+        return fmt.Errorf("scarf: encode event: %w", err)
+    }
+    ew.buf = b
+    if _, err := ew.w.Write(ew.buf); err != nil {
+        return err
+    }
+    ew.header = true
+    ew.count++
+    return nil
+}
+
+// Close writes the trailer. It does not close the underlying writer.
+func (ew *EventWriter) Close() error {
+    b := []byte(nil)
+    if !ew.header {
+        b = exportHeader()
+    }
+    b = append(b, `{"count":`+strconv.Itoa(ew.count)+"}\n"...)
+    _, err := ew.w.Write(b)
+    return err
+}
+
+// EventReader reads an export one event at a time. It is not safe for
+// concurrent use.
+type EventReader struct {
+    br      *bufio.Reader
+    line    int
+    version int // 0 until a header is read: lines are plain event objects
+    count   int // records since the last header or trailer
+    err     error
+}
+
+// NewEventReader returns an EventReader reading from r.
+func NewEventReader(r io.Reader) *EventReader {
+    return &EventReader{br: bufio.NewReader(r)}
+}
+
+// exportLine is any line of a versioned export.
+type exportLine struct {
+    Format  string          `json:"format"`
+    Version int             `json:"version"`
+    CRC     string          `json:"crc32c"`
+    Data    json.RawMessage `json:"data"`
+    Count   *int            `json:"count"`
+}
+
+// Next returns the next event, or io.EOF at the end of the input. A line
+// that can't be read is reported as an *ExportLineError, after which Next
+// may be called again to continue with the following line; other errors,
+// such as ErrExportVersion or a read error, end the export.
+func (er *EventReader) Next() (Event, error) {
+    for er.err == nil {
+        raw, err := er.br.ReadBytes('\n')
+        if err != nil {
+            // io.EOF or a read error; raw may still hold a final line.
+            er.err = err
+        }
+        if len(raw) > 0 {
+            er.line++
+        }
+        b := bytes.TrimSpace(raw)
+        if len(b) == 0 {
+            continue
+        }
+        ev, ok, err := er.parseLine(b)
+        switch {
+        case errors.Is(err, ErrExportVersion):
+            er.err = err
+        case err != nil:
+            return Event{}, &ExportLineError{Line: er.line, Err: err}
+        case ok:
+            return ev, nil
+        }
+    }
+    return Event{}, er.err
+}
+
+// parseLine decodes one non-empty line, reporting false for header and
+// trailer lines.
+func (er *EventReader) parseLine(b []byte) (Event, bool, error) {
+    if isExportHeader(b) {
+        var l exportLine
+        if err := json.Unmarshal(b, &l); err != nil {
+            return Event{}, false, err
+        }
+        return Event{}, false, er.header(l)
+    }
+    if er.version == 0 {
+        ev, err := eventFromJSON(b)
+        return ev, err == nil, err
+    }
+
+    var l exportLine
+    if err := json.Unmarshal(b, &l); err != nil {
+        return Event{}, false, err
+    }
+    switch {
+    case l.Count != nil:
+        n := er.count
+        er.count = 0
+        if *l.Count != n {
+            return Event{}, false, fmt.Errorf("%w: trailer counts %d records, read %d", ErrExportChecksum, *l.Count, n)
+        }
+        return Event{}, false, nil
+    case l.Data == nil:
+        return Event{}, false, errors.New("not an export record")
+    }
+    er.count++
+    if l.CRC != checksum(l.Data) {
+        return Event{}, false, ErrExportChecksum
+    }
+    ev, err := eventFromJSON(l.Data)
+    return ev, err == nil, err
+}
+
+// header starts a new export with the version in l.
+func (er *EventReader) header(l exportLine) error {
+    if l.Version < 1 || l.Version > ExportVersion {
+        return fmt.Errorf("%w %d (line %d)", ErrExportVersion, l.Version, er.line)
+    }
+    er.version, er.count = l.Version, 0
+    return nil
+}
+
+// eventFromJSON decodes an event object with the fields written by
+// jsonBody. Numbers are kept as json.Number, so they are sent on unchanged.
+func eventFromJSON(b []byte) (Event, error) {
+    dec := json.NewDecoder(bytes.NewReader(b))
+    dec.UseNumber()
+    var fields map[string]any
+    if err := dec.Decode(&fields); err != nil {
+        return Event{}, err
+    }
+    if fields == nil {
+        return Event{}, errors.New("not an event object")
+    }
+    var ev Event
+    if name, ok := fields["event"].(string); ok {
+        ev.Name = name
+        delete(fields, "event")
+    }
+    if ts, ok := fields["timestamp"].(string); ok {
+        t, err := time.Parse(time.RFC3339Nano, ts)
+        if err != nil {
+            return Event{}, fmt.Errorf("invalid timestamp: %w", err)
+        }
+        ev.Timestamp = t
+        delete(fields, "timestamp")
+    }
+    if id, ok := fields["event_id"].(string); ok {
+        ev.ID = id
+        delete(fields, "event_id")
+    }
+    ev.Properties = fields
+    return ev, nil
+}
@@ -0,0 +1,120 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "io"
+    "strings"
+    "testing"
+    "time"
+)
+
+func exportString(t *testing.T, events ...Event) string {
+    t.Helper()
+    var buf bytes.Buffer
+    if err := ExportEvents(&buf, events); err != nil {
+        t.Fatalf("ExportEvents: %v", err)
+    }
+    return buf.String()
+}
+
+func TestExportEvents_RoundTrip(t *testing.T) {
+    ts := time.Date(2024, 5, 1, 12, 0, 0, 123, time.UTC)
+    in := []Event{
+        {Name: "install", Timestamp: ts, ID: "id-1", Properties: map[string]any{
+            "os": "linux", "n": 12345678901234567, "ok": true, "tags": []any{"a"},
+        }},
+        {Name: "exit"},
+    }
+    s := exportString(t, in...)
+    lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+    if len(lines) != 4 || lines[0] != `{"format":"scarf-events","version":1}` || lines[3] != `{"count":2}` {
+        t.Fatalf("unexpected export:\n%s", s)
+    }
+
+    out, err := ImportEvents(strings.NewReader(s))
+    if err != nil {
+        t.Fatalf("ImportEvents: %v", err)
+    }
+    if len(out) != 2 || out[0].Name != "install" || out[0].ID != "id-1" || !out[0].Timestamp.Equal(ts) || out[1].Name != "exit" {
+        t.Fatalf("unexpected events: %+v", out)
+    }
+    props := out[0].Properties
+    if props["os"] != "linux" || props["n"] != json.Number("12345678901234567") || props["ok"] != true || len(props["tags"].([]any)) != 1 {
+        t.Fatalf("unexpected properties: %#v", props)
+    }
+
+    // Re-exporting an import reproduces the records exactly.
+    if again := exportString(t, out...); again != s {
+        t.Fatalf("expected a stable round trip:\n%s\n%s", s, again)
+    }
+}
+
+func TestEventReader_Corruption(t *testing.T) {
+    s := exportString(t, Event{Name: "a"}, Event{Name: "b"}, Event{Name: "c"})
+    lines := strings.Split(s, "\n")
+    lines[1] = strings.Replace(lines[1], `"a"`, `"x"`, 1)
+    lines = append(lines[:2], lines[3:]...) // drop "b"
+    er := NewEventReader(strings.NewReader(strings.Join(lines, "\n")))
+
+    var lineErr *ExportLineError
+    if _, err := er.Next(); !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.Is(err, ErrExportChecksum) {
+        t.Fatalf("expected a checksum error on line 2, got %v", err)
+    }
+    if ev, err := er.Next(); err != nil || ev.Name != "c" {
+        t.Fatalf("expected reading to continue with c, got %v, %v", ev, err)
+    }
+    if _, err := er.Next(); !errors.Is(err, ErrExportChecksum) || !strings.Contains(err.Error(), "trailer counts 3 records, read 2") {
+        t.Fatalf("expected the trailer to catch the missing record, got %v", err)
+    }
+    if _, err := er.Next(); err != io.EOF {
+        t.Fatalf("expected EOF, got %v", err)
+    }
+}
+
+func TestEventReader_TornLine(t *testing.T) {
+    s := exportString(t, Event{Name: "a"}, Event{Name: "b"})
+    s = s[:strings.LastIndex(s, `{"count"`)-10] // a spool cut off mid-write
+    events, err := ImportEvents(strings.NewReader(s))
+    var lineErr *ExportLineError
+    if len(events) != 1 || !errors.As(err, &lineErr) || lineErr.Line != 3 {
+        t.Fatalf("expected one event and an error on line 3, got %v, %v", events, err)
+    }
+}
+
+func TestEventReader_Version(t *testing.T) {
+    s := `{"format":"scarf-events","version":99}` + "\n" + `{"crc32c":"00000000","data":{}}` + "\n"
+    er := NewEventReader(strings.NewReader(s))
+    for i := 0; i < 2; i++ {
+        if _, err := er.Next(); !errors.Is(err, ErrExportVersion) {
+            t.Fatalf("expected ErrExportVersion, got %v", err)
+        }
+    }
+}
+
+func TestImportEvents_Legacy(t *testing.T) {
+    legacy := `{"event":"old","event_id":"1","timestamp":"2024-05-01T12:00:00Z","n":1}` + "\n\n"
+    s := legacy + exportString(t, Event{Name: "new"}) + exportString(t, Event{Name: "newer"})
+    events, err := ImportEvents(strings.NewReader(s))
+    if err != nil {
+        t.Fatalf("ImportEvents: %v", err)
+    }
+    if len(events) != 3 || events[0].Name != "old" || events[0].ID != "1" || events[0].Timestamp.IsZero() || events[2].Name != "newer" {
+        t.Fatalf("expected a legacy file followed by two exports to import, got %+v", events)
+    }
+
+    if _, err := ImportEvents(strings.NewReader(`{"event":"x","timestamp":"yesterday"}`)); err == nil || !strings.Contains(err.Error(), "line 1: invalid timestamp") {
+        t.Fatalf("expected a timestamp error, got %v", err)
+    }
+}
+
+func TestExportEvents_Empty(t *testing.T) {
+    s := exportString(t)
+    if s != `{"format":"scarf-events","version":1}`+"\n"+`{"count":0}`+"\n" {
+        t.Fatalf("unexpected empty export %q", s)
+    }
+    if events, err := ImportEvents(strings.NewReader(s)); err != nil || len(events) != 0 {
+        t.Fatalf("expected no events, got %v, %v", events, err)
+    }
+}
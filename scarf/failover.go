@@ -0,0 +1,49 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+)
+
+// WithFallbackEndpoints sets endpoints to try, in order, when delivery to the
+// primary endpoint fails with a network error or a 5xx response, e.g.
+// self-hosted gateways in other regions. Client errors (4xx) and rate
+// limiting are not retried elsewhere. Each attempt gets the full timeout and
+// counts as a separate delivery attempt for observers and the circuit
+// breaker. Batches sent with LogEvents fail over too; streamed uploads can't
+// be sent twice, so StreamEvents only fails over with WithRequestSigning,
+// which buffers the body.
+func WithFallbackEndpoints(urls ...string) Option {
+    return func(s *ScarfEventLogger) {
+        s.fallbackURLs = append(s.fallbackURLs, urls...)
+    }
+}
+
+// failoverable reports whether a failed attempt, with the given response
+// status (0 for none), should be retried against the next endpoint.
+func failoverable(status int, err error) bool {
+    if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) {
+        return false
+    }
+    return status == 0 || status >= 500
+}
+
+// failover calls send with the endpoint URL and then each fallback endpoint
+// in turn while attempts fail with network errors or 5xx responses. send
+// returns the response status code, if any.
+func (s *ScarfEventLogger) failover(ctx context.Context, send func(endpoint string) (int, error)) error {
+    endpoints := append([]string{s.endpointURL}, s.fallbackURLs...)
+    var err error
+    for i, endpoint := range endpoints {
+        var status int
+        status, err = send(endpoint)
+        if err == nil || ctx.Err() != nil || !failoverable(status, err) {
+            return err
+        }
+        if i+1 < len(endpoints) {
+            s.warn("endpoint failed; trying fallback", "error", err, "fallback", endpoints[i+1])
+            s.stats.retries.Add(1)
+        }
+    }
+    return err
+}
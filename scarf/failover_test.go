@@ -0,0 +1,101 @@
+package scarf
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+)
+
+func statusServer(t *testing.T, status int, hits *atomic.Int32) *httptest.Server {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        hits.Add(1)
+        w.WriteHeader(status)
+    }))
+    t.Cleanup(srv.Close)
+    return srv
+}
+
+func TestFallbackEndpoints_FailOver(t *testing.T) {
+    var primaryHits, fallbackHits atomic.Int32
+    primary := statusServer(t, http.StatusBadGateway, &primaryHits)
+    fallback := statusServer(t, http.StatusOK, &fallbackHits)
+
+    // The first fallback refuses connections.
+    dead := httptest.NewServer(http.NotFoundHandler())
+    deadURL := dead.URL
+    dead.Close()
+
+    l := New(primary.URL, WithFallbackEndpoints(deadURL, fallback.URL))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("expected fallback to succeed, got %v", err)
+    }
+    if primaryHits.Load() != 1 || fallbackHits.Load() != 1 {
+        t.Fatalf("expected one hit each, got primary=%d fallback=%d", primaryHits.Load(), fallbackHits.Load())
+    }
+}
+
+func TestFallbackEndpoints_NotOnClientError(t *testing.T) {
+    var primaryHits, fallbackHits atomic.Int32
+    primary := statusServer(t, http.StatusBadRequest, &primaryHits)
+    fallback := statusServer(t, http.StatusOK, &fallbackHits)
+
+    l := New(primary.URL, WithFallbackEndpoints(fallback.URL))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil {
+        t.Fatalf("expected 4xx error")
+    }
+    if fallbackHits.Load() != 0 {
+        t.Fatalf("4xx responses must not fail over")
+    }
+}
+
+func TestFallbackEndpoints_AllFail(t *testing.T) {
+    var hits atomic.Int32
+    a := statusServer(t, http.StatusInternalServerError, &hits)
+    b := statusServer(t, http.StatusServiceUnavailable, &hits)
+
+    var attempts int
+    l := New(a.URL, WithFallbackEndpoints(b.URL), WithOnFailure(func(Delivery) { attempts++ }))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil {
+        t.Fatalf("expected error when every endpoint fails")
+    }
+    if hits.Load() != 2 || attempts != 2 {
+        t.Fatalf("expected 2 attempts, got hits=%d failures=%d", hits.Load(), attempts)
+    }
+}
+
+func TestFallbackEndpoints_Batch(t *testing.T) {
+    var primaryHits, fallbackHits atomic.Int32
+    primary := statusServer(t, http.StatusBadGateway, &primaryHits)
+    fallback := statusServer(t, http.StatusOK, &fallbackHits)
+
+    l := New(primary.URL, WithFallbackEndpoints(fallback.URL))
+    if err := l.LogEvents(context.Background(), []Event{{Name: "a"}, {Name: "b"}}); err != nil {
+        t.Fatalf("expected the batch to fail over, got %v", err)
+    }
+    if primaryHits.Load() != 1 || fallbackHits.Load() != 1 {
+        t.Fatalf("expected one hit each, got primary=%d fallback=%d", primaryHits.Load(), fallbackHits.Load())
+    }
+
+    // Streamed bodies can't be resent.
+    next := func() func() (Event, bool) {
+        sent := false
+        return func() (Event, bool) {
+            if sent {
+                return Event{}, false
+            }
+            sent = true
+            return Event{Name: "a"}, true
+        }
+    }
+    if err := l.StreamEvents(context.Background(), next()); err == nil || fallbackHits.Load() != 1 {
+        t.Fatalf("expected the stream to fail without failing over, got %v (fallback hits %d)", err, fallbackHits.Load())
+    }
+
+    // Signed streams are buffered, so they fail over.
+    l = New(primary.URL, WithFallbackEndpoints(fallback.URL), WithRequestSigning([]byte("k")))
+    if err := l.StreamEvents(context.Background(), next()); err != nil || fallbackHits.Load() != 2 {
+        t.Fatalf("expected the signed stream to fail over, got %v (fallback hits %d)", err, fallbackHits.Load())
+    }
+}
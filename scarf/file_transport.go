@@ -0,0 +1,141 @@
+package scarf
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "sync"
+)
+
+// FileTransport appends each event to a local file in the export format (see
+// ExportVersion), for air-gapped environments where telemetry is shipped out
+// of band later and read back with ImportEvents or the scarf-replay command.
+// When a write would grow the file beyond its maximum size, the file is
+// rotated: path becomes path.1, path.1 becomes path.2, and so on, keeping at
+// most the configured number of backups.
+type FileTransport struct {
+    path    string
+    maxSize int64
+    backups int
+
+    mu   sync.Mutex
+    file *os.File
+    size int64
+}
+
+// NewFileTransport opens (or creates) path for appending. maxSize is the
+// size in bytes at which the file is rotated (0 for no rotation) and backups
+// how many rotated files are kept; older ones are removed.
+func NewFileTransport(path string, maxSize int64, backups int) (*FileTransport, error) {
+    t := &FileTransport{path: path, maxSize: maxSize, backups: backups}
+    if err := t.open(); err != nil {
+        return nil, err
+    }
+    return t, nil
+}
+
+func (t *FileTransport) open() error {
+    f, err := os.OpenFile(t.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+    if err != nil {
+        return fmt.Errorf("scarf: open event file: %w", err)
+    }
+    info, err := f.Stat()
+    if err != nil {
+        f.Close()
+        return fmt.Errorf("scarf: open event file: %w", err)
+    }
+    t.file, t.size = f, info.Size()
+    if t.size == 0 || !startsWithHeader(t.path) {
+        // A file written before the format was versioned gets a header
+        // before the first new record.
+        n, err := f.Write(exportHeader())
+        t.size += int64(n)
+        if err != nil {
+            return fmt.Errorf("scarf: write event file: %w", err)
+        }
+    }
+    return nil
+}
+
+// startsWithHeader reports whether the file at path starts with an export
+// header line.
+func startsWithHeader(path string) bool {
+    f, err := os.Open(path)
+    if err != nil {
+        return false
+    }
+    defer f.Close()
+    b := make([]byte, len(exportHeaderPrefix))
+    _, err = io.ReadFull(f, b)
+    return err == nil && isExportHeader(b)
+}
+
+// Send appends ev to the file, rotating it first if needed. A single event
+// larger than the maximum size is still written, to a fresh file.
+func (t *FileTransport) Send(_ context.Context, ev Event) error {
+    b, err := appendExportRecord(nil, ev)
+    if err != nil {
+        return fmt.Errorf("scarf: encode event: %w", err)
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if t.file == nil {
+        return fmt.Errorf("scarf: write event file: %w", os.ErrClosed)
+    }
+    if t.maxSize > 0 && t.size > int64(len(exportHeader())) && t.size+int64(len(b)) > t.maxSize {
+        if err := t.rotate(); err != nil {
+            return err
+        }
+    }
+    n, err := t.file.Write(b)
+    t.size += int64(n)
+    if err != nil {
+        return fmt.Errorf("scarf: write event file: %w", err)
+    }
+    return nil
+}
+
+// rotate shifts the backups, moves the current file to path.1 and reopens
+// path. If the file can't be moved aside, writing continues to it. It is
+// called with t.mu held.
+func (t *FileTransport) rotate() error {
+    if err := t.file.Close(); err != nil {
+        return fmt.Errorf("scarf: rotate event file: %w", err)
+    }
+    t.file = nil
+    var err error
+    if t.backups > 0 {
+        os.Remove(t.backupPath(t.backups))
+        for i := t.backups - 1; i >= 1; i-- {
+            os.Rename(t.backupPath(i), t.backupPath(i+1))
+        }
+        err = os.Rename(t.path, t.backupPath(1))
+    } else {
+        err = os.Remove(t.path)
+    }
+    if openErr := t.open(); openErr != nil {
+        return openErr
+    }
+    if err != nil {
+        return fmt.Errorf("scarf: rotate event file: %w", err)
+    }
+    return nil
+}
+
+func (t *FileTransport) backupPath(i int) string {
+    return fmt.Sprintf("%s.%d", t.path, i)
+}
+
+// Close closes the file. Later sends fail.
+func (t *FileTransport) Close() error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if t.file == nil {
+        return nil
+    }
+    err := t.file.Close()
+    t.file = nil
+    return err
+}
@@ -0,0 +1,107 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func readEvents(t *testing.T, path string) []Event {
+    t.Helper()
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatalf("Open: %v", err)
+    }
+    defer f.Close()
+    events, err := ImportEvents(f)
+    if err != nil {
+        t.Fatalf("ImportEvents: %v", err)
+    }
+    return events
+}
+
+func TestFileTransport(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "events.jsonl")
+    tr, err := NewFileTransport(path, 0, 0)
+    if err != nil {
+        t.Fatalf("NewFileTransport: %v", err)
+    }
+    l := New("", WithTransport(tr))
+    for _, name := range []string{"a", "b"} {
+        if err := l.LogEvent(map[string]any{"event": name, "n": 1}); err != nil {
+            t.Fatalf("LogEvent: %v", err)
+        }
+    }
+    tr.Close()
+    if err := l.LogEvent(map[string]any{"event": "c"}); err == nil {
+        t.Fatalf("expected an error after Close")
+    }
+
+    events := readEvents(t, path)
+    if len(events) != 2 || events[0].Name != "a" || events[1].Name != "b" || events[0].Properties["n"] != json.Number("1") || events[0].ID == "" {
+        t.Fatalf("unexpected events: %v", events)
+    }
+
+    // Reopening appends.
+    tr, _ = NewFileTransport(path, 0, 0)
+    New("", WithTransport(tr)).LogEvent(map[string]any{"event": "d"})
+    tr.Close()
+    if n := len(readEvents(t, path)); n != 3 {
+        t.Fatalf("expected 3 events after reopening, got %d", n)
+    }
+    if b, _ := os.ReadFile(path); bytes.Count(b, exportHeader()) != 1 {
+        t.Fatalf("expected a single header after reopening, got %q", b)
+    }
+}
+
+func TestFileTransport_Rotation(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "events.jsonl")
+    tr, err := NewFileTransport(path, 200, 2)
+    if err != nil {
+        t.Fatalf("NewFileTransport: %v", err)
+    }
+    defer tr.Close()
+    l := New("", WithTransport(tr))
+    for i := 0; i < 20; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x", "i": i}); err != nil {
+            t.Fatalf("LogEvent: %v", err)
+        }
+    }
+
+    for _, p := range []string{path, path + ".1", path + ".2"} {
+        info, err := os.Stat(p)
+        if err != nil {
+            t.Fatalf("expected %s: %v", p, err)
+        }
+        if info.Size() > 200 {
+            t.Fatalf("%s is %d bytes, want at most 200", p, info.Size())
+        }
+    }
+    if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+        t.Fatalf("expected only 2 backups, got %v", err)
+    }
+    cur, prev := readEvents(t, path), readEvents(t, path+".1")
+    last, first := prev[len(prev)-1].Properties["i"].(json.Number), cur[0].Properties["i"].(json.Number)
+    lastN, _ := last.Int64()
+    firstN, _ := first.Int64()
+    if cur[len(cur)-1].Properties["i"] != json.Number("19") || lastN+1 != firstN {
+        t.Fatalf("expected events to continue across rotation: %v / %v", prev, cur)
+    }
+}
+
+func TestFileTransport_LegacyFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "events.jsonl")
+    os.WriteFile(path, []byte(`{"event":"old","event_id":"1"}`+"\n"), 0o600)
+    tr, err := NewFileTransport(path, 0, 0)
+    if err != nil {
+        t.Fatalf("NewFileTransport: %v", err)
+    }
+    New("", WithTransport(tr)).LogEvent(map[string]any{"event": "new"})
+    tr.Close()
+    events := readEvents(t, path)
+    if len(events) != 2 || events[0].Name != "old" || events[1].Name != "new" {
+        t.Fatalf("expected the old and new events, got %v", events)
+    }
+}
@@ -0,0 +1,90 @@
+package scarf
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+// IsFirstRun reports whether this is the first time the application has run
+// on this machine, for telling installs apart from repeated use. The first
+// call creates a marker file next to the install ID (see InstallID); the
+// answer is then fixed for the life of the logger, so every caller in the
+// process sees the same result. It requires WithAppName or WithStateDir.
+//
+// When analytics are disabled or consent has not been granted, the marker is
+// never read or created and an error is returned.
+func (s *ScarfEventLogger) IsFirstRun() (bool, error) {
+    if s == nil {
+        return false, ErrDisabled
+    }
+    if !s.Enabled() {
+        return false, ErrDisabled
+    }
+    if err := s.checkConsent(); err != nil {
+        return false, err
+    }
+    dir, err := s.appStateDir()
+    if err != nil {
+        return false, fmt.Errorf("scarf: first run: %w", err)
+    }
+    if dir == "" {
+        return false, errors.New("scarf: first-run detection requires WithAppName or WithStateDir")
+    }
+    return s.firstRun.get(filepath.Join(dir, "first_run"))
+}
+
+// LogFirstRunEvent sends a standardized "first_run" event, with the same
+// properties as LogInstallEvent, if IsFirstRun reports true, and does
+// nothing otherwise. The marker is created before the event is sent, so an
+// event that fails to send is not retried on a later run.
+func (s *ScarfEventLogger) LogFirstRunEvent(props map[string]any) error {
+    if s == nil {
+        return nil
+    }
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    first, err := s.IsFirstRun()
+    if err != nil || !first {
+        return err
+    }
+    return s.LogEventStruct(s.standardEvent("first_run", props))
+}
+
+// firstRunCache checks and creates the first-run marker once per logger.
+type firstRunCache struct {
+    mu      sync.Mutex
+    checked bool
+    first   bool
+}
+
+func (c *firstRunCache) get(path string) (bool, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.checked {
+        return c.first, nil
+    }
+
+    if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return false, fmt.Errorf("scarf: create first-run marker: %w", err)
+    }
+    // O_EXCL makes the check atomic, so of several processes started at once
+    // only one sees a first run.
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+    switch {
+    case err == nil:
+        _, _ = f.WriteString(time.Now().UTC().Format(time.RFC3339) + "\n")
+        f.Close()
+        c.first = true
+    case errors.Is(err, os.ErrExist):
+        c.first = false
+    default:
+        return false, fmt.Errorf("scarf: create first-run marker: %w", err)
+    }
+    c.checked = true
+    return c.first, nil
+}
@@ -0,0 +1,64 @@
+package scarf
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestIsFirstRun(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    l := New("", WithAppName("mytool"))
+    for i := 0; i < 2; i++ {
+        if first, err := l.IsFirstRun(); err != nil || !first {
+            t.Fatalf("expected a first run (call %d), got %v (err=%v)", i, first, err)
+        }
+    }
+    if first, err := New("", WithAppName("mytool")).IsFirstRun(); err != nil || first {
+        t.Fatalf("expected a repeated run, got %v (err=%v)", first, err)
+    }
+    if first, _ := New("", WithAppName("othertool")).IsFirstRun(); !first {
+        t.Fatal("first-run markers must be namespaced per app")
+    }
+    if _, err := New("").IsFirstRun(); err == nil {
+        t.Fatal("expected an error without WithAppName")
+    }
+}
+
+func TestIsFirstRun_NotCreatedWhenDisabled(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    l := New("", WithAppName("mytool"), WithEnabled(false))
+    if _, err := l.IsFirstRun(); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+    if entries, _ := os.ReadDir(filepath.Join(dir, "scarf")); len(entries) != 0 {
+        t.Fatalf("expected no marker while disabled, found %v", entries)
+    }
+}
+
+func TestLogFirstRunEvent(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    var got []Event
+    if err := New("", WithAppName("mytool"), captureEvents(&got)).LogFirstRunEvent(map[string]any{"channel": "brew"}); err != nil {
+        t.Fatalf("LogFirstRunEvent: %v", err)
+    }
+    if err := New("", WithAppName("mytool"), captureEvents(&got)).LogFirstRunEvent(nil); err != nil {
+        t.Fatalf("LogFirstRunEvent: %v", err)
+    }
+    if len(got) != 1 || got[0].Name != "first_run" || got[0].Properties["channel"] != "brew" || got[0].Properties["install_id"] == nil {
+        t.Fatalf("expected a single first_run event, got %+v", got)
+    }
+}
@@ -0,0 +1,34 @@
+package scarf
+
+import (
+    "errors"
+    "net/url"
+    "strings"
+)
+
+// packageEventsBaseURL is the base of Scarf's package event endpoints.
+const packageEventsBaseURL = "https://scarf.sh/api/v1/packages"
+
+// ErrInvalidPackageID is returned for empty or malformed Scarf package IDs.
+var ErrInvalidPackageID = errors.New("scarf: invalid package ID")
+
+// PackageEventURL returns the Scarf event endpoint for a package,
+// https://scarf.sh/api/v1/packages/{id}/events.
+func PackageEventURL(packageID string) (string, error) {
+    id := strings.TrimSpace(packageID)
+    if id == "" || strings.ContainsAny(id, "/?#") {
+        return "", ErrInvalidPackageID
+    }
+    return packageEventsBaseURL + "/" + url.PathEscape(id) + "/events", nil
+}
+
+// NewPackageEventLogger creates a logger that sends events for the Scarf
+// package with the given ID, so the endpoint URL needn't be built by hand.
+//   logger, err := NewPackageEventLogger("your-package-id", WithAPIKey(key))
+func NewPackageEventLogger(packageID string, opts ...Option) (*ScarfEventLogger, error) {
+    endpoint, err := PackageEventURL(packageID)
+    if err != nil {
+        return nil, err
+    }
+    return New(endpoint, opts...), nil
+}
@@ -0,0 +1,34 @@
+package scarf
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestPackageEventURL(t *testing.T) {
+    got, err := PackageEventURL(" 4bd7d1a6-0b8b-4d8c-9be8-5d1a3c6e2f10 ")
+    if err != nil {
+        t.Fatalf("PackageEventURL: %v", err)
+    }
+    if want := "https://scarf.sh/api/v1/packages/4bd7d1a6-0b8b-4d8c-9be8-5d1a3c6e2f10/events"; got != want {
+        t.Fatalf("got %q, want %q", got, want)
+    }
+    for _, id := range []string{"", "  ", "a/b", "a?b", "a#b"} {
+        if _, err := PackageEventURL(id); !errors.Is(err, ErrInvalidPackageID) {
+            t.Errorf("PackageEventURL(%q): expected ErrInvalidPackageID, got %v", id, err)
+        }
+    }
+}
+
+func TestNewPackageEventLogger(t *testing.T) {
+    l, err := NewPackageEventLogger("pkg", WithAPIKey("k"))
+    if err != nil {
+        t.Fatalf("NewPackageEventLogger: %v", err)
+    }
+    if l.endpointURL != "https://scarf.sh/api/v1/packages/pkg/events" || l.apiKey != "k" {
+        t.Fatalf("unexpected logger config: %q %q", l.endpointURL, l.apiKey)
+    }
+    if _, err := NewPackageEventLogger(""); !errors.Is(err, ErrInvalidPackageID) {
+        t.Fatalf("expected ErrInvalidPackageID, got %v", err)
+    }
+}
@@ -0,0 +1,29 @@
+package scarf
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "os"
+    "strings"
+)
+
+// HashIdentifier returns a stable, non-reversible hex digest of value, keyed
+// by salt (HMAC-SHA256). Use a salt unique to your project so hashes of the
+// same identifier cannot be correlated across tools:
+//
+//   logger.LogEvent(map[string]any{"host": scarf.HashIdentifier(hostname, "mytool")})
+func HashIdentifier(value, salt string) string {
+    mac := hmac.New(sha256.New, []byte(salt))
+    mac.Write([]byte(value))
+    return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashedHostname returns HashIdentifier of the lower-cased host name.
+func HashedHostname(salt string) (string, error) {
+    host, err := os.Hostname()
+    if err != nil {
+        return "", err
+    }
+    return HashIdentifier(strings.ToLower(host), salt), nil
+}
@@ -0,0 +1,44 @@
+package scarf
+
+import (
+    "os"
+    "strings"
+    "testing"
+)
+
+func TestHashIdentifier(t *testing.T) {
+    a := HashIdentifier("alice", "mytool")
+    if len(a) != 64 {
+        t.Fatalf("expected 64 hex chars, got %d", len(a))
+    }
+    if a != HashIdentifier("alice", "mytool") {
+        t.Fatalf("hash must be stable")
+    }
+    if a == HashIdentifier("alice", "othertool") {
+        t.Fatalf("different salts must yield different hashes")
+    }
+    if a == HashIdentifier("bob", "mytool") {
+        t.Fatalf("different values must yield different hashes")
+    }
+    if strings.Contains(a, "alice") {
+        t.Fatalf("hash must not contain the input")
+    }
+    // HMAC-SHA256("mytool", "alice"), computed independently.
+    if want := "a772cee3020cd345f88ecb9402cfd39cb6776a91e955f2a96e8591643093f29b"; a != want {
+        t.Fatalf("HashIdentifier = %s, want %s", a, want)
+    }
+}
+
+func TestHashedHostname(t *testing.T) {
+    host, err := os.Hostname()
+    if err != nil {
+        t.Skipf("no hostname: %v", err)
+    }
+    got, err := HashedHostname("salt")
+    if err != nil {
+        t.Fatalf("HashedHostname: %v", err)
+    }
+    if got != HashIdentifier(strings.ToLower(host), "salt") {
+        t.Fatalf("unexpected hostname hash")
+    }
+}
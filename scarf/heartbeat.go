@@ -0,0 +1,56 @@
+package scarf
+
+import (
+    "context"
+    "time"
+)
+
+// defaultHeartbeatInterval is used by StartHeartbeat for non-positive intervals.
+const defaultHeartbeatInterval = 15 * time.Minute
+
+// StartHeartbeat emits a lightweight "heartbeat" event every interval (15
+// minutes if interval is zero or less) until the returned stop function is
+// called or the logger is closed. Each event carries props plus
+// uptime_seconds, the whole seconds since StartHeartbeat was called. Set
+// props["event"] to use a different event name.
+//
+// Heartbeats give long-running daemons a liveness signal, not just a
+// startup event. Send errors are logged in verbose mode and otherwise ignored.
+func (s *ScarfEventLogger) StartHeartbeat(interval time.Duration, props map[string]any) (stop func()) {
+    if buildDisabled || s == nil || s.noop {
+        return func() {}
+    }
+    if interval <= 0 {
+        interval = defaultHeartbeatInterval
+    }
+    props = copyProperties(props)
+    if _, ok := props["event"]; !ok {
+        props["event"] = "heartbeat"
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    go func() {
+        start := s.clock.Now()
+        timer := s.clock.NewTimer(interval)
+        defer timer.Stop()
+        for {
+            select {
+            case <-timer.C():
+                timer.Reset(interval)
+                if refused, _ := s.refuse(); refused {
+                    continue
+                }
+                ev := eventFromProperties(copyProperties(props))
+                ev.Properties["uptime_seconds"] = int64(s.clock.Now().Sub(start) / time.Second)
+                if err := s.logEventInternal(ctx, ev, s.defaultTimeout); err != nil {
+                    s.debug("heartbeat not sent", "error", err)
+                }
+            case <-ctx.Done():
+                return
+            case <-s.done:
+                return
+            }
+        }
+    }()
+    return cancel
+}
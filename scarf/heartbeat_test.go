@@ -0,0 +1,62 @@
+package scarf
+
+import (
+    "context"
+    "testing"
+    "time"
+)
+
+func TestStartHeartbeat(t *testing.T) {
+    events := make(chan Event, 16)
+    l := New("", WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        events <- ev
+        return nil
+    })))
+    props := map[string]any{"service": "api"}
+    stop := l.StartHeartbeat(5*time.Millisecond, props)
+    defer stop()
+
+    for i := 0; i < 2; i++ {
+        select {
+        case ev := <-events:
+            if ev.Name != "heartbeat" || ev.Properties["service"] != "api" {
+                t.Fatalf("unexpected heartbeat event: %+v", ev)
+            }
+            if _, ok := ev.Properties["uptime_seconds"].(int64); !ok {
+                t.Fatalf("expected uptime_seconds, got %v", ev.Properties)
+            }
+        case <-time.After(time.Second):
+            t.Fatalf("heartbeat %d not sent", i)
+        }
+    }
+    if len(props) != 1 {
+        t.Fatalf("caller's props were modified: %v", props)
+    }
+}
+
+func TestStartHeartbeat_StopsOnClose(t *testing.T) {
+    events := make(chan Event, 64)
+    l := New("", WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        events <- ev
+        return nil
+    })))
+    l.StartHeartbeat(time.Millisecond, map[string]any{"event": "alive"})
+    ev := <-events
+    if ev.Name != "alive" {
+        t.Fatalf("expected custom event name, got %q", ev.Name)
+    }
+
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    // Drain anything sent before Close, then make sure nothing follows.
+    time.Sleep(10 * time.Millisecond)
+    for len(events) > 0 {
+        <-events
+    }
+    select {
+    case ev := <-events:
+        t.Fatalf("heartbeat sent after Close: %+v", ev)
+    case <-time.After(20 * time.Millisecond):
+    }
+}
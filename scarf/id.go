@@ -0,0 +1,70 @@
+package scarf
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "time"
+)
+
+// NewEventID returns a new UUIDv7: a random UUID whose leading 48 bits are the
+// Unix time in milliseconds, so IDs sort in creation order. Loggers take the
+// time from their Clock (see WithClock).
+func NewEventID() string {
+    return newEventID(time.Now())
+}
+
+// newEventID returns a new UUIDv7 for the time now.
+func newEventID(now time.Time) string {
+    var u [16]byte
+    _, _ = rand.Read(u[:])
+
+    ms := uint64(now.UnixMilli())
+    u[0] = byte(ms >> 40)
+    u[1] = byte(ms >> 32)
+    u[2] = byte(ms >> 24)
+    u[3] = byte(ms >> 16)
+    u[4] = byte(ms >> 8)
+    u[5] = byte(ms)
+    u[6] = (u[6] & 0x0f) | 0x70 // version 7
+    u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+    return formatUUID(u)
+}
+
+func formatUUID(u [16]byte) string {
+    var buf [36]byte
+    hex.Encode(buf[0:8], u[0:4])
+    buf[8] = '-'
+    hex.Encode(buf[9:13], u[4:6])
+    buf[13] = '-'
+    hex.Encode(buf[14:18], u[6:8])
+    buf[18] = '-'
+    hex.Encode(buf[19:23], u[8:10])
+    buf[23] = '-'
+    hex.Encode(buf[24:], u[10:])
+    return string(buf[:])
+}
+
+// WithIDGenerator overrides how event IDs are generated (NewEventID by default).
+// Returning an empty string omits the ID. Events that already carry an ID are
+// left untouched.
+func WithIDGenerator(fn func() string) Option {
+    return func(s *ScarfEventLogger) {
+        if fn != nil {
+            s.newID = fn
+        }
+    }
+}
+
+// stampEvent fills in a client-side timestamp and ID unless already set.
+func (s *ScarfEventLogger) stampEvent(ev Event) Event {
+    if ev.Timestamp.IsZero() {
+        ev.Timestamp = s.clock.Now()
+    }
+    if ev.ID == "" {
+        if s.newID == nil || s.guard("ID generator", func() { ev.ID = s.newID() }) != nil {
+            ev.ID = newEventID(s.clock.Now())
+        }
+    }
+    return ev
+}
@@ -0,0 +1,70 @@
+package scarf
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "regexp"
+    "testing"
+    "time"
+)
+
+var uuidV7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewEventID(t *testing.T) {
+    seen := map[string]bool{}
+    prev := ""
+    for i := 0; i < 100; i++ {
+        id := NewEventID()
+        if !uuidV7Pattern.MatchString(id) {
+            t.Fatalf("not a UUIDv7: %q", id)
+        }
+        if seen[id] {
+            t.Fatalf("duplicate id %q", id)
+        }
+        seen[id] = true
+        // The timestamp prefix must never go backwards.
+        if prev != "" && id[:13] < prev[:13] {
+            t.Fatalf("ids out of order: %q before %q", prev, id)
+        }
+        prev = id
+    }
+}
+
+func TestAutoTimestampAndID(t *testing.T) {
+    var ids []string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        ts, err := time.Parse(time.RFC3339Nano, q.Get("timestamp"))
+        if err != nil || time.Since(ts) > time.Minute {
+            t.Fatalf("expected recent timestamp, got %q (err=%v)", q.Get("timestamp"), err)
+        }
+        ids = append(ids, q.Get("event_id"))
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL)
+    for i := 0; i < 2; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+            t.Fatalf("expected success, got %v", err)
+        }
+    }
+    if len(ids) != 2 || ids[0] == "" || ids[0] == ids[1] {
+        t.Fatalf("expected two distinct event ids, got %v", ids)
+    }
+}
+
+func TestIDOverrides(t *testing.T) {
+    l := New("https://example.com", WithIDGenerator(func() string { return "fixed" }))
+    if ev := l.stampEvent(Event{}); ev.ID != "fixed" {
+        t.Fatalf("expected generator id, got %q", ev.ID)
+    }
+    ts := time.Unix(1700000000, 0)
+    ev := l.stampEvent(Event{ID: "mine", Timestamp: ts})
+    if ev.ID != "mine" || !ev.Timestamp.Equal(ts) {
+        t.Fatalf("explicit id/timestamp should be preserved, got %+v", ev)
+    }
+    if ev := New("https://example.com", WithIDGenerator(func() string { return "" })).stampEvent(Event{}); ev.ID != "" {
+        t.Fatalf("empty generator result should omit id, got %q", ev.ID)
+    }
+}
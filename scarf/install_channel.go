@@ -0,0 +1,149 @@
+package scarf
+
+import (
+    "bufio"
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+)
+
+var (
+    installChannelOnce sync.Once
+    installChannelDone = make(chan struct{})
+    installChannel     string
+)
+
+// InstallChannel returns how the running binary appears to have been
+// installed: "homebrew", "snap", "nix", "scoop", "go_install" (GOBIN or
+// GOPATH/bin), "apt" (owned by a dpkg package) or "docker" (running in a
+// container), or "" if unknown. Detection is best effort, from the
+// executable's path and package manager metadata, and runs once per process.
+func InstallChannel() string {
+    startInstallChannel()
+    <-installChannelDone
+    return installChannel
+}
+
+// startInstallChannel starts detecting the install channel in the background
+// unless that has already been done.
+func startInstallChannel() {
+    installChannelOnce.Do(func() {
+        go func() {
+            defer close(installChannelDone)
+            exe, err := os.Executable()
+            if err != nil {
+                return
+            }
+            if p, err := filepath.EvalSymlinks(exe); err == nil {
+                exe = p
+            }
+            installChannel = detectInstallChannel(context.Background(), exe, "/")
+        }()
+    })
+}
+
+// InstallChannelEnricher returns an Enricher adding "install_channel" from
+// InstallChannel, or nothing if the channel is unknown. Detection, which can
+// scan the dpkg database, starts in the background when the enricher is
+// created; events sent before it finishes wait for it only until their
+// context is done, and go without the property if it ends first.
+func InstallChannelEnricher() Enricher {
+    startInstallChannel()
+    return EnricherFunc(func(ctx context.Context) map[string]any {
+        select {
+        case <-installChannelDone:
+        case <-ctx.Done():
+            return nil
+        }
+        if installChannel != "" {
+            return map[string]any{"install_channel": installChannel}
+        }
+        return nil
+    })
+}
+
+// detectInstallChannel classifies exe, looking for package manager metadata
+// under root. The dpkg scan stops early, finding nothing, once ctx is done.
+func detectInstallChannel(ctx context.Context, exe, root string) string {
+    p := filepath.ToSlash(exe)
+    switch {
+    case strings.Contains(p, "/Cellar/") || strings.Contains(p, "/linuxbrew/"):
+        return "homebrew"
+    case strings.HasPrefix(p, "/snap/"):
+        return "snap"
+    case strings.HasPrefix(p, "/nix/store/"):
+        return "nix"
+    case strings.Contains(strings.ToLower(p), "/scoop/apps/"):
+        return "scoop"
+    }
+    if inGoBin(exe) {
+        return "go_install"
+    }
+    if dpkgOwns(ctx, root, p) {
+        return "apt"
+    }
+    if _, err := os.Stat(filepath.Join(root, ".dockerenv")); err == nil {
+        return "docker"
+    }
+    return ""
+}
+
+// inGoBin reports whether exe is in GOBIN or a GOPATH bin directory, where
+// go install puts binaries.
+func inGoBin(exe string) bool {
+    var dirs []string
+    if gobin := os.Getenv("GOBIN"); gobin != "" {
+        dirs = append(dirs, gobin)
+    }
+    gopath := os.Getenv("GOPATH")
+    if gopath == "" {
+        if home, err := os.UserHomeDir(); err == nil {
+            gopath = filepath.Join(home, "go")
+        }
+    }
+    for _, p := range filepath.SplitList(gopath) {
+        dirs = append(dirs, filepath.Join(p, "bin"))
+    }
+    dir := filepath.Dir(exe)
+    for _, d := range dirs {
+        if filepath.Clean(d) == dir {
+            return true
+        }
+    }
+    return false
+}
+
+// dpkgOwns reports whether a dpkg package under root lists p among its files.
+// Only system directories are checked, so binaries installed by hand aren't
+// worth the scan.
+func dpkgOwns(ctx context.Context, root, p string) bool {
+    system := strings.HasPrefix(p, "/usr/") && !strings.HasPrefix(p, "/usr/local/") ||
+        strings.HasPrefix(p, "/bin/") || strings.HasPrefix(p, "/sbin/") || strings.HasPrefix(p, "/opt/")
+    if !system {
+        return false
+    }
+    // With merged /usr, packages may list /bin/x for what resolves to
+    // /usr/bin/x.
+    want := map[string]bool{p: true, strings.TrimPrefix(p, "/usr"): true}
+    lists, _ := filepath.Glob(filepath.Join(root, "var", "lib", "dpkg", "info", "*.list"))
+    for _, list := range lists {
+        if ctx.Err() != nil {
+            return false
+        }
+        f, err := os.Open(list)
+        if err != nil {
+            continue
+        }
+        sc := bufio.NewScanner(f)
+        for sc.Scan() {
+            if want[sc.Text()] {
+                f.Close()
+                return true
+            }
+        }
+        f.Close()
+    }
+    return false
+}
@@ -0,0 +1,65 @@
+package scarf
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestDetectInstallChannel(t *testing.T) {
+    root := t.TempDir()
+    t.Setenv("GOBIN", "")
+    t.Setenv("GOPATH", "/home/dev/go")
+
+    cases := map[string]string{
+        "/opt/homebrew/Cellar/tool/1.0/bin/tool": "homebrew",
+        "/home/linuxbrew/.linuxbrew/bin/tool":    "homebrew",
+        "/snap/tool/12/bin/tool":                 "snap",
+        "/nix/store/abc-tool-1.0/bin/tool":       "nix",
+        "/home/dev/go/bin/tool":                  "go_install",
+        "/usr/bin/tool":                          "",
+        "/home/dev/bin/tool":                     "",
+    }
+    for exe, want := range cases {
+        if got := detectInstallChannel(context.Background(), exe, root); got != want {
+            t.Errorf("detectInstallChannel(%q) = %q, want %q", exe, got, want)
+        }
+    }
+
+    info := filepath.Join(root, "var", "lib", "dpkg", "info")
+    if err := os.MkdirAll(info, 0o755); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(filepath.Join(info, "tool.list"), []byte("/.\n/bin\n/bin/tool\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if got := detectInstallChannel(context.Background(), "/usr/bin/tool", root); got != "apt" {
+        t.Fatalf("expected apt for a dpkg-owned binary, got %q", got)
+    }
+    canceled, cancel := context.WithCancel(context.Background())
+    cancel()
+    if got := detectInstallChannel(canceled, "/usr/bin/tool", root); got != "" {
+        t.Fatalf("expected the dpkg scan to stop once the context is done, got %q", got)
+    }
+
+    if err := os.WriteFile(filepath.Join(root, ".dockerenv"), nil, 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if got := detectInstallChannel(context.Background(), "/app/tool", root); got != "docker" {
+        t.Fatalf("expected docker, got %q", got)
+    }
+}
+
+func TestInstallChannelEnricher(t *testing.T) {
+    e := InstallChannelEnricher()
+    canceled, cancel := context.WithCancel(context.Background())
+    cancel()
+    // Whether or not detection has finished, a done context never blocks.
+    e.Enrich(canceled)
+
+    props := e.Enrich(context.Background())
+    if c := InstallChannel(); c != "" && props["install_channel"] != c {
+        t.Fatalf("expected install_channel %q, got %v", c, props)
+    }
+}
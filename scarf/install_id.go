@@ -0,0 +1,115 @@
+package scarf
+
+import (
+    "crypto/rand"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+
+    "github.com/scarf-sh/scarf-go/scarf/statedir"
+)
+
+// WithInstallID attaches the anonymous install ID (see InstallID) to every
+// event as the "install_id" property. Requires WithAppName or WithStateDir.
+// Events are still sent, without the property, if the ID cannot be read or
+// persisted.
+func WithInstallID() Option {
+    return func(s *ScarfEventLogger) {
+        s.attachInstallID = true
+    }
+}
+
+// InstallID returns a random identifier for this installation, generating it
+// on first use and persisting it in the user's state directory
+// (XDG_STATE_HOME, ~/Library/Application Support or %LocalAppData%, see the
+// statedir package) under scarf/<app name>/install_id. It requires
+// WithAppName or WithStateDir.
+//
+// When analytics are disabled or consent has not been granted, the ID is
+// never generated or read and an error is returned.
+func (s *ScarfEventLogger) InstallID() (string, error) {
+    if s == nil {
+        return "", ErrDisabled
+    }
+    if !s.Enabled() {
+        return "", ErrDisabled
+    }
+    if err := s.checkConsent(); err != nil {
+        return "", err
+    }
+    dir, err := s.appStateDir()
+    if err != nil {
+        return "", fmt.Errorf("scarf: install ID: %w", err)
+    }
+    if dir == "" {
+        return "", errors.New("scarf: install ID requires WithAppName or WithStateDir")
+    }
+    return s.installID.get(filepath.Join(dir, "install_id"))
+}
+
+// installIDCache loads or creates the install ID once per logger.
+type installIDCache struct {
+    mu sync.Mutex
+    id string
+}
+
+func (c *installIDCache) get(path string) (string, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.id != "" {
+        return c.id, nil
+    }
+
+    if data, err := os.ReadFile(path); err == nil {
+        if id := strings.TrimSpace(string(data)); id != "" {
+            c.id = id
+            return id, nil
+        }
+    } else if !errors.Is(err, os.ErrNotExist) {
+        return "", fmt.Errorf("scarf: read install ID: %w", err)
+    }
+
+    id := newRandomUUID()
+    if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return "", fmt.Errorf("scarf: persist install ID: %w", err)
+    }
+    if err := os.WriteFile(path, []byte(id+"\n"), 0o600); err != nil {
+        return "", fmt.Errorf("scarf: persist install ID: %w", err)
+    }
+    c.id = id
+    return id, nil
+}
+
+// newRandomUUID returns a version 4 (random) UUID.
+func newRandomUUID() string {
+    var u [16]byte
+    _, _ = rand.Read(u[:])
+    u[6] = (u[6] & 0x0f) | 0x40 // version 4
+    u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+    return formatUUID(u)
+}
+
+// WithStateDir keeps the logger's persistent state (install ID, first-run
+// marker, schedules and, unless WithConsentFile is set, the consent decision)
+// directly in dir instead of the per-app directory chosen by the statedir
+// package. It can stand in for WithAppName.
+func WithStateDir(dir string) Option {
+    return func(s *ScarfEventLogger) {
+        s.stateDir = strings.TrimSpace(dir)
+    }
+}
+
+// appStateDir returns the directory holding the logger's persistent state,
+// or "" if neither WithStateDir nor WithAppName is set.
+func (s *ScarfEventLogger) appStateDir() (string, error) {
+    if s.stateDir != "" {
+        return s.stateDir, nil
+    }
+    if s.appName == "" {
+        return "", nil
+    }
+    return statedir.Dir(s.appName)
+}
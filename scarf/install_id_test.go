@@ -0,0 +1,125 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestInstallID_Persisted(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    l := New("", WithAppName("mytool"))
+    id, err := l.InstallID()
+    if err != nil {
+        t.Fatalf("InstallID: %v", err)
+    }
+    if !strings.HasPrefix(id[14:], "4") || len(id) != 36 {
+        t.Fatalf("expected a v4 UUID, got %q", id)
+    }
+    again, err := New("", WithAppName("mytool")).InstallID()
+    if err != nil || again != id {
+        t.Fatalf("expected persisted id %q, got %q (err=%v)", id, again, err)
+    }
+    other, _ := New("", WithAppName("othertool")).InstallID()
+    if other == id {
+        t.Fatalf("install IDs must be namespaced per app")
+    }
+}
+
+func TestInstallID_NotGeneratedWhenDisabled(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+    t.Setenv("DO_NOT_TRACK", "1")
+
+    if _, err := New("", WithAppName("mytool")).InstallID(); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+    entries, _ := os.ReadDir(dir)
+    if len(entries) != 0 {
+        t.Fatalf("no state should be written when disabled, found %v", entries)
+    }
+}
+
+func TestInstallID_RequiresAppName(t *testing.T) {
+    if _, err := New("").InstallID(); err == nil {
+        t.Fatalf("expected error without app name")
+    }
+}
+
+func TestWithInstallID_Attached(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    var got Event
+    props := map[string]any{"event": "x"}
+    l := New("", WithAppName("mytool"), WithInstallID(), WithTransport(TransportFunc(func(_ context.Context, ev Event) error {
+        got = ev
+        return nil
+    })))
+    if err := l.LogEvent(props); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    want, _ := l.InstallID()
+    if got.Properties["install_id"] != want {
+        t.Fatalf("expected install_id=%q, got %v", want, got.Properties["install_id"])
+    }
+    if _, ok := props["install_id"]; ok {
+        t.Fatalf("caller's properties must not be modified")
+    }
+    data, err := os.ReadFile(filepath.Join(dir, "scarf", "mytool", "install_id"))
+    if err != nil || strings.TrimSpace(string(data)) != want {
+        t.Fatalf("expected id persisted on disk, got %q (err=%v)", data, err)
+    }
+}
+
+func TestWithStateDir(t *testing.T) {
+    dir := t.TempDir()
+    l := New("", WithStateDir(dir))
+    id, err := l.InstallID()
+    if err != nil {
+        t.Fatalf("InstallID: %v", err)
+    }
+    if data, _ := os.ReadFile(filepath.Join(dir, "install_id")); strings.TrimSpace(string(data)) != id {
+        t.Fatalf("expected the install ID in %s, got %q", dir, data)
+    }
+    if first, err := l.IsFirstRun(); err != nil || !first {
+        t.Fatalf("IsFirstRun: %v, %v", first, err)
+    }
+    if err := l.SetConsent(true); err != nil {
+        t.Fatalf("SetConsent: %v", err)
+    }
+    if _, err := os.Stat(filepath.Join(dir, "consent.json")); err != nil {
+        t.Fatalf("expected consent in the state directory: %v", err)
+    }
+}
+
+func TestStateDirEnv(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("SCARF_STATE_DIR", dir)
+
+    l := New("", WithAppName("mytool"))
+    id, err := l.InstallID()
+    if err != nil {
+        t.Fatalf("InstallID: %v", err)
+    }
+    if data, _ := os.ReadFile(filepath.Join(dir, "mytool", "install_id")); strings.TrimSpace(string(data)) != id {
+        t.Fatalf("expected SCARF_STATE_DIR to hold the install ID, got %q", data)
+    }
+    if err := l.SetConsent(false); err != nil {
+        t.Fatalf("SetConsent: %v", err)
+    }
+    if _, err := os.Stat(filepath.Join(dir, "mytool", "consent.json")); err != nil {
+        t.Fatalf("expected consent under SCARF_STATE_DIR: %v", err)
+    }
+}
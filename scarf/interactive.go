@@ -0,0 +1,46 @@
+package scarf
+
+import (
+    "context"
+    "os"
+    "sync"
+)
+
+// IsInteractive reports whether the process appears to be driven by a person
+// at a terminal: stdin and stdout are terminals, TERM isn't "dumb", and it
+// isn't running in CI. Use it to tell human CLI usage apart from scripts and
+// automation.
+func IsInteractive() bool {
+    return isTerminal(os.Stdin) && isTerminal(os.Stdout) && os.Getenv("TERM") != "dumb" && !IsCI()
+}
+
+// InteractivityEnricher returns an Enricher with "tty" (stdout is a
+// terminal), "interactive" (IsInteractive) and "ci" (IsCI), determined once,
+// so dashboards can separate human usage from scripted usage.
+func InteractivityEnricher() Enricher {
+    var once sync.Once
+    var props map[string]any
+    return EnricherFunc(func(context.Context) map[string]any {
+        once.Do(func() {
+            props = map[string]any{
+                "tty":         isTerminal(os.Stdout),
+                "interactive": IsInteractive(),
+                "ci":          IsCI(),
+            }
+        })
+        return props
+    })
+}
+
+// isTerminal reports whether f is a character device other than the null
+// device, which is as close as the standard library gets to isatty.
+func isTerminal(f *os.File) bool {
+    fi, err := f.Stat()
+    if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+        return false
+    }
+    if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(fi, null) {
+        return false
+    }
+    return true
+}
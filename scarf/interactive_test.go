@@ -0,0 +1,42 @@
+package scarf
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestIsTerminal(t *testing.T) {
+    f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+    if isTerminal(f) {
+        t.Fatalf("expected a regular file not to be a terminal")
+    }
+    null, err := os.Open(os.DevNull)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer null.Close()
+    if isTerminal(null) {
+        t.Fatalf("expected the null device not to be a terminal")
+    }
+}
+
+func TestInteractivityEnricher(t *testing.T) {
+    clearCIEnv(t)
+    t.Setenv("GITHUB_ACTIONS", "true")
+    if IsInteractive() {
+        t.Fatalf("expected CI not to be interactive")
+    }
+    props := InteractivityEnricher().Enrich(context.Background())
+    if props["ci"] != true || props["interactive"] != false {
+        t.Fatalf("unexpected interactivity properties %v", props)
+    }
+    if _, ok := props["tty"].(bool); !ok {
+        t.Fatalf("expected a tty property, got %v", props)
+    }
+}
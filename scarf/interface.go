@@ -0,0 +1,70 @@
+package scarf
+
+import (
+    "context"
+)
+
+// EventLogger is the interface implemented by ScarfEventLogger and
+// NoopLogger. Downstream code can depend on it and substitute a mock or the
+// no-op implementation in tests.
+type EventLogger interface {
+    LogEvent(properties map[string]any) error
+    LogEventContext(ctx context.Context, properties map[string]any) error
+    Enabled() bool
+    Flush(ctx context.Context) error
+    Close() error
+}
+
+var (
+    _ EventLogger = (*ScarfEventLogger)(nil)
+    _ EventLogger = NoopLogger{}
+)
+
+// NoopLogger is an EventLogger that discards every event and reports itself
+// as disabled. The zero value is ready to use.
+type NoopLogger struct{}
+
+// LogEvent discards the event and returns nil.
+func (NoopLogger) LogEvent(map[string]any) error { return nil }
+
+// LogEventContext discards the event and returns nil.
+func (NoopLogger) LogEventContext(context.Context, map[string]any) error { return nil }
+
+// LogEventAsync discards the event and returns a finished Receipt with a nil
+// error.
+func (NoopLogger) LogEventAsync(map[string]any) *Receipt {
+    r := &Receipt{done: make(chan struct{})}
+    r.finish(nil)
+    return r
+}
+
+// LogEventAsyncContext discards the event and returns a finished Receipt
+// with a nil error.
+func (n NoopLogger) LogEventAsyncContext(context.Context, map[string]any) *Receipt {
+    return n.LogEventAsync(nil)
+}
+
+// Enabled always returns false.
+func (NoopLogger) Enabled() bool { return false }
+
+// DisabledReason always returns "no-op logger".
+func (NoopLogger) DisabledReason() string { return "no-op logger" }
+
+// Ping always returns ErrDisabled.
+func (NoopLogger) Ping(context.Context) error { return ErrDisabled }
+
+// Flush returns nil immediately.
+func (NoopLogger) Flush(context.Context) error { return nil }
+
+// Close returns nil.
+func (NoopLogger) Close() error { return nil }
+
+// NewNoopLogger returns a *ScarfEventLogger that accepts every call but never
+// sends anything: its Log methods return nil and Enabled reports false.
+// Libraries that hold a *ScarfEventLogger can default to it when telemetry is
+// optional instead of scattering nil checks.
+func NewNoopLogger() *ScarfEventLogger {
+    s := New("")
+    s.noop = true
+    return s
+}
@@ -0,0 +1,18 @@
+//go:build scarf_disabled
+
+package scarf
+
+// buildDisabled reports whether the SDK was built with the scarf_disabled
+// tag, for distributions that must ship telemetry-free binaries:
+//
+//   go build -tags scarf_disabled ./...
+//
+// Every logger then behaves like NewNoopLogger, whatever its options, and
+// nothing can turn it back on. The public API is unchanged so applications
+// build as usual, and because buildDisabled is a constant the compiler drops
+// the SDK's code paths that build or send requests, dial sockets or probe a
+// metadata server. The net and net/http packages are still linked, for the
+// types in the API, and their package initialization keeps the default HTTP
+// transport's dialer in the binary, though nothing calls it; CI checks that
+// no other network packages are linked in.
+const buildDisabled = true
@@ -0,0 +1,35 @@
+//go:build scarf_disabled
+
+package scarf
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+)
+
+// Run with: go test -tags scarf_disabled -run TestBuildDisabled ./scarf
+func TestBuildDisabled(t *testing.T) {
+    var requests atomic.Int64
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests.Add(1)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithEnabled(true))
+    l.SetEnabled(true)
+    if l.Enabled() || l.DisabledReason() != "built with the scarf_disabled tag" {
+        t.Fatalf("expected the build tag to disable the logger, reason %q", l.DisabledReason())
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("expected a silent no-op, got %v", err)
+    }
+    if err := l.LogEvents(context.Background(), []Event{{Name: "x"}}); err != nil {
+        t.Fatalf("expected a silent no-op, got %v", err)
+    }
+    if n := requests.Load(); n != 0 {
+        t.Fatalf("expected no requests, got %d", n)
+    }
+}
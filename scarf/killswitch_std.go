@@ -0,0 +1,7 @@
+//go:build !scarf_disabled
+
+package scarf
+
+// buildDisabled reports whether the SDK was built with the scarf_disabled
+// tag; see killswitch_disabled.go.
+const buildDisabled = false
@@ -0,0 +1,134 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "sync"
+)
+
+// ErrClosed is returned for events logged after Close.
+var ErrClosed = errors.New("scarf: logger closed")
+
+// Flush sends any aggregated metrics and queued events, then blocks until all
+// sends in progress have completed, or ctx is done.
+func (s *ScarfEventLogger) Flush(ctx context.Context) error {
+    if s == nil {
+        return nil
+    }
+    s.flushMetrics(ctx)
+    s.flushQueue(ctx)
+    return s.inflight.wait(ctx)
+}
+
+// Close ends the current session and sends any aggregated metrics and queued
+// events, then stops the logger from accepting new events and waits for sends
+// in progress to complete. Events logged after Close return ErrClosed.
+// Calling Close more than once is safe.
+func (s *ScarfEventLogger) Close() error {
+    if s == nil {
+        return nil
+    }
+    return s.closeContext(context.Background())
+}
+
+// closeContext is Close with the wait bounded by ctx.
+func (s *ScarfEventLogger) closeContext(ctx context.Context) error {
+    if !s.closed.Load() {
+        if err := s.EndSession(); err != nil {
+            s.debug("session end not sent", "error", err)
+        }
+        s.flushMetrics(ctx)
+        s.flushQueue(ctx)
+    }
+    s.closed.Store(true)
+    s.closeOnce.Do(func() { close(s.done) })
+    // Events queued while closing fail with ErrClosed rather than waiting
+    // for the batch timer.
+    s.flushQueue(ctx)
+    return s.inflight.wait(ctx)
+}
+
+// WithMaxPending limits the number of sends in progress at once, e.g. to
+// bound the goroutines left waiting on a slow endpoint by middleware that
+// sends in the background. Beyond the limit, events fail immediately with
+// ErrQueueFull. The default, 0, means no limit.
+func WithMaxPending(n int) Option {
+    return func(s *ScarfEventLogger) {
+        s.maxPending = n
+    }
+}
+
+// startSend counts a send as in progress, or reports ErrQueueFull if the
+// WithMaxPending limit has been reached.
+func (s *ScarfEventLogger) startSend() error {
+    if !s.inflight.tryAdd(s.maxPending) {
+        s.warn("too many sends in progress; dropping event", "max", s.maxPending)
+        s.notifyDrop(DropQueueFull)
+        return ErrQueueFull
+    }
+    return nil
+}
+
+// Pending returns the number of sends in progress, including events waiting
+// on the network and batches being uploaded.
+func (s *ScarfEventLogger) Pending() int {
+    if s == nil {
+        return 0
+    }
+    return s.inflight.count()
+}
+
+// inflightTracker counts sends in progress and lets callers wait until there
+// are none. Unlike sync.WaitGroup, waiting may overlap with new sends starting.
+type inflightTracker struct {
+    mu   sync.Mutex
+    n    int
+    idle chan struct{} // closed when n drops to 0; made only once someone waits
+}
+
+// tryAdd starts a send unless max (if positive) sends are in progress.
+func (t *inflightTracker) tryAdd(max int) bool {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if max > 0 && t.n >= max {
+        return false
+    }
+    t.n++
+    return true
+}
+
+func (t *inflightTracker) done() {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.n--
+    if t.n == 0 && t.idle != nil {
+        close(t.idle)
+        t.idle = nil
+    }
+}
+
+func (t *inflightTracker) count() int {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return t.n
+}
+
+func (t *inflightTracker) wait(ctx context.Context) error {
+    t.mu.Lock()
+    if t.n == 0 {
+        t.mu.Unlock()
+        return nil
+    }
+    if t.idle == nil {
+        t.idle = make(chan struct{})
+    }
+    idle := t.idle
+    t.mu.Unlock()
+
+    select {
+    case <-idle:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
@@ -0,0 +1,100 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestFlushWaitsForInflight(t *testing.T) {
+    release := make(chan struct{})
+    started := make(chan struct{})
+    l := New("", WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        close(started)
+        <-release
+        return nil
+    })))
+
+    go func() { _ = l.LogEvent(map[string]any{"event": "slow"}) }()
+    <-started
+    if n := l.Pending(); n != 1 {
+        t.Fatalf("Pending() = %d while a send is in flight, want 1", n)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    if err := l.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+        t.Fatalf("expected Flush to time out while a send is in flight, got %v", err)
+    }
+
+    close(release)
+    if err := l.Flush(context.Background()); err != nil {
+        t.Fatalf("expected Flush to succeed, got %v", err)
+    }
+    if n := l.Pending(); n != 0 {
+        t.Fatalf("Pending() = %d after Flush, want 0", n)
+    }
+}
+
+func TestCloseRejectsNewEvents(t *testing.T) {
+    l := New("", WithTransport(TransportFunc(func(context.Context, Event) error { return nil })))
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if err := l.Close(); err != nil {
+        t.Fatalf("second Close: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "late"}); !errors.Is(err, ErrClosed) {
+        t.Fatalf("expected ErrClosed, got %v", err)
+    }
+}
+
+func TestLogEventContext_Canceled(t *testing.T) {
+    l := New("", WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        return ctx.Err()
+    })))
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if err := l.LogEventContext(ctx, map[string]any{"event": "x"}); !errors.Is(err, context.Canceled) {
+        t.Fatalf("expected context.Canceled, got %v", err)
+    }
+}
+
+func TestNoopLogger(t *testing.T) {
+    var l EventLogger = NoopLogger{}
+    if l.Enabled() {
+        t.Fatalf("NoopLogger should report disabled")
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if err := l.LogEventContext(context.Background(), nil); err != nil {
+        t.Fatalf("LogEventContext: %v", err)
+    }
+    if err := l.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+}
+
+func TestNewNoopLogger(t *testing.T) {
+    l := NewNoopLogger()
+    if l.Enabled() {
+        t.Fatalf("noop logger should report disabled")
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if err := l.LogEventStruct(Event{Name: "x"}); err != nil {
+        t.Fatalf("LogEventStruct: %v", err)
+    }
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if err := l.LogEventWithTimeout(map[string]any{"event": "x"}, time.Second); err != nil {
+        t.Fatalf("noop logger should stay silent after Close, got %v", err)
+    }
+}
@@ -0,0 +1,75 @@
+package scarf
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+)
+
+// LocaleEnricher returns an Enricher describing where users are, to help
+// prioritize localization:
+//
+//   - locale: user locale from LC_ALL, LC_MESSAGES or LANG (e.g. "en_US")
+//   - language: the locale's language (e.g. "en")
+//   - timezone: IANA zone name from TZ or /etc/localtime (e.g. "Europe/Berlin")
+//   - tz_offset: current UTC offset, as "+02:00"
+//
+// The offset is read on each event so it follows daylight saving time; the
+// rest is determined once. Unknown values are omitted.
+func LocaleEnricher() Enricher {
+    var once sync.Once
+    var base map[string]any
+    return EnricherFunc(func(context.Context) map[string]any {
+        once.Do(func() {
+            base = map[string]any{}
+            if l := detectLocale(); l != "" {
+                base["locale"] = l
+                lang, _, _ := strings.Cut(l, "_")
+                base["language"] = lang
+            }
+            if tz := timezoneName(); tz != "" {
+                base["timezone"] = tz
+            }
+        })
+        props := make(map[string]any, len(base)+1)
+        for k, v := range base {
+            props[k] = v
+        }
+        _, offset := time.Now().Zone()
+        props["tz_offset"] = formatUTCOffset(offset)
+        return props
+    })
+}
+
+// timezoneName returns the local IANA time zone name, from TZ or the target
+// of the /etc/localtime symlink.
+func timezoneName() string {
+    if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" && !filepath.IsAbs(tz) {
+        return tz
+    }
+    if name := time.Local.String(); name != "Local" && name != "" {
+        return name
+    }
+    target, err := os.Readlink("/etc/localtime")
+    if err != nil {
+        return ""
+    }
+    if _, name, ok := strings.Cut(filepath.ToSlash(target), "zoneinfo/"); ok {
+        return name
+    }
+    return ""
+}
+
+// formatUTCOffset formats an offset in seconds east of UTC as "+hh:mm".
+func formatUTCOffset(seconds int) string {
+    sign := '+'
+    if seconds < 0 {
+        sign = '-'
+        seconds = -seconds
+    }
+    return fmt.Sprintf("%c%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
@@ -0,0 +1,30 @@
+package scarf
+
+import (
+    "context"
+    "testing"
+)
+
+func TestFormatUTCOffset(t *testing.T) {
+    cases := map[int]string{0: "+00:00", 7200: "+02:00", -16200: "-04:30", 19800: "+05:30"}
+    for in, want := range cases {
+        if got := formatUTCOffset(in); got != want {
+            t.Errorf("formatUTCOffset(%d) = %q, want %q", in, got, want)
+        }
+    }
+}
+
+func TestLocaleEnricher(t *testing.T) {
+    t.Setenv("LC_ALL", "")
+    t.Setenv("LC_MESSAGES", "")
+    t.Setenv("LANG", "de_DE.UTF-8")
+    t.Setenv("TZ", "Europe/Berlin")
+
+    props := LocaleEnricher().Enrich(context.Background())
+    if props["locale"] != "de_DE" || props["language"] != "de" || props["timezone"] != "Europe/Berlin" {
+        t.Fatalf("unexpected locale properties %v", props)
+    }
+    if off, _ := props["tz_offset"].(string); len(off) != 6 {
+        t.Fatalf("expected a +hh:mm offset, got %v", props["tz_offset"])
+    }
+}
@@ -0,0 +1,168 @@
+package scarf
+
+import (
+    "fmt"
+    "net/url"
+    "strings"
+)
+
+// LogLevel controls how much of the SDK's diagnostics reach the Logger. Each
+// level includes the ones before it.
+type LogLevel int
+
+const (
+    LogOff   LogLevel = iota // no output (the default)
+    LogError                 // events that could not be sent at all
+    LogWarn                  // failed deliveries and degraded behavior
+    LogInfo                  // lifecycle messages
+    LogDebug                 // every send and skip, with property values redacted
+    LogTrace                 // as LogDebug, with full request URLs
+)
+
+var logLevelNames = [...]string{"off", "error", "warn", "info", "debug", "trace"}
+
+func (l LogLevel) String() string {
+    if l < LogOff || l > LogTrace {
+        return fmt.Sprintf("LogLevel(%d)", int(l))
+    }
+    return logLevelNames[l]
+}
+
+// ParseLogLevel parses a level name ("off", "error", "warn", "info", "debug",
+// "trace"). For compatibility with the old on/off SCARF_VERBOSE, boolean
+// values are accepted too: "1", "true", "yes" and "on" mean LogDebug; "0",
+// "false" and "no" mean LogOff.
+func ParseLogLevel(s string) (LogLevel, error) {
+    v := strings.ToLower(strings.TrimSpace(s))
+    for i, name := range logLevelNames {
+        if v == name {
+            return LogLevel(i), nil
+        }
+    }
+    switch v {
+    case "1", "true", "yes", "on":
+        return LogDebug, nil
+    case "", "0", "false", "no":
+        return LogOff, nil
+    }
+    return LogOff, fmt.Errorf("scarf: unknown log level %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l LogLevel) MarshalText() ([]byte, error) {
+    return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *LogLevel) UnmarshalText(b []byte) error {
+    v, err := ParseLogLevel(string(b))
+    if err != nil {
+        return err
+    }
+    *l = v
+    return nil
+}
+
+// WithLogLevel sets the verbosity of diagnostics, overriding SCARF_VERBOSE.
+// Below LogTrace, query parameter values are redacted from logged URLs so
+// event properties don't end up in the host application's logs.
+func WithLogLevel(level LogLevel) Option {
+    return func(s *ScarfEventLogger) {
+        s.logLevel = level
+        s.mark("log_level")
+    }
+}
+
+// Logger receives the SDK's internal diagnostics. *slog.Logger satisfies it,
+// so verbose output can be routed into the host application's structured
+// logging:
+//
+//   logger := scarf.New(endpoint, scarf.WithLogger(slog.Default()), scarf.WithLogLevel(scarf.LogDebug))
+//
+// args are alternating key/value pairs, as for slog. LogTrace messages are
+// passed to Debug.
+type Logger interface {
+    Debug(msg string, args ...any)
+    Info(msg string, args ...any)
+    Warn(msg string, args ...any)
+    Error(msg string, args ...any)
+}
+
+// WithLogger sets the destination for verbose output (stderr by default).
+// Messages are only emitted at or below the configured LogLevel.
+func WithLogger(l Logger) Option {
+    return func(s *ScarfEventLogger) {
+        if l != nil {
+            s.logger = l
+        }
+    }
+}
+
+func (s *ScarfEventLogger) trace(msg string, args ...any) {
+    if s.logLevel >= LogTrace {
+        s.logger.Debug(msg, args...)
+    }
+}
+
+func (s *ScarfEventLogger) debug(msg string, args ...any) {
+    if s.logLevel >= LogDebug {
+        s.logger.Debug(msg, args...)
+    }
+}
+
+func (s *ScarfEventLogger) info(msg string, args ...any) {
+    if s.logLevel >= LogInfo {
+        s.logger.Info(msg, args...)
+    }
+}
+
+func (s *ScarfEventLogger) warn(msg string, args ...any) {
+    if s.logLevel >= LogWarn {
+        s.logger.Warn(msg, args...)
+    }
+}
+
+func (s *ScarfEventLogger) error(msg string, args ...any) {
+    if s.logLevel >= LogError {
+        s.logger.Error(msg, args...)
+    }
+}
+
+// logURL returns rawURL for logging: unchanged at LogTrace, otherwise with
+// every query parameter value replaced, since those carry event properties.
+func (s *ScarfEventLogger) logURL(rawURL string) string {
+    if s.logLevel >= LogTrace {
+        return rawURL
+    }
+    u, err := url.Parse(rawURL)
+    if err != nil {
+        return "(invalid URL)"
+    }
+    if u.RawQuery == "" {
+        return rawURL
+    }
+    q := u.Query()
+    for k := range q {
+        q[k] = []string{"REDACTED"}
+    }
+    u.RawQuery = q.Encode()
+    return u.String()
+}
+
+// formatLogLine renders a level, message and key/value pairs as
+// "LEVEL msg key=value ...".
+func formatLogLine(level, msg string, args []any) string {
+    var b strings.Builder
+    b.WriteString(level)
+    b.WriteByte(' ')
+    b.WriteString(msg)
+    for i := 0; i < len(args); i += 2 {
+        b.WriteByte(' ')
+        if i+1 < len(args) {
+            fmt.Fprintf(&b, "%v=%v", args[i], args[i+1])
+        } else {
+            fmt.Fprintf(&b, "%v", args[i])
+        }
+    }
+    return b.String()
+}
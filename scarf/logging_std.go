@@ -0,0 +1,32 @@
+//go:build !tinygo
+
+package scarf
+
+import (
+    "log"
+    "os"
+)
+
+// newDefaultLogger returns the stderr logger used unless WithLogger is given.
+func newDefaultLogger() Logger {
+    return newStdLogger(log.New(os.Stderr, "[scarf] ", log.LstdFlags))
+}
+
+// stdLogger adapts a *log.Logger to Logger, rendering key/value pairs as
+// "key=value".
+type stdLogger struct {
+    l *log.Logger
+}
+
+func newStdLogger(l *log.Logger) stdLogger {
+    return stdLogger{l: l}
+}
+
+func (s stdLogger) Debug(msg string, args ...any) { s.print("DEBUG", msg, args) }
+func (s stdLogger) Info(msg string, args ...any)  { s.print("INFO", msg, args) }
+func (s stdLogger) Warn(msg string, args ...any)  { s.print("WARN", msg, args) }
+func (s stdLogger) Error(msg string, args ...any) { s.print("ERROR", msg, args) }
+
+func (s stdLogger) print(level, msg string, args []any) {
+    s.l.Println(formatLogLine(level, msg, args))
+}
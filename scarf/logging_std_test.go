@@ -0,0 +1,17 @@
+//go:build !tinygo
+
+package scarf
+
+import (
+    "bytes"
+    "log"
+    "testing"
+)
+
+func TestStdLogger_Format(t *testing.T) {
+    var buf bytes.Buffer
+    newStdLogger(log.New(&buf, "[scarf] ", 0)).Warn("request failed", "status", 500, "dangling")
+    if got := buf.String(); got != "[scarf] WARN request failed status=500 dangling\n" {
+        t.Fatalf("unexpected output %q", got)
+    }
+}
@@ -0,0 +1,84 @@
+package scarf
+
+import (
+    "bytes"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestWithLogger_Slog(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusBadGateway)
+    }))
+    defer srv.Close()
+
+    var buf bytes.Buffer
+    sl := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+    l := New(srv.URL, WithLogger(sl), WithVerbose(true))
+    _ = l.LogEvent(map[string]any{"event": "x"})
+
+    out := buf.String()
+    if !strings.Contains(out, `"level":"DEBUG","msg":"sending event"`) {
+        t.Fatalf("expected debug record for send, got:\n%s", out)
+    }
+    if !strings.Contains(out, `"level":"WARN","msg":"non-success status","status":"502 Bad Gateway"`) {
+        t.Fatalf("expected warn record with status attribute, got:\n%s", out)
+    }
+}
+
+func TestWithLogger_QuietUnlessVerbose(t *testing.T) {
+    var buf bytes.Buffer
+    l := New("", WithLogger(slog.New(slog.NewTextHandler(&buf, nil))), WithVerbose(false))
+    _ = l.LogEvent(map[string]any{"event": "x"})
+    if buf.Len() != 0 {
+        t.Fatalf("expected no output without verbose, got %q", buf.String())
+    }
+}
+
+func TestParseLogLevel(t *testing.T) {
+    cases := map[string]LogLevel{"": LogOff, "0": LogOff, "off": LogOff, "Warn": LogWarn, " trace ": LogTrace, "1": LogDebug, "true": LogDebug}
+    for in, want := range cases {
+        if got, err := ParseLogLevel(in); err != nil || got != want {
+            t.Errorf("ParseLogLevel(%q) = %s, %v; want %s", in, got, err, want)
+        }
+    }
+    if _, err := ParseLogLevel("loud"); err == nil {
+        t.Errorf("expected an error for an unknown level")
+    }
+}
+
+func TestWithLogLevel(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusBadGateway)
+    }))
+    defer srv.Close()
+
+    output := func(level LogLevel) string {
+        var buf bytes.Buffer
+        sl := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+        _ = New(srv.URL+"/e?pkg=abc", WithLogger(sl), WithLogLevel(level)).LogEvent(map[string]any{"event": "x", "email": "a@example.com"})
+        return buf.String()
+    }
+
+    if out := output(LogWarn); strings.Contains(out, "level=DEBUG") || !strings.Contains(out, "non-success status") {
+        t.Fatalf("expected only warnings at LogWarn, got:\n%s", out)
+    }
+    if out := output(LogDebug); !strings.Contains(out, "sending event") || strings.Contains(out, "example.com") || strings.Contains(out, "abc") {
+        t.Fatalf("expected property values to be redacted at LogDebug, got:\n%s", out)
+    }
+    if out := output(LogTrace); !strings.Contains(out, "email=a%40example.com") {
+        t.Fatalf("expected full URLs at LogTrace, got:\n%s", out)
+    }
+}
+
+func TestNetworkErrorOmitsQuery(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+    srv.Close()
+    err := New(srv.URL).LogEvent(map[string]any{"event": "x", "email": "a@example.com"})
+    if err == nil || strings.Contains(err.Error(), "example.com") {
+        t.Fatalf("expected an error without property values, got %v", err)
+    }
+}
@@ -0,0 +1,28 @@
+//go:build tinygo
+
+package scarf
+
+import (
+    "io"
+    "os"
+)
+
+// newDefaultLogger returns the stderr logger used unless WithLogger is given.
+// Under TinyGo it writes directly to stderr rather than pulling in package log.
+func newDefaultLogger() Logger {
+    return writerLogger{w: os.Stderr}
+}
+
+// writerLogger writes "[scarf] LEVEL msg key=value" lines to w.
+type writerLogger struct {
+    w io.Writer
+}
+
+func (l writerLogger) Debug(msg string, args ...any) { l.print("DEBUG", msg, args) }
+func (l writerLogger) Info(msg string, args ...any)  { l.print("INFO", msg, args) }
+func (l writerLogger) Warn(msg string, args ...any)  { l.print("WARN", msg, args) }
+func (l writerLogger) Error(msg string, args ...any) { l.print("ERROR", msg, args) }
+
+func (l writerLogger) print(level, msg string, args []any) {
+    _, _ = io.WriteString(l.w, "[scarf] "+formatLogLine(level, msg, args)+"\n")
+}
@@ -0,0 +1,126 @@
+package scarf
+
+import (
+    "context"
+    "sort"
+    "sync"
+    "time"
+)
+
+// defaultMetricsInterval is how often aggregated metrics are sent by default.
+const defaultMetricsInterval = time.Minute
+
+// WithMetricsInterval sets how often values recorded with Count and Gauge are
+// sent. The default is one minute.
+func WithMetricsInterval(d time.Duration) Option {
+    return func(s *ScarfEventLogger) {
+        if d > 0 {
+            s.metricsInterval = d
+        }
+    }
+}
+
+// Count adds delta to the counter name. Counters are accumulated locally and
+// sent as one event per counter every metrics interval, so frequent
+// increments don't each cost a request. The event is named name and carries
+// metric_type "counter" and the summed value.
+func (s *ScarfEventLogger) Count(name string, delta int64) {
+    if s == nil {
+        return
+    }
+    if !s.wouldSend() {
+        return
+    }
+    s.metrics.count(name, delta)
+    s.startMetrics()
+}
+
+// Gauge records value as the current value of the gauge name. Like counters,
+// gauges are sent once per metrics interval, with metric_type "gauge" and the
+// most recently recorded value.
+func (s *ScarfEventLogger) Gauge(name string, value float64) {
+    if s == nil {
+        return
+    }
+    if !s.wouldSend() {
+        return
+    }
+    s.metrics.gauge(name, value)
+    s.startMetrics()
+}
+
+// startMetrics starts the background flush loop on first use.
+func (s *ScarfEventLogger) startMetrics() {
+    s.metricsOnce.Do(func() {
+        interval := s.metricsInterval
+        if interval <= 0 {
+            interval = defaultMetricsInterval
+        }
+        go func() {
+            timer := s.clock.NewTimer(interval)
+            defer timer.Stop()
+            for {
+                select {
+                case <-timer.C():
+                    timer.Reset(interval)
+                    s.flushMetrics(context.Background())
+                case <-s.done:
+                    return
+                }
+            }
+        }()
+    })
+}
+
+// flushMetrics sends and resets all aggregated values.
+func (s *ScarfEventLogger) flushMetrics(ctx context.Context) {
+    for _, ev := range s.metrics.drain() {
+        if err := s.logEventInternal(ctx, ev, s.defaultTimeout); err != nil {
+            s.debug("metric not sent", "metric", ev.Name, "error", err)
+        }
+    }
+}
+
+// aggregator accumulates counter and gauge values between flushes.
+type aggregator struct {
+    mu       sync.Mutex
+    counters map[string]int64
+    gauges   map[string]float64
+}
+
+func (a *aggregator) count(name string, delta int64) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.counters == nil {
+        a.counters = make(map[string]int64)
+    }
+    a.counters[name] += delta
+}
+
+func (a *aggregator) gauge(name string, value float64) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.gauges == nil {
+        a.gauges = make(map[string]float64)
+    }
+    a.gauges[name] = value
+}
+
+// drain returns one event per recorded metric, sorted by name, and resets the
+// aggregator.
+func (a *aggregator) drain() []Event {
+    a.mu.Lock()
+    counters, gauges := a.counters, a.gauges
+    a.counters, a.gauges = nil, nil
+    a.mu.Unlock()
+
+    events := make([]Event, 0, len(counters)+len(gauges))
+    for name, v := range counters {
+        events = append(events, Event{Name: name, Properties: map[string]any{"metric_type": "counter", "value": v}})
+    }
+    for name, v := range gauges {
+        events = append(events, Event{Name: name, Properties: map[string]any{"metric_type": "gauge", "value": v}})
+    }
+    sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+    return events
+}
@@ -0,0 +1,70 @@
+package scarf
+
+import (
+    "context"
+    "testing"
+    "time"
+)
+
+func TestCountAndGauge_AggregateUntilFlush(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithMetricsInterval(time.Hour))
+    for i := 0; i < 5; i++ {
+        l.Count("cache_hit", 1)
+    }
+    l.Gauge("open_conns", 3)
+    l.Gauge("open_conns", 12)
+    if len(got) != 0 {
+        t.Fatalf("expected nothing sent before flush, got %d events", len(got))
+    }
+
+    if err := l.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if len(got) != 2 {
+        t.Fatalf("expected 2 aggregated events, got %d", len(got))
+    }
+    if got[0].Name != "cache_hit" || got[0].Properties["metric_type"] != "counter" || got[0].Properties["value"] != int64(5) {
+        t.Fatalf("unexpected counter event: %+v", got[0])
+    }
+    if got[1].Name != "open_conns" || got[1].Properties["metric_type"] != "gauge" || got[1].Properties["value"] != 12.0 {
+        t.Fatalf("unexpected gauge event: %+v", got[1])
+    }
+
+    if err := l.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if len(got) != 2 {
+        t.Fatalf("expected values to reset after flush, got %d events", len(got))
+    }
+}
+
+func TestCount_FlushedOnIntervalAndClose(t *testing.T) {
+    events := make(chan Event, 16)
+    l := New("", WithMetricsInterval(5*time.Millisecond), WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        events <- ev
+        return nil
+    })))
+    l.Count("jobs", 2)
+    select {
+    case ev := <-events:
+        if ev.Name != "jobs" || ev.Properties["value"] != int64(2) {
+            t.Fatalf("unexpected event: %+v", ev)
+        }
+    case <-time.After(time.Second):
+        t.Fatalf("metrics not flushed on interval")
+    }
+
+    l.Count("jobs", 1)
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    select {
+    case ev := <-events:
+        if ev.Properties["value"] != int64(1) {
+            t.Fatalf("unexpected event: %+v", ev)
+        }
+    default:
+        t.Fatalf("expected Close to flush pending metrics")
+    }
+}
@@ -0,0 +1,203 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/json"
+    "math"
+    "sort"
+    "time"
+)
+
+// msgpackContentType is the Content-Type of MessagePack-encoded requests.
+const msgpackContentType = "application/msgpack"
+
+// msgpackBody encodes the event as a MessagePack map with the same fields as
+// jsonBody.
+func (e Event) msgpackBody() ([]byte, error) {
+    fields := make(map[string]any, len(e.Properties)+3)
+    for k, v := range e.Properties {
+        fields[k] = CanonicalValue(v)
+    }
+    if e.Name != "" {
+        fields["event"] = e.Name
+    }
+    if !e.Timestamp.IsZero() {
+        fields["timestamp"] = e.Timestamp.UTC().Format(time.RFC3339Nano)
+    }
+    if e.ID != "" {
+        fields["event_id"] = e.ID
+    }
+    return appendMsgpack(nil, fields)
+}
+
+// appendMsgpack appends the MessagePack encoding of v to b. Common types are
+// encoded directly; anything else is first converted through encoding/json,
+// so json tags and Marshalers apply as they do for JSON bodies.
+func appendMsgpack(b []byte, v any) ([]byte, error) {
+    switch vv := v.(type) {
+    case nil:
+        return append(b, 0xc0), nil
+    case bool:
+        if vv {
+            return append(b, 0xc3), nil
+        }
+        return append(b, 0xc2), nil
+    case string:
+        return appendMsgpackString(b, vv), nil
+    case []byte:
+        return appendMsgpackBin(b, vv), nil
+    case int:
+        return appendMsgpackInt(b, int64(vv)), nil
+    case int8:
+        return appendMsgpackInt(b, int64(vv)), nil
+    case int16:
+        return appendMsgpackInt(b, int64(vv)), nil
+    case int32:
+        return appendMsgpackInt(b, int64(vv)), nil
+    case int64:
+        return appendMsgpackInt(b, vv), nil
+    case uint:
+        return appendMsgpackUint(b, uint64(vv)), nil
+    case uint8:
+        return appendMsgpackUint(b, uint64(vv)), nil
+    case uint16:
+        return appendMsgpackUint(b, uint64(vv)), nil
+    case uint32:
+        return appendMsgpackUint(b, uint64(vv)), nil
+    case uint64:
+        return appendMsgpackUint(b, vv), nil
+    case float32:
+        b = append(b, 0xca)
+        return binary.BigEndian.AppendUint32(b, math.Float32bits(vv)), nil
+    case float64:
+        b = append(b, 0xcb)
+        return binary.BigEndian.AppendUint64(b, math.Float64bits(vv)), nil
+    case json.Number:
+        if i, err := vv.Int64(); err == nil {
+            return appendMsgpackInt(b, i), nil
+        }
+        f, err := vv.Float64()
+        if err != nil {
+            return nil, err
+        }
+        return appendMsgpack(b, f)
+    case []any:
+        b = appendMsgpackHeader(b, len(vv), 0x90, 0xdc)
+        for _, e := range vv {
+            var err error
+            if b, err = appendMsgpack(b, e); err != nil {
+                return nil, err
+            }
+        }
+        return b, nil
+    case map[string]any:
+        keys := make([]string, 0, len(vv))
+        for k := range vv {
+            keys = append(keys, k)
+        }
+        sort.Strings(keys)
+        b = appendMsgpackHeader(b, len(vv), 0x80, 0xde)
+        for _, k := range keys {
+            b = appendMsgpackString(b, k)
+            var err error
+            if b, err = appendMsgpack(b, vv[k]); err != nil {
+                return nil, err
+            }
+        }
+        return b, nil
+    }
+
+    raw, err := json.Marshal(v)
+    if err != nil {
+        return nil, err
+    }
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.UseNumber()
+    var generic any
+    if err := dec.Decode(&generic); err != nil {
+        return nil, err
+    }
+    return appendMsgpack(b, generic)
+}
+
+func appendMsgpackHeader(b []byte, n int, fix, code16 byte) []byte {
+    switch {
+    case n < 16:
+        return append(b, fix|byte(n))
+    case n <= math.MaxUint16:
+        b = append(b, code16)
+        return binary.BigEndian.AppendUint16(b, uint16(n))
+    default:
+        b = append(b, code16+1)
+        return binary.BigEndian.AppendUint32(b, uint32(n))
+    }
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+    switch n := len(s); {
+    case n < 32:
+        b = append(b, 0xa0|byte(n))
+    case n <= math.MaxUint8:
+        b = append(b, 0xd9, byte(n))
+    case n <= math.MaxUint16:
+        b = append(b, 0xda)
+        b = binary.BigEndian.AppendUint16(b, uint16(n))
+    default:
+        b = append(b, 0xdb)
+        b = binary.BigEndian.AppendUint32(b, uint32(n))
+    }
+    return append(b, s...)
+}
+
+func appendMsgpackBin(b []byte, p []byte) []byte {
+    switch n := len(p); {
+    case n <= math.MaxUint8:
+        b = append(b, 0xc4, byte(n))
+    case n <= math.MaxUint16:
+        b = append(b, 0xc5)
+        b = binary.BigEndian.AppendUint16(b, uint16(n))
+    default:
+        b = append(b, 0xc6)
+        b = binary.BigEndian.AppendUint32(b, uint32(n))
+    }
+    return append(b, p...)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+    switch {
+    case i >= 0:
+        return appendMsgpackUint(b, uint64(i))
+    case i >= -32:
+        return append(b, byte(i))
+    case i >= math.MinInt8:
+        return append(b, 0xd0, byte(i))
+    case i >= math.MinInt16:
+        b = append(b, 0xd1)
+        return binary.BigEndian.AppendUint16(b, uint16(i))
+    case i >= math.MinInt32:
+        b = append(b, 0xd2)
+        return binary.BigEndian.AppendUint32(b, uint32(i))
+    default:
+        b = append(b, 0xd3)
+        return binary.BigEndian.AppendUint64(b, uint64(i))
+    }
+}
+
+func appendMsgpackUint(b []byte, u uint64) []byte {
+    switch {
+    case u < 128:
+        return append(b, byte(u))
+    case u <= math.MaxUint8:
+        return append(b, 0xcc, byte(u))
+    case u <= math.MaxUint16:
+        b = append(b, 0xcd)
+        return binary.BigEndian.AppendUint16(b, uint16(u))
+    case u <= math.MaxUint32:
+        b = append(b, 0xce)
+        return binary.BigEndian.AppendUint32(b, uint32(u))
+    default:
+        b = append(b, 0xcf)
+        return binary.BigEndian.AppendUint64(b, u)
+    }
+}
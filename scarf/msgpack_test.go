@@ -0,0 +1,81 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/hex"
+    "math"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestAppendMsgpack(t *testing.T) {
+    type level int
+    cases := []struct {
+        in   any
+        want string
+    }{
+        {nil, "c0"},
+        {true, "c3"},
+        {false, "c2"},
+        {0, "00"},
+        {127, "7f"},
+        {128, "cc80"},
+        {256, "cd0100"},
+        {70000, "ce00011170"},
+        {int64(math.MaxInt64), "cf7fffffffffffffff"},
+        {uint64(math.MaxUint64), "cfffffffffffffffff"},
+        {-1, "ff"},
+        {-32, "e0"},
+        {-33, "d0df"},
+        {-200, "d1ff38"},
+        {-70000, "d2fffeee90"},
+        {int64(math.MinInt64), "d38000000000000000"},
+        {1.5, "cb3ff8000000000000"},
+        {float32(1.5), "ca3fc00000"},
+        {"", "a0"},
+        {"hi", "a2" + "6869"},
+        {strings.Repeat("a", 32), "d920" + strings.Repeat("61", 32)},
+        {[]byte{1, 2}, "c4020102"},
+        {[]any{1, "a"}, "9201a161"},
+        {map[string]any{"b": 2, "a": 1}, "82a16101a16202"},
+        {level(3), "03"},
+        {[]string{"x"}, "91a178"},
+        {struct {
+            N int `json:"n"`
+        }{7}, "81a16e07"},
+    }
+    for _, c := range cases {
+        b, err := appendMsgpack(nil, c.in)
+        if err != nil {
+            t.Fatalf("appendMsgpack(%#v): %v", c.in, err)
+        }
+        if got := hex.EncodeToString(b); got != c.want {
+            t.Errorf("appendMsgpack(%#v) = %s, want %s", c.in, got, c.want)
+        }
+    }
+}
+
+func TestAppendMsgpack_Headers(t *testing.T) {
+    b, _ := appendMsgpack(nil, strings.Repeat("a", 300))
+    if !bytes.HasPrefix(b, []byte{0xda, 0x01, 0x2c}) {
+        t.Fatalf("expected str16 header, got % x", b[:3])
+    }
+    b, _ = appendMsgpack(nil, make([]any, 20))
+    if !bytes.HasPrefix(b, []byte{0xdc, 0x00, 0x14}) {
+        t.Fatalf("expected array16 header, got % x", b[:3])
+    }
+}
+
+func TestEventMsgpackBody(t *testing.T) {
+    ev := Event{Name: "x", Timestamp: time.Unix(0, 0), ID: "i", Properties: map[string]any{"n": 1}}
+    b, err := ev.msgpackBody()
+    if err != nil {
+        t.Fatalf("msgpackBody: %v", err)
+    }
+    want := "84" + "a56576656e74" + "a178" + "a86576656e745f6964" + "a169" + "a16e" + "01" +
+        "a974696d657374616d70" + "b4" + hex.EncodeToString([]byte("1970-01-01T00:00:00Z"))
+    if got := hex.EncodeToString(b); got != want {
+        t.Fatalf("msgpackBody = %s, want %s", got, want)
+    }
+}
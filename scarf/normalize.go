@@ -0,0 +1,117 @@
+package scarf
+
+import (
+    "sort"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// KeyNormalization rewrites property keys so events from different call
+// sites aggregate under identical keys.
+type KeyNormalization struct {
+    // Lowercase lowercases keys: "UserID" becomes "userid".
+    Lowercase bool
+    // SnakeCase converts keys to snake_case: "userID", "User-ID" and
+    // "user id" all become "user_id". It implies Lowercase.
+    SnakeCase bool
+    // MaxLength truncates keys longer than MaxLength characters when positive.
+    MaxLength int
+    // Reserved lists keys callers may not set, matched after normalization;
+    // such properties are dropped.
+    Reserved []string
+}
+
+// WithKeyNormalization normalizes the keys of caller-supplied properties
+// before any other processing, so allowlists and redaction rules see the
+// normalized keys. When several keys normalize to the same key, one already
+// in normal form wins, otherwise the first in sorted order. Keys the SDK adds
+// (event, timestamp, event_id, enricher output) are left alone.
+func WithKeyNormalization(n KeyNormalization) Option {
+    return func(s *ScarfEventLogger) {
+        n.Reserved = append([]string(nil), n.Reserved...)
+        s.keyNorm = &n
+    }
+}
+
+// normalizeKey applies n to key.
+func (n *KeyNormalization) normalizeKey(key string) string {
+    switch {
+    case n.SnakeCase:
+        key = snakeCase(key)
+    case n.Lowercase:
+        key = strings.ToLower(key)
+    }
+    if n.MaxLength > 0 && utf8.RuneCountInString(key) > n.MaxLength {
+        key = string([]rune(key)[:n.MaxLength])
+    }
+    return key
+}
+
+// normalizeProperties returns props with normalized keys, dropping reserved
+// ones. The "event" key is kept as is.
+func (n *KeyNormalization) normalizeProperties(props map[string]any) map[string]any {
+    keys := make([]string, 0, len(props))
+    for k := range props {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    out := make(map[string]any, len(props))
+    if v, ok := props["event"]; ok {
+        out["event"] = v
+    }
+    // Keys already in normal form take precedence over ones that collide
+    // with them after normalization.
+    for _, pass := range []bool{true, false} {
+        for _, k := range keys {
+            if k == "event" {
+                continue
+            }
+            nk := n.normalizeKey(k)
+            if (nk == k) != pass || n.reserved(nk) {
+                continue
+            }
+            if _, taken := out[nk]; !taken {
+                out[nk] = props[k]
+            }
+        }
+    }
+    return out
+}
+
+func (n *KeyNormalization) reserved(key string) bool {
+    for _, r := range n.Reserved {
+        if key == r {
+            return true
+        }
+    }
+    return false
+}
+
+// snakeCase converts s to lower snake_case, splitting words at case changes
+// and at any character that isn't a letter or digit.
+func snakeCase(s string) string {
+    var b strings.Builder
+    runes := []rune(s)
+    pendingSep := false
+    for i, r := range runes {
+        if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+            pendingSep = b.Len() > 0
+            continue
+        }
+        if unicode.IsUpper(r) && i > 0 && b.Len() > 0 {
+            prev := runes[i-1]
+            nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+            if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+                pendingSep = true
+            }
+        }
+        if pendingSep {
+            b.WriteByte('_')
+            pendingSep = false
+        }
+        b.WriteRune(unicode.ToLower(r))
+    }
+    return b.String()
+}
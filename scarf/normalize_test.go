@@ -0,0 +1,68 @@
+package scarf
+
+import "testing"
+
+func TestSnakeCase(t *testing.T) {
+    cases := map[string]string{
+        "userID":      "user_id",
+        "UserID":      "user_id",
+        "HTTPStatus":  "http_status",
+        "user-name":   "user_name",
+        "user name":   "user_name",
+        "already_ok":  "already_ok",
+        "__leading":   "leading",
+        "os2Version":  "os2_version",
+        "CONSTANT":    "constant",
+        "trailing-":   "trailing",
+    }
+    for in, want := range cases {
+        if got := snakeCase(in); got != want {
+            t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+        }
+    }
+}
+
+func TestWithKeyNormalization(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got),
+        WithKeyNormalization(KeyNormalization{SnakeCase: true, MaxLength: 12, Reserved: []string{"install_id"}}),
+        WithRedactedKeys([]string{"api_token"}),
+    )
+    props := map[string]any{
+        "event":            "Build",
+        "userID":           "a",
+        "user_id":          "b",
+        "apiToken":         "secret",
+        "installID":        "spoofed",
+        "averyveryLongKey": 1,
+    }
+    if err := l.LogEvent(props); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    p := got[0].Properties
+    if p["event"] != "Build" {
+        t.Fatalf("event name must not be normalized: %v", p)
+    }
+    if p["user_id"] != "b" {
+        t.Fatalf("key already in normal form should win a collision, got %v", p["user_id"])
+    }
+    if p["api_token"] != RedactedValue {
+        t.Fatalf("redaction should see normalized keys: %v", p)
+    }
+    if _, ok := p["install_id"]; ok {
+        t.Fatalf("reserved key should be dropped: %v", p)
+    }
+    if p["averyvery_lo"] != 1 {
+        t.Fatalf("expected key truncated to 12 characters: %v", p)
+    }
+    if len(props) != 6 {
+        t.Fatalf("caller's map must not be modified")
+    }
+}
+
+func TestKeyNormalization_Lowercase(t *testing.T) {
+    n := KeyNormalization{Lowercase: true}
+    if got := n.normalizeKey("UserID"); got != "userid" {
+        t.Fatalf("got %q", got)
+    }
+}
@@ -0,0 +1,108 @@
+package scarf
+
+import (
+    "time"
+)
+
+// Delivery describes the outcome of a single delivery attempt.
+type Delivery struct {
+    // StatusCode is the HTTP status code returned by the endpoint, or 0 if no
+    // response was received.
+    StatusCode int
+    // Latency is the time spent waiting for the endpoint to respond.
+    Latency time.Duration
+    // Err is nil when the attempt succeeded with a 2xx status code.
+    Err error
+}
+
+// Observer is notified after each delivery attempt, so applications can
+// surface telemetry health in their own metrics.
+//
+// Observers are called synchronously on the sending goroutine and should
+// return quickly.
+type Observer interface {
+    OnSuccess(Delivery)
+    OnFailure(Delivery)
+}
+
+// ObserverFuncs adapts plain functions to the Observer interface.
+// Nil fields are ignored.
+type ObserverFuncs struct {
+    Success func(Delivery)
+    Failure func(Delivery)
+}
+
+// OnSuccess calls f.Success if set.
+func (f ObserverFuncs) OnSuccess(d Delivery) {
+    if f.Success != nil {
+        f.Success(d)
+    }
+}
+
+// OnFailure calls f.Failure if set.
+func (f ObserverFuncs) OnFailure(d Delivery) {
+    if f.Failure != nil {
+        f.Failure(d)
+    }
+}
+
+// WithObserver registers an Observer. It may be passed multiple times;
+// observers are called in registration order.
+func WithObserver(o Observer) Option {
+    return func(s *ScarfEventLogger) {
+        if o != nil {
+            s.observers = append(s.observers, o)
+        }
+    }
+}
+
+// WithOnSuccess registers a callback invoked after each successful delivery.
+func WithOnSuccess(fn func(Delivery)) Option {
+    return WithObserver(ObserverFuncs{Success: fn})
+}
+
+// WithOnFailure registers a callback invoked after each failed delivery attempt.
+func WithOnFailure(fn func(Delivery)) Option {
+    return WithObserver(ObserverFuncs{Failure: fn})
+}
+
+// DropReason says why an event was dropped before any delivery attempt.
+type DropReason string
+
+// Drop reasons reported to DropObservers.
+const (
+    DropClosed    DropReason = "closed"     // the logger was closed
+    DropDisabled  DropReason = "disabled"   // analytics are disabled
+    DropConsent   DropReason = "consent"    // consent was not granted
+    DropSampled   DropReason = "sampled"    // the event was sampled out
+    DropDeadline  DropReason = "deadline"   // too close to a serverless deadline
+    DropQueueFull DropReason = "queue_full" // the WithMaxPending limit was reached
+    DropInvalid   DropReason = "invalid"    // rejected by WithStrict
+)
+
+// DropObserver may be implemented by an Observer to also be notified of
+// events dropped before delivery. Calls to LogEvents and StreamEvents that
+// are refused outright (closed, disabled, no consent) count as one drop.
+type DropObserver interface {
+    OnDrop(reason DropReason)
+}
+
+func (s *ScarfEventLogger) notifyDrop(reason DropReason) {
+    s.stats.recordDrop(reason)
+    for _, o := range s.observers {
+        if do, ok := o.(DropObserver); ok {
+            s.guard("observer", func() { do.OnDrop(reason) })
+        }
+    }
+}
+
+func (s *ScarfEventLogger) notifyObservers(d Delivery) {
+    s.stats.recordDelivery(d)
+    for _, o := range s.observers {
+        if d.Err == nil {
+            s.guard("observer", func() { o.OnSuccess(d) })
+        } else {
+            s.guard("observer", func() { o.OnFailure(d) })
+        }
+    }
+}
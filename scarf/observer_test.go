@@ -0,0 +1,92 @@
+package scarf
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestObserver_SuccessAndFailure(t *testing.T) {
+    status := http.StatusOK
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(status)
+    }))
+    defer srv.Close()
+
+    var successes, failures []Delivery
+    l := New(srv.URL,
+        WithOnSuccess(func(d Delivery) { successes = append(successes, d) }),
+        WithOnFailure(func(d Delivery) { failures = append(failures, d) }),
+    )
+
+    if err := l.LogEvent(map[string]any{"event": "ok"}); err != nil {
+        t.Fatalf("expected success, got %v", err)
+    }
+    status = http.StatusServiceUnavailable
+    if err := l.LogEvent(map[string]any{"event": "fail"}); err == nil {
+        t.Fatalf("expected error on non-2xx status")
+    }
+
+    if len(successes) != 1 || successes[0].StatusCode != http.StatusOK || successes[0].Err != nil {
+        t.Fatalf("unexpected successes: %+v", successes)
+    }
+    if len(failures) != 1 || failures[0].StatusCode != http.StatusServiceUnavailable || failures[0].Err == nil {
+        t.Fatalf("unexpected failures: %+v", failures)
+    }
+    if successes[0].Latency <= 0 {
+        t.Fatalf("expected positive latency, got %s", successes[0].Latency)
+    }
+}
+
+func TestObserver_NetworkError(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+    url := srv.URL
+    srv.Close()
+
+    var got []Delivery
+    l := New(url, WithObserver(ObserverFuncs{Failure: func(d Delivery) { got = append(got, d) }}))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil {
+        t.Fatalf("expected request failure")
+    }
+    if len(got) != 1 || got[0].StatusCode != 0 || got[0].Err == nil {
+        t.Fatalf("unexpected deliveries: %+v", got)
+    }
+}
+
+func TestObserver_NotCalledWhenDisabled(t *testing.T) {
+    t.Setenv("DO_NOT_TRACK", "1")
+    called := false
+    l := New("https://example.com", WithOnFailure(func(Delivery) { called = true }))
+    _ = l.LogEvent(map[string]any{"event": "x"})
+    if called {
+        t.Fatalf("observer should not be called when no attempt was made")
+    }
+}
+
+type dropRecorder struct {
+    ObserverFuncs
+    drops []DropReason
+}
+
+func (r *dropRecorder) OnDrop(reason DropReason) { r.drops = append(r.drops, reason) }
+
+func TestDropObserver(t *testing.T) {
+    rec := &dropRecorder{}
+    l := New("", captureEvents(new([]Event)), WithObserver(rec), WithSampleRate(0))
+    _ = l.LogEvent(map[string]any{"event": "x"})
+    l.Close()
+    _ = l.LogEvent(map[string]any{"event": "x"})
+
+    t.Setenv("DO_NOT_TRACK", "1")
+    _ = New("https://example.com", WithObserver(rec)).LogEvent(map[string]any{"event": "x"})
+
+    want := []DropReason{DropSampled, DropClosed, DropDisabled}
+    if len(rec.drops) != len(want) {
+        t.Fatalf("drops = %v, want %v", rec.drops, want)
+    }
+    for i := range want {
+        if rec.drops[i] != want[i] {
+            t.Fatalf("drops = %v, want %v", rec.drops, want)
+        }
+    }
+}
@@ -0,0 +1,63 @@
+package scarf
+
+import (
+    "net/http"
+    "strings"
+    "time"
+)
+
+// Option configures a ScarfEventLogger created with New.
+type Option func(*ScarfEventLogger)
+
+// WithTimeout sets the default timeout used by LogEvent.
+// Non-positive values leave the default (3 seconds) in place.
+func WithTimeout(timeout time.Duration) Option {
+    return func(s *ScarfEventLogger) {
+        if timeout > 0 {
+            s.defaultTimeout = timeout
+            s.mark("timeout")
+        }
+    }
+}
+
+// WithEndpointURL sets the endpoint URL, overriding the one passed to New or
+// read from a config file or the environment by NewFromSources. Blank values
+// are ignored.
+func WithEndpointURL(endpointURL string) Option {
+    return func(s *ScarfEventLogger) {
+        if endpointURL = strings.TrimSpace(endpointURL); endpointURL != "" {
+            s.endpointURL = endpointURL
+            s.mark("endpoint_url")
+        }
+    }
+}
+
+// WithHTTPClient sets the HTTP client used to deliver events. The configured
+// timeout is applied per request through its context; a non-zero
+// client.Timeout also bounds every request, StreamEvents uploads included, so
+// prefer leaving it unset.
+func WithHTTPClient(client *http.Client) Option {
+    return func(s *ScarfEventLogger) {
+        if client != nil {
+            s.httpClient = client
+        }
+    }
+}
+
+// WithAPIKey authenticates requests with an "Authorization: Bearer <key>"
+// header, for endpoints that require it.
+func WithAPIKey(key string) Option {
+    return func(s *ScarfEventLogger) {
+        s.apiKey = strings.TrimSpace(key)
+        s.mark("api_key")
+    }
+}
+
+// WithVerbose sets the log level to LogDebug or LogOff, overriding
+// SCARF_VERBOSE. See WithLogLevel for finer control.
+func WithVerbose(verbose bool) Option {
+    if verbose {
+        return WithLogLevel(LogDebug)
+    }
+    return WithLogLevel(LogOff)
+}
@@ -0,0 +1,91 @@
+package scarf
+
+import (
+    "bufio"
+    "context"
+    "os"
+    "runtime"
+    "strings"
+    "sync"
+)
+
+// OSVersionEnricher returns an Enricher identifying the OS release beyond
+// GOOS, for support decisions:
+//
+//   - os_product: the Linux distribution ID from /etc/os-release (e.g.
+//     "ubuntu", "fedora"), or "macos" or "windows"
+//   - os_product_version: the distribution's VERSION_ID (e.g. "22.04"), the
+//     macOS product version (e.g. "14.4.1"), or the Windows version and build
+//     number (e.g. "10.0.22631")
+//
+// Detection is best effort, runs once, and never starts a process. Unknown
+// values are omitted.
+func OSVersionEnricher() Enricher {
+    var once sync.Once
+    var props map[string]any
+    return EnricherFunc(func(context.Context) map[string]any {
+        once.Do(func() {
+            product, version := osProductVersion()
+            props = map[string]any{}
+            if product != "" {
+                props["os_product"] = product
+            }
+            if version != "" {
+                props["os_product_version"] = version
+            }
+        })
+        return props
+    })
+}
+
+func osProductVersion() (product, version string) {
+    switch runtime.GOOS {
+    case "linux":
+        for _, path := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+            if f, err := os.Open(path); err == nil {
+                defer f.Close()
+                osRelease := parseOSRelease(f)
+                return osRelease["ID"], osRelease["VERSION_ID"]
+            }
+        }
+    case "darwin":
+        b, err := os.ReadFile("/System/Library/CoreServices/SystemVersion.plist")
+        if err != nil {
+            return "macos", ""
+        }
+        return "macos", plistString(string(b), "ProductVersion")
+    case "windows":
+        return "windows", windowsVersion()
+    }
+    return "", ""
+}
+
+// parseOSRelease reads the KEY=value lines of an os-release file, unquoting
+// values.
+func parseOSRelease(f *os.File) map[string]string {
+    vals := map[string]string{}
+    sc := bufio.NewScanner(f)
+    for sc.Scan() {
+        k, v, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+        if !ok || strings.HasPrefix(k, "#") {
+            continue
+        }
+        vals[k] = strings.Trim(v, `"'`)
+    }
+    return vals
+}
+
+// plistString returns the string value for key in an XML property list, or
+// "" if it isn't there.
+func plistString(plist, key string) string {
+    _, rest, ok := strings.Cut(plist, "<key>"+key+"</key>")
+    if !ok {
+        return ""
+    }
+    _, rest, ok = strings.Cut(rest, "<string>")
+    if !ok {
+        return ""
+    }
+    v, _, _ := strings.Cut(rest, "</string>")
+    return strings.TrimSpace(v)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package scarf
+
+// windowsVersion is only available on Windows; see osversion_windows.go.
+func windowsVersion() string {
+    return ""
+}
@@ -0,0 +1,55 @@
+package scarf
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "runtime"
+    "testing"
+)
+
+func TestParseOSRelease(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "os-release")
+    content := "# comment\nNAME=\"Ubuntu\"\nID=ubuntu\nVERSION_ID=\"22.04\"\nPRETTY_NAME='Ubuntu 22.04.4 LTS'\n"
+    if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+    got := parseOSRelease(f)
+    if got["ID"] != "ubuntu" || got["VERSION_ID"] != "22.04" || got["PRETTY_NAME"] != "Ubuntu 22.04.4 LTS" {
+        t.Fatalf("unexpected os-release values %v", got)
+    }
+}
+
+func TestPlistString(t *testing.T) {
+    plist := `<dict>
+    <key>ProductName</key>
+    <string>macOS</string>
+    <key>ProductVersion</key>
+    <string>14.4.1</string>
+</dict>`
+    if got := plistString(plist, "ProductVersion"); got != "14.4.1" {
+        t.Fatalf("expected 14.4.1, got %q", got)
+    }
+    if got := plistString(plist, "Missing"); got != "" {
+        t.Fatalf("expected no value for a missing key, got %q", got)
+    }
+}
+
+func TestOSVersionEnricher(t *testing.T) {
+    props := OSVersionEnricher().Enrich(context.Background())
+    switch runtime.GOOS {
+    case "darwin":
+        if props["os_product"] != "macos" {
+            t.Fatalf("expected macos, got %v", props)
+        }
+    case "windows":
+        if props["os_product"] != "windows" || props["os_product_version"] == nil {
+            t.Fatalf("expected the Windows build, got %v", props)
+        }
+    }
+}
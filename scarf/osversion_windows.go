@@ -0,0 +1,35 @@
+//go:build windows
+
+package scarf
+
+import (
+    "fmt"
+    "syscall"
+    "unsafe"
+)
+
+// osVersionInfo is RTL_OSVERSIONINFOW.
+type osVersionInfo struct {
+    size       uint32
+    major      uint32
+    minor      uint32
+    build      uint32
+    platformID uint32
+    csdVersion [128]uint16
+}
+
+// windowsVersion returns the Windows version and build number from
+// RtlGetVersion, which, unlike GetVersionEx, isn't subject to compatibility
+// shims.
+func windowsVersion() string {
+    proc := syscall.NewLazyDLL("ntdll.dll").NewProc("RtlGetVersion")
+    if proc.Find() != nil {
+        return ""
+    }
+    var info osVersionInfo
+    info.size = uint32(unsafe.Sizeof(info))
+    if status, _, _ := proc.Call(uintptr(unsafe.Pointer(&info))); status != 0 {
+        return ""
+    }
+    return fmt.Sprintf("%d.%d.%d", info.major, info.minor, info.build)
+}
@@ -0,0 +1,150 @@
+package scarf
+
+import (
+    "errors"
+    "fmt"
+    "net/url"
+)
+
+// defaultMaxPayload is the request URL length above which events are sent as
+// a JSON body by default.
+const defaultMaxPayload = 2048
+
+// ErrPayloadTooLarge is returned when an event can't be made to fit the
+// maximum payload size.
+var ErrPayloadTooLarge = errors.New("scarf: payload too large")
+
+// OversizeStrategy decides what happens to events whose encoded URL exceeds
+// the maximum payload size.
+type OversizeStrategy int
+
+const (
+    // OversizeTruncate shortens the longest property values until the event
+    // fits.
+    OversizeTruncate OversizeStrategy = iota
+    // OversizeDrop removes the largest properties until the event fits.
+    OversizeDrop
+    // OversizeBody sends the event as a JSON request body instead of query
+    // parameters.
+    OversizeBody
+)
+
+// WithMaxPayloadSize limits the encoded request URL, including query
+// parameters, to maxBytes; many proxies silently reject very long URLs.
+// Oversized events are handled by strategy. The event name, timestamp and
+// event_id are never truncated or dropped; if an event can't be made to fit,
+// sending fails with ErrPayloadTooLarge. A maxBytes of zero or less removes
+// the limit, so every event uses query parameters.
+//
+// The default is 2048 bytes with OversizeBody: small events keep using query
+// parameters and larger ones move transparently into a JSON body.
+func WithMaxPayloadSize(maxBytes int, strategy OversizeStrategy) Option {
+    return func(s *ScarfEventLogger) {
+        s.maxPayload = maxBytes
+        s.oversize = strategy
+    }
+}
+
+// encodeHTTP encodes ev for delivery to endpoint, returning the request URL
+// and, in body mode, the body and its Content-Type.
+func (s *ScarfEventLogger) encodeHTTP(endpoint string, ev Event) (string, []byte, string, error) {
+    p, err := s.endpoints.get(endpoint)
+    if err != nil {
+        return "", nil, "", fmt.Errorf("scarf: invalid endpoint URL: %w", err)
+    }
+    if s.encoding != EncodingQuery {
+        return s.encodeBodyTo(endpoint, ev)
+    }
+    if p.fast {
+        if rawURL := p.queryURL(ev); s.maxPayload <= 0 || len(rawURL) <= s.maxPayload {
+            return rawURL, nil, "", nil
+        }
+    }
+
+    // Slow path: the event needs shrinking, or the URL has a fragment.
+    u, _ := url.Parse(endpoint)
+    q := u.Query()
+    // The endpoint's own parameters are never truncated or dropped.
+    fixed := map[string]bool{"event": true, "timestamp": true, "event_id": true}
+    for k := range q {
+        fixed[k] = true
+    }
+    for k, vs := range ev.queryValues() {
+        q[k] = vs
+    }
+    u.RawQuery = q.Encode()
+    if s.maxPayload <= 0 || len(u.String()) <= s.maxPayload {
+        return u.String(), nil, "", nil
+    }
+    s.debug("event exceeds maximum payload size", "size", len(u.String()), "max", s.maxPayload)
+    if s.strict && s.oversize != OversizeBody {
+        return "", nil, "", ErrPayloadTooLarge
+    }
+
+    switch s.oversize {
+    case OversizeBody:
+        return s.encodeBodyTo(endpoint, ev)
+    case OversizeDrop:
+        for len(u.String()) > s.maxPayload {
+            k := largestProperty(q, fixed, true)
+            if k == "" {
+                return "", nil, "", ErrPayloadTooLarge
+            }
+            q.Del(k)
+            u.RawQuery = q.Encode()
+        }
+    default:
+        for len(u.String()) > s.maxPayload {
+            k := largestProperty(q, fixed, false)
+            if k == "" {
+                return "", nil, "", ErrPayloadTooLarge
+            }
+            // Keep the longest prefix of the value that fits, if any.
+            v := []rune(q.Get(k))
+            lo, hi := 0, len(v)-1
+            for lo < hi {
+                mid := (lo + hi + 1) / 2
+                q.Set(k, string(v[:mid]))
+                u.RawQuery = q.Encode()
+                if len(u.String()) <= s.maxPayload {
+                    lo = mid
+                } else {
+                    hi = mid - 1
+                }
+            }
+            q.Set(k, string(v[:lo]))
+            u.RawQuery = q.Encode()
+        }
+    }
+    return u.String(), nil, "", nil
+}
+
+// encodeBodyTo encodes ev as a body for endpoint.
+func (s *ScarfEventLogger) encodeBodyTo(endpoint string, ev Event) (string, []byte, string, error) {
+    body, contentType, err := s.encodeBody(ev)
+    if err != nil {
+        return "", nil, "", fmt.Errorf("scarf: encode body: %w", err)
+    }
+    return endpoint, body, contentType, nil
+}
+
+// largestProperty returns the parameter with the longest value (counting the
+// key too if withKey is set) that isn't fixed, or "" if there is nothing left
+// to shrink.
+func largestProperty(q url.Values, fixed map[string]bool, withKey bool) string {
+    var largest string
+    best := 0
+    for k, vs := range q {
+        if fixed[k] {
+            continue
+        }
+        n := len(vs[0])
+        if withKey {
+            n += len(k) + 1
+        }
+        if n > best || (n == best && k < largest) {
+            largest, best = k, n
+        }
+    }
+    return largest
+}
@@ -0,0 +1,126 @@
+package scarf
+
+import (
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+type capturedRequest struct {
+    url         string
+    contentType string
+    body        []byte
+}
+
+func captureServer(t *testing.T, got *[]capturedRequest) *httptest.Server {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        *got = append(*got, capturedRequest{url: r.URL.String(), contentType: r.Header.Get("Content-Type"), body: body})
+        w.WriteHeader(http.StatusOK)
+    }))
+    t.Cleanup(srv.Close)
+    return srv
+}
+
+func TestMaxPayloadSize_Truncate(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    max := 300
+    l := New(srv.URL+"/e?pkg=abc", WithMaxPayloadSize(max, OversizeTruncate))
+    long := strings.Repeat("é", 400)
+    if err := l.LogEvent(map[string]any{"event": "x", "stack": long, "short": "ok"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if n := len(srv.URL) + len(got[0].url); n > max {
+        t.Fatalf("URL is %d bytes, want at most %d", n, max)
+    }
+    if !strings.Contains(got[0].url, "pkg=abc") || !strings.Contains(got[0].url, "short=ok") || !strings.Contains(got[0].url, "stack=%C3%A9") {
+        t.Fatalf("expected only the long value to be truncated: %s", got[0].url)
+    }
+}
+
+func TestMaxPayloadSize_Drop(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    l := New(srv.URL, WithMaxPayloadSize(300, OversizeDrop))
+    if err := l.LogEvent(map[string]any{"event": "x", "stack": strings.Repeat("a", 500), "short": "ok"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if strings.Contains(got[0].url, "stack=") || !strings.Contains(got[0].url, "short=ok") {
+        t.Fatalf("expected the oversized property to be dropped: %s", got[0].url)
+    }
+}
+
+func TestMaxPayloadSize_Body(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    l := New(srv.URL+"/e?pkg=abc", WithMaxPayloadSize(300, OversizeBody))
+    if err := l.LogEvent(map[string]any{"event": "small"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "big", "stack": strings.Repeat("a", 500), "count": 3}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if len(got[0].body) != 0 || !strings.Contains(got[0].url, "event=small") {
+        t.Fatalf("small events should stay on the query path: %+v", got[0])
+    }
+    big := got[1]
+    if big.url != "/e?pkg=abc" || big.contentType != "application/json" {
+        t.Fatalf("unexpected body-mode request: %s (%s)", big.url, big.contentType)
+    }
+    var fields map[string]any
+    if err := json.Unmarshal(big.body, &fields); err != nil {
+        t.Fatalf("invalid JSON body: %v", err)
+    }
+    if fields["event"] != "big" || fields["count"] != 3.0 || fields["event_id"] == nil || len(fields["stack"].(string)) != 500 {
+        t.Fatalf("unexpected body fields: %v", fields)
+    }
+}
+
+func TestMaxPayloadSize_TooLarge(t *testing.T) {
+    l := New("https://example.com/"+strings.Repeat("p", 200), WithMaxPayloadSize(100, OversizeTruncate))
+    if err := l.LogEvent(map[string]any{"event": "x", "a": "b"}); !errors.Is(err, ErrPayloadTooLarge) {
+        t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+    }
+}
+
+func TestDefaultBodyFallback(t *testing.T) {
+    var got []capturedRequest
+    srv := captureServer(t, &got)
+    l := New(srv.URL)
+    if err := l.LogEvent(map[string]any{"event": "small", "v": "1"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "large", "v": strings.Repeat("x", 2048)}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if len(got[0].body) != 0 || !strings.Contains(got[0].url, "v=1") {
+        t.Fatalf("small events should use query parameters: %+v", got[0])
+    }
+    if got[1].url != "/" || got[1].contentType != "application/json" || !strings.Contains(string(got[1].body), `"event":"large"`) {
+        t.Fatalf("large events should move to a JSON body: %s %s", got[1].url, got[1].contentType)
+    }
+
+    got = nil
+    if err := New(srv.URL, WithMaxPayloadSize(0, OversizeBody)).LogEvent(map[string]any{"event": "large", "v": strings.Repeat("x", 2048)}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if len(got[0].body) != 0 {
+        t.Fatalf("a zero limit should keep every event on the query path")
+    }
+}
+
+func BenchmarkEncodeHTTP(b *testing.B) {
+    l := New("https://example.com/e?pkg=abc")
+    ev := l.stampEvent(Event{Name: "x", Properties: map[string]any{"version": "1.2.3", "os": "linux", "count": 3, "ci": false}})
+    b.ReportAllocs()
+    for i := 0; i < b.N; i++ {
+        if _, _, _, err := l.encodeHTTP(l.endpointURL, ev); err != nil {
+            b.Fatal(err)
+        }
+    }
+}
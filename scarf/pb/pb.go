@@ -0,0 +1,305 @@
+// Package pb implements the Protocol Buffers wire format for Scarf events
+// described in event.proto, without depending on the protobuf runtime.
+//
+// Self-hosted collectors can decode request bodies sent with
+// scarf.WithEncoding(scarf.EncodingProtobuf) using Event.Unmarshal and
+// Batch.Unmarshal, or generate their own types from event.proto.
+package pb
+
+import (
+    "encoding/binary"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+    "sort"
+)
+
+// ContentType is the Content-Type of protobuf-encoded requests.
+const ContentType = "application/x-protobuf"
+
+// Kind identifies which field of a Value is set.
+type Kind int
+
+// Value kinds, matching the Value.kind oneof in event.proto.
+const (
+    KindString Kind = iota + 1
+    KindInt
+    KindDouble
+    KindBool
+    KindJSON
+)
+
+// Value is a property value: a string, integer, float, bool, or any other
+// value as JSON.
+type Value struct {
+    Kind   Kind
+    String string // KindString and KindJSON
+    Int    int64
+    Double float64
+    Bool   bool
+}
+
+// ValueOf converts a Go value to a Value. Integers that don't fit an int64
+// and values of other types are JSON-encoded.
+func ValueOf(v any) Value {
+    switch vv := v.(type) {
+    case string:
+        return Value{Kind: KindString, String: vv}
+    case bool:
+        return Value{Kind: KindBool, Bool: vv}
+    case int:
+        return Value{Kind: KindInt, Int: int64(vv)}
+    case int8:
+        return Value{Kind: KindInt, Int: int64(vv)}
+    case int16:
+        return Value{Kind: KindInt, Int: int64(vv)}
+    case int32:
+        return Value{Kind: KindInt, Int: int64(vv)}
+    case int64:
+        return Value{Kind: KindInt, Int: vv}
+    case uint8:
+        return Value{Kind: KindInt, Int: int64(vv)}
+    case uint16:
+        return Value{Kind: KindInt, Int: int64(vv)}
+    case uint32:
+        return Value{Kind: KindInt, Int: int64(vv)}
+    case uint:
+        if uint64(vv) <= math.MaxInt64 {
+            return Value{Kind: KindInt, Int: int64(vv)}
+        }
+    case uint64:
+        if vv <= math.MaxInt64 {
+            return Value{Kind: KindInt, Int: int64(vv)}
+        }
+    case float32:
+        return Value{Kind: KindDouble, Double: float64(vv)}
+    case float64:
+        return Value{Kind: KindDouble, Double: vv}
+    case fmt.Stringer:
+        return Value{Kind: KindString, String: vv.String()}
+    }
+    b, err := json.Marshal(v)
+    if err != nil {
+        return Value{Kind: KindString, String: fmt.Sprint(v)}
+    }
+    return Value{Kind: KindJSON, String: string(b)}
+}
+
+// Event is a single event.
+type Event struct {
+    Name              string
+    TimestampUnixNano int64
+    ID                string
+    Properties        map[string]Value
+}
+
+// Batch is a list of events sent in one request.
+type Batch struct {
+    Events []Event
+}
+
+// ErrMalformed is returned when decoding invalid input.
+var ErrMalformed = errors.New("pb: malformed message")
+
+const (
+    wireVarint = 0
+    wire64     = 1
+    wireBytes  = 2
+    wire32     = 5
+)
+
+func appendTag(b []byte, field, wire int) []byte {
+    return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+    b = appendTag(b, field, wireBytes)
+    b = binary.AppendUvarint(b, uint64(len(v)))
+    return append(b, v...)
+}
+
+func appendString(b []byte, field int, v string) []byte {
+    if v == "" {
+        return b
+    }
+    return appendBytes(b, field, []byte(v))
+}
+
+// Marshal encodes v. Exactly one field of the oneof is always written.
+func (v Value) Marshal() []byte {
+    var b []byte
+    switch v.Kind {
+    case KindInt:
+        b = appendTag(b, 2, wireVarint)
+        b = binary.AppendUvarint(b, uint64(v.Int<<1)^uint64(v.Int>>63))
+    case KindDouble:
+        b = appendTag(b, 3, wire64)
+        b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v.Double))
+    case KindBool:
+        b = appendTag(b, 4, wireVarint)
+        if v.Bool {
+            b = append(b, 1)
+        } else {
+            b = append(b, 0)
+        }
+    case KindJSON:
+        b = appendBytes(b, 5, []byte(v.String))
+    default:
+        b = appendBytes(b, 1, []byte(v.String))
+    }
+    return b
+}
+
+// Marshal encodes e. Properties are written in key order so the encoding is
+// deterministic.
+func (e *Event) Marshal() []byte {
+    var b []byte
+    b = appendString(b, 1, e.Name)
+    if e.TimestampUnixNano != 0 {
+        b = appendTag(b, 2, wireVarint)
+        b = binary.AppendUvarint(b, uint64(e.TimestampUnixNano))
+    }
+    b = appendString(b, 3, e.ID)
+    keys := make([]string, 0, len(e.Properties))
+    for k := range e.Properties {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        var entry []byte
+        entry = appendBytes(entry, 1, []byte(k))
+        entry = appendBytes(entry, 2, e.Properties[k].Marshal())
+        b = appendBytes(b, 4, entry)
+    }
+    return b
+}
+
+// Marshal encodes the batch.
+func (bt *Batch) Marshal() []byte {
+    var b []byte
+    for i := range bt.Events {
+        b = appendBytes(b, 1, bt.Events[i].Marshal())
+    }
+    return b
+}
+
+// field is a decoded field: its number, wire type and value.
+type field struct {
+    num   int
+    wire  int
+    u     uint64
+    bytes []byte
+}
+
+// fields decodes the top-level fields of a message.
+func fields(b []byte, fn func(f field) error) error {
+    for len(b) > 0 {
+        tag, n := binary.Uvarint(b)
+        if n <= 0 {
+            return ErrMalformed
+        }
+        b = b[n:]
+        f := field{num: int(tag >> 3), wire: int(tag & 7)}
+        switch f.wire {
+        case wireVarint:
+            f.u, n = binary.Uvarint(b)
+            if n <= 0 {
+                return ErrMalformed
+            }
+            b = b[n:]
+        case wire64:
+            if len(b) < 8 {
+                return ErrMalformed
+            }
+            f.u, b = binary.LittleEndian.Uint64(b), b[8:]
+        case wire32:
+            if len(b) < 4 {
+                return ErrMalformed
+            }
+            f.u, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+        case wireBytes:
+            l, n := binary.Uvarint(b)
+            if n <= 0 || l > uint64(len(b)-n) {
+                return ErrMalformed
+            }
+            f.bytes, b = b[n:n+int(l)], b[n+int(l):]
+        default:
+            return ErrMalformed
+        }
+        if err := fn(f); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Unmarshal decodes b into v. Unknown fields are ignored.
+func (v *Value) Unmarshal(b []byte) error {
+    *v = Value{}
+    return fields(b, func(f field) error {
+        switch {
+        case f.num == 1 && f.wire == wireBytes:
+            *v = Value{Kind: KindString, String: string(f.bytes)}
+        case f.num == 2 && f.wire == wireVarint:
+            *v = Value{Kind: KindInt, Int: int64(f.u>>1) ^ -int64(f.u&1)}
+        case f.num == 3 && f.wire == wire64:
+            *v = Value{Kind: KindDouble, Double: math.Float64frombits(f.u)}
+        case f.num == 4 && f.wire == wireVarint:
+            *v = Value{Kind: KindBool, Bool: f.u != 0}
+        case f.num == 5 && f.wire == wireBytes:
+            *v = Value{Kind: KindJSON, String: string(f.bytes)}
+        }
+        return nil
+    })
+}
+
+// Unmarshal decodes b into e. Unknown fields are ignored.
+func (e *Event) Unmarshal(b []byte) error {
+    *e = Event{}
+    return fields(b, func(f field) error {
+        switch {
+        case f.num == 1 && f.wire == wireBytes:
+            e.Name = string(f.bytes)
+        case f.num == 2 && f.wire == wireVarint:
+            e.TimestampUnixNano = int64(f.u)
+        case f.num == 3 && f.wire == wireBytes:
+            e.ID = string(f.bytes)
+        case f.num == 4 && f.wire == wireBytes:
+            var key string
+            var val Value
+            err := fields(f.bytes, func(kf field) error {
+                switch {
+                case kf.num == 1 && kf.wire == wireBytes:
+                    key = string(kf.bytes)
+                case kf.num == 2 && kf.wire == wireBytes:
+                    return val.Unmarshal(kf.bytes)
+                }
+                return nil
+            })
+            if err != nil {
+                return err
+            }
+            if e.Properties == nil {
+                e.Properties = make(map[string]Value)
+            }
+            e.Properties[key] = val
+        }
+        return nil
+    })
+}
+
+// Unmarshal decodes b into bt. Unknown fields are ignored.
+func (bt *Batch) Unmarshal(b []byte) error {
+    *bt = Batch{}
+    return fields(b, func(f field) error {
+        if f.num == 1 && f.wire == wireBytes {
+            var e Event
+            if err := e.Unmarshal(f.bytes); err != nil {
+                return err
+            }
+            bt.Events = append(bt.Events, e)
+        }
+        return nil
+    })
+}
@@ -0,0 +1,62 @@
+package pb
+
+import (
+    "bytes"
+    "errors"
+    "reflect"
+    "testing"
+)
+
+func TestValueOf(t *testing.T) {
+    cases := []struct {
+        in   any
+        want Value
+    }{
+        {"s", Value{Kind: KindString, String: "s"}},
+        {true, Value{Kind: KindBool, Bool: true}},
+        {-3, Value{Kind: KindInt, Int: -3}},
+        {uint64(1 << 63), Value{Kind: KindJSON, String: "9223372036854775808"}},
+        {2.5, Value{Kind: KindDouble, Double: 2.5}},
+        {[]string{"a"}, Value{Kind: KindJSON, String: `["a"]`}},
+    }
+    for _, c := range cases {
+        if got := ValueOf(c.in); got != c.want {
+            t.Errorf("ValueOf(%v) = %+v, want %+v", c.in, got, c.want)
+        }
+    }
+}
+
+func TestEventMarshal_Golden(t *testing.T) {
+    e := Event{Name: "x", TimestampUnixNano: 1, ID: "i", Properties: map[string]Value{"k": ValueOf(-1)}}
+    // name=1 "x", timestamp=2 varint 1, id=3 "i", properties=4 {key "k", value {int_value sint64 -1}}
+    want := []byte{0x0a, 1, 'x', 0x10, 1, 0x1a, 1, 'i', 0x22, 7, 0x0a, 1, 'k', 0x12, 2, 0x10, 1}
+    if got := e.Marshal(); !bytes.Equal(got, want) {
+        t.Fatalf("Marshal = % x, want % x", got, want)
+    }
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+    in := Batch{Events: []Event{
+        {Name: "a", TimestampUnixNano: 1700000000123456789, ID: "01", Properties: map[string]Value{
+            "s": ValueOf(""), "i": ValueOf(int64(-1 << 40)), "d": ValueOf(0.1), "b": ValueOf(false), "j": ValueOf(map[string]int{"n": 1}),
+        }},
+        {Name: "b"},
+    }}
+    var out Batch
+    if err := out.Unmarshal(in.Marshal()); err != nil {
+        t.Fatalf("Unmarshal: %v", err)
+    }
+    in.Events[1].Properties = nil
+    if !reflect.DeepEqual(in, out) {
+        t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", out, in)
+    }
+}
+
+func TestUnmarshal_Malformed(t *testing.T) {
+    for _, b := range [][]byte{{0x0a, 5, 'x'}, {0x80}, {0x0b}} {
+        var e Event
+        if err := e.Unmarshal(b); !errors.Is(err, ErrMalformed) {
+            t.Errorf("Unmarshal(% x): expected ErrMalformed, got %v", b, err)
+        }
+    }
+}
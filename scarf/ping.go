@@ -0,0 +1,82 @@
+package scarf
+
+import (
+    "context"
+    "net/http"
+    "strings"
+)
+
+// Ping checks that the endpoint is reachable by sending it a HEAD request,
+// which exercises DNS, the proxy, TLS and the API key without recording an
+// event. Call it at startup to surface misconfiguration immediately rather
+// than as events that silently fail:
+//
+//   if err := logger.Ping(ctx); err != nil {
+//       log.Printf("telemetry endpoint unusable: %v", err)
+//   }
+//
+// Failures are a *NetworkError or an *EndpointError, as for LogEvent; a
+// server that rejects HEAD with 405 or 501 still counts as reachable. A
+// disabled or closed logger, or one without consent, returns the same error
+// LogEvent would without touching the network. In dry-run mode Ping succeeds
+// without sending anything, and with a custom Transport it delegates to the
+// transport's Ping(context.Context) error method, if it has one.
+//
+// Ping uses the logger's timeout and neither trips the circuit breaker nor
+// reaches observers.
+func (s *ScarfEventLogger) Ping(ctx context.Context) error {
+    if buildDisabled || s == nil || s.noop {
+        return ErrDisabled
+    }
+    if s.closed.Load() {
+        return ErrClosed
+    }
+    if !s.Enabled() {
+        return ErrDisabled
+    }
+    if err := s.checkConsent(); err != nil {
+        return err
+    }
+    if s.transport != nil {
+        if p, ok := s.transport.(interface{ Ping(context.Context) error }); ok {
+            return p.Ping(ctx)
+        }
+        return nil
+    }
+    if strings.TrimSpace(s.endpointURL) == "" {
+        return ErrNoEndpoint
+    }
+    if s.dryRun {
+        s.info("dry run: not pinging endpoint", "url", s.logURL(s.endpointURL))
+        return nil
+    }
+    if s.tls.err != nil {
+        return s.tls.err
+    }
+
+    req, err := s.newRequest(http.MethodHead, s.endpointURL, nil, "")
+    if err != nil {
+        return err
+    }
+    parent := ctx
+    if s.defaultTimeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, s.defaultTimeout)
+        defer cancel()
+    }
+    resp, err := s.httpClient.Do(req.WithContext(ctx))
+    if err != nil {
+        err = newNetworkError(parent, req.URL, err)
+        s.warn("ping failed", "error", err)
+        return err
+    }
+    _ = drainAndClose(resp)
+    switch {
+    case resp.StatusCode < 400, resp.StatusCode == http.StatusMethodNotAllowed, resp.StatusCode == http.StatusNotImplemented:
+        s.debug("ping succeeded", "status", resp.Status)
+        return nil
+    }
+    err = newEndpointError(resp)
+    s.warn("ping failed", "error", err)
+    return err
+}
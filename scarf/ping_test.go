@@ -0,0 +1,80 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+)
+
+func TestPing(t *testing.T) {
+    var status atomic.Int32
+    status.Store(http.StatusOK)
+    var gotMethod, gotAuth atomic.Value
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        gotMethod.Store(r.Method)
+        gotAuth.Store(r.Header.Get("Authorization"))
+        w.WriteHeader(int(status.Load()))
+    }))
+    defer srv.Close()
+
+    var deliveries atomic.Int32
+    l := New(srv.URL+"/?package=p", WithAPIKey("k"), WithObserver(ObserverFuncs{
+        Success: func(Delivery) { deliveries.Add(1) },
+        Failure: func(Delivery) { deliveries.Add(1) },
+    }))
+    if err := l.Ping(context.Background()); err != nil {
+        t.Fatalf("Ping: %v", err)
+    }
+    if gotMethod.Load() != http.MethodHead || gotAuth.Load() != "Bearer k" {
+        t.Fatalf("expected an authenticated HEAD, got %v with %q", gotMethod.Load(), gotAuth.Load())
+    }
+    if deliveries.Load() != 0 {
+        t.Fatal("expected Ping not to reach observers")
+    }
+
+    status.Store(http.StatusMethodNotAllowed)
+    if err := l.Ping(context.Background()); err != nil {
+        t.Fatalf("expected 405 to count as reachable, got %v", err)
+    }
+
+    status.Store(http.StatusUnauthorized)
+    var epErr *EndpointError
+    if err := l.Ping(context.Background()); !errors.As(err, &epErr) || epErr.StatusCode != http.StatusUnauthorized {
+        t.Fatalf("expected an EndpointError for 401, got %v", err)
+    }
+
+    srv.Close()
+    var netErr *NetworkError
+    if err := l.Ping(context.Background()); !errors.As(err, &netErr) {
+        t.Fatalf("expected a NetworkError, got %v", err)
+    }
+    if netErr.URL != srv.URL+"/" {
+        t.Fatalf("expected the query to be dropped from %q", netErr.URL)
+    }
+}
+
+func TestPingRefused(t *testing.T) {
+    var hits atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        hits.Add(1)
+    }))
+    defer srv.Close()
+
+    if err := New(srv.URL, WithEnabled(false)).Ping(context.Background()); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+    closed := New(srv.URL)
+    closed.Close()
+    if err := closed.Ping(context.Background()); !errors.Is(err, ErrClosed) {
+        t.Fatalf("expected ErrClosed, got %v", err)
+    }
+    if err := New("").Ping(context.Background()); !errors.Is(err, ErrNoEndpoint) {
+        t.Fatalf("expected ErrNoEndpoint, got %v", err)
+    }
+    if hits.Load() != 0 {
+        t.Fatalf("expected no requests, got %d", hits.Load())
+    }
+}
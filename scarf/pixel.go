@@ -0,0 +1,58 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// pixelBaseURL is Scarf's tracking pixel endpoint.
+var pixelBaseURL = "https://static.scarf.sh/a.png"
+
+// ErrInvalidPixelID is returned by TrackPixel for an empty pixel ID.
+var ErrInvalidPixelID = errors.New("scarf: invalid pixel ID")
+
+// TrackPixel records a view of the Scarf pixel pixelID by issuing a GET for
+// the pixel image, the way a web page embedding it would. props are sent as
+// query parameters and go through the same filtering, enrichment, sampling
+// and consent checks as LogEvent. The event_id added to every event makes
+// each URL unique so caches never swallow a hit.
+//
+// Pixels are always fetched over HTTP; a custom Transport is not used.
+func (s *ScarfEventLogger) TrackPixel(pixelID string, props map[string]any) error {
+    if s == nil {
+        return nil
+    }
+    pixelID = strings.TrimSpace(pixelID)
+    if pixelID == "" {
+        return ErrInvalidPixelID
+    }
+    send := func(ctx context.Context, ev Event, timeout time.Duration) error {
+        return s.sendPixel(ctx, pixelID, ev, timeout)
+    }
+    return s.process(context.Background(), eventFromProperties(props), s.defaultTimeout, send)
+}
+
+// sendPixel fetches the pixel image with ev encoded as query parameters.
+func (s *ScarfEventLogger) sendPixel(ctx context.Context, pixelID string, ev Event, timeout time.Duration) error {
+    u, err := url.Parse(pixelBaseURL)
+    if err != nil {
+        return fmt.Errorf("scarf: invalid pixel URL: %w", err)
+    }
+    q := ev.queryValues()
+    q.Set("x-pxid", pixelID)
+    u.RawQuery = q.Encode()
+
+    req, err := s.newRequest(http.MethodGet, u.String(), nil, "")
+    if err != nil {
+        s.error("failed to build request", "error", err)
+        return fmt.Errorf("scarf: build request: %w", err)
+    }
+    req.Header.Set("Cache-Control", "no-cache")
+    _, err = s.roundTrip(ctx, req, timeout)
+    return err
+}
@@ -0,0 +1,55 @@
+package scarf
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestTrackPixel(t *testing.T) {
+    var reqs []*http.Request
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        reqs = append(reqs, r)
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+    orig := pixelBaseURL
+    pixelBaseURL = srv.URL + "/a.png"
+    t.Cleanup(func() { pixelBaseURL = orig })
+
+    l := New("")
+    for i := 0; i < 2; i++ {
+        if err := l.TrackPixel("px-123", map[string]any{"page": "docs"}); err != nil {
+            t.Fatalf("TrackPixel: %v", err)
+        }
+    }
+    if len(reqs) != 2 {
+        t.Fatalf("expected 2 requests, got %d", len(reqs))
+    }
+    r := reqs[0]
+    q := r.URL.Query()
+    if r.Method != http.MethodGet || r.URL.Path != "/a.png" {
+        t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+    }
+    if q.Get("x-pxid") != "px-123" || q.Get("page") != "docs" {
+        t.Fatalf("unexpected query: %v", q)
+    }
+    if !strings.HasPrefix(r.Header.Get("User-Agent"), "scarf-go/") || r.Header.Get("Cache-Control") != "no-cache" {
+        t.Fatalf("unexpected headers: %v", r.Header)
+    }
+    if reqs[0].URL.RawQuery == reqs[1].URL.RawQuery {
+        t.Fatalf("expected each pixel URL to be unique for cache busting")
+    }
+}
+
+func TestTrackPixel_Gated(t *testing.T) {
+    if err := New("").TrackPixel("", nil); !errors.Is(err, ErrInvalidPixelID) {
+        t.Fatalf("expected ErrInvalidPixelID, got %v", err)
+    }
+    t.Setenv("DO_NOT_TRACK", "1")
+    if err := New("").TrackPixel("px", nil); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+}
@@ -0,0 +1,83 @@
+package scarf
+
+import (
+    "context"
+    "os"
+    "runtime"
+    "strings"
+    "sync"
+)
+
+// PlatformEnricher returns an Enricher with a standard platform fingerprint,
+// named consistently across every tool using scarf-go so dashboards are
+// comparable:
+//
+//   - os: operating system, as in the User-Agent ("linux", "macOS", "windows", ...)
+//   - os_version: kernel or OS release, when it can be determined cheaply
+//   - arch: CPU architecture (runtime.GOARCH)
+//   - go_version: Go toolchain version, without the "go" prefix
+//   - num_cpu: number of logical CPUs
+//   - locale: user locale from LC_ALL, LC_MESSAGES or LANG (e.g. "en_US")
+//
+// Unknown values are omitted.
+func PlatformEnricher() Enricher {
+    var once sync.Once
+    var props map[string]any
+    return EnricherFunc(func(context.Context) map[string]any {
+        once.Do(func() {
+            props = map[string]any{
+                "os":         platformName(),
+                "arch":       runtime.GOARCH,
+                "go_version": strings.TrimPrefix(runtime.Version(), "go"),
+                "num_cpu":    runtime.NumCPU(),
+            }
+            if v := kernelRelease(); v != "" {
+                props["os_version"] = v
+            }
+            if l := detectLocale(); l != "" {
+                props["locale"] = l
+            }
+        })
+        return props
+    })
+}
+
+// platformName returns runtime.GOOS with "darwin" spelled "macOS".
+func platformName() string {
+    if runtime.GOOS == "darwin" {
+        return "macOS"
+    }
+    return runtime.GOOS
+}
+
+// kernelRelease returns the kernel release where it is exposed as a file.
+func kernelRelease() string {
+    if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+        return ""
+    }
+    b, err := os.ReadFile("/proc/sys/kernel/osrelease")
+    if err != nil {
+        return ""
+    }
+    return strings.TrimSpace(string(b))
+}
+
+// detectLocale returns the POSIX locale name from the environment, without
+// encoding or modifier ("en_US.UTF-8@euro" -> "en_US"). The "C" and "POSIX"
+// locales are reported as unknown.
+func detectLocale() string {
+    for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+        v := strings.TrimSpace(os.Getenv(key))
+        if v == "" {
+            continue
+        }
+        if i := strings.IndexAny(v, ".@"); i >= 0 {
+            v = v[:i]
+        }
+        if v == "C" || v == "POSIX" || v == "" {
+            return ""
+        }
+        return v
+    }
+    return ""
+}
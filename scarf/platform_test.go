@@ -0,0 +1,48 @@
+package scarf
+
+import (
+    "context"
+    "runtime"
+    "testing"
+)
+
+func TestDetectLocale(t *testing.T) {
+    cases := []struct {
+        lcAll, lcMessages, lang string
+        want                    string
+    }{
+        {"", "", "en_US.UTF-8", "en_US"},
+        {"", "de_DE@euro", "en_US.UTF-8", "de_DE"},
+        {"fr_FR.UTF-8", "de_DE", "en_US", "fr_FR"},
+        {"C", "", "en_US", ""},
+        {"", "", "", ""},
+    }
+    for _, c := range cases {
+        t.Setenv("LC_ALL", c.lcAll)
+        t.Setenv("LC_MESSAGES", c.lcMessages)
+        t.Setenv("LANG", c.lang)
+        if got := detectLocale(); got != c.want {
+            t.Errorf("detectLocale(LC_ALL=%q, LC_MESSAGES=%q, LANG=%q) = %q, want %q", c.lcAll, c.lcMessages, c.lang, got, c.want)
+        }
+    }
+}
+
+func TestPlatformEnricher(t *testing.T) {
+    t.Setenv("LC_ALL", "")
+    t.Setenv("LC_MESSAGES", "")
+    t.Setenv("LANG", "pt_BR.UTF-8")
+
+    props := PlatformEnricher().Enrich(context.Background())
+    if props["arch"] != runtime.GOARCH || props["os"] != platformName() || props["num_cpu"] != runtime.NumCPU() {
+        t.Fatalf("unexpected platform properties %v", props)
+    }
+    if v, _ := props["go_version"].(string); v == "" || v[0] == 'g' {
+        t.Fatalf("expected go_version without prefix, got %v", props["go_version"])
+    }
+    if props["locale"] != "pt_BR" {
+        t.Fatalf("expected locale pt_BR, got %v", props["locale"])
+    }
+    if runtime.GOOS == "linux" && props["os_version"] == nil {
+        t.Fatalf("expected os_version on linux")
+    }
+}
@@ -0,0 +1,60 @@
+package scarf
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Policy controls whether events are sent when the user has made no explicit
+// consent decision. It applies only while analytics are enabled, which code
+// (WithEnabled, SetEnabled) decides over a config file, and a config file
+// over DO_NOT_TRACK and SCARF_NO_ANALYTICS (see SettingSource). Regardless of
+// policy, an explicit denial stops sending.
+type Policy int
+
+const (
+    // PolicyOptOut sends events unless the user has opted out. It is the default.
+    PolicyOptOut Policy = iota
+    // PolicyOptIn sends events only once the user has granted consent.
+    PolicyOptIn
+)
+
+func (p Policy) String() string {
+    if p == PolicyOptIn {
+        return "opt-in"
+    }
+    return "opt-out"
+}
+
+// ParsePolicy parses "opt-in" or "opt-out" (case-insensitive, "_" accepted in
+// place of "-").
+func ParsePolicy(s string) (Policy, error) {
+    switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "_", "-") {
+    case "opt-out", "optout":
+        return PolicyOptOut, nil
+    case "opt-in", "optin":
+        return PolicyOptIn, nil
+    }
+    return PolicyOptOut, fmt.Errorf("scarf: unknown policy %q", s)
+}
+
+// WithPolicy sets the consent policy.
+func WithPolicy(p Policy) Option {
+    return func(s *ScarfEventLogger) {
+        s.policy = p
+        s.mark("policy")
+    }
+}
+
+// WithOptIn is shorthand for WithPolicy(PolicyOptIn).
+func WithOptIn() Option {
+    return WithPolicy(PolicyOptIn)
+}
+
+// Policy returns the logger's consent policy.
+func (s *ScarfEventLogger) Policy() Policy {
+    if s == nil {
+        return PolicyOptOut
+    }
+    return s.policy
+}
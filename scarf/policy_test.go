@@ -0,0 +1,59 @@
+package scarf
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+    for in, want := range map[string]Policy{"opt-in": PolicyOptIn, "OPT_IN": PolicyOptIn, "optout": PolicyOptOut, "opt-out": PolicyOptOut} {
+        got, err := ParsePolicy(in)
+        if err != nil || got != want {
+            t.Errorf("ParsePolicy(%q) = %s, %v; want %s", in, got, err, want)
+        }
+    }
+    if _, err := ParsePolicy("sometimes"); err == nil {
+        t.Errorf("expected error for unknown policy")
+    }
+}
+
+func TestPolicy_SendsAbsentDecision(t *testing.T) {
+    sent := 0
+    out := New("", WithTransport(countingTransport(&sent)))
+    if out.Policy() != PolicyOptOut {
+        t.Fatalf("expected opt-out by default, got %s", out.Policy())
+    }
+    if err := out.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("opt-out should send without a decision, got %v", err)
+    }
+
+    in := New("", WithTransport(countingTransport(&sent)), WithPolicy(PolicyOptIn))
+    if err := in.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrNoConsent) {
+        t.Fatalf("opt-in should not send without a decision, got %v", err)
+    }
+    if sent != 1 {
+        t.Fatalf("expected 1 event sent, got %d", sent)
+    }
+}
+
+func TestPolicy_DoNotTrackWins(t *testing.T) {
+    t.Setenv("DO_NOT_TRACK", "1")
+    l := New("", WithPolicy(PolicyOptIn))
+    _ = l.SetConsent(true)
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("DO_NOT_TRACK should override granted consent, got %v", err)
+    }
+}
+
+func TestConfig_Policy(t *testing.T) {
+    l, err := NewFromConfig(Config{EndpointURL: "https://example.com", Policy: "opt-in"})
+    if err != nil {
+        t.Fatalf("NewFromConfig: %v", err)
+    }
+    if l.Policy() != PolicyOptIn {
+        t.Fatalf("expected opt-in policy from config, got %s", l.Policy())
+    }
+    if _, err := NewFromConfig(Config{EndpointURL: "https://example.com", Policy: "maybe"}); err == nil {
+        t.Fatalf("expected error for invalid policy")
+    }
+}
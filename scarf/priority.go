@@ -0,0 +1,106 @@
+package scarf
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// defaultBatchInterval is used by WithBatching for non-positive intervals.
+const defaultBatchInterval = 5 * time.Second
+
+// WithBatching makes LogEventAsync queue events and send them together, in
+// one LogEvents request, once interval has passed since the first was queued
+// or maxEvents (if positive) are waiting, whichever comes first. Each Receipt
+// finishes when its batch has been sent, and Flush and Close send whatever is
+// queued. Events marked with WithHighPriority skip the queue; synchronous Log
+// methods are never batched.
+func WithBatching(interval time.Duration, maxEvents int) Option {
+    return func(s *ScarfEventLogger) {
+        if interval <= 0 {
+            interval = defaultBatchInterval
+        }
+        s.batchInterval = interval
+        s.batchMax = maxEvents
+    }
+}
+
+// WithHighPriority marks the events with the given names (e.g. "uninstall")
+// as high priority: LogEventAsync sends them right away instead of waiting for
+// the next batch.
+func WithHighPriority(names ...string) Option {
+    return func(s *ScarfEventLogger) {
+        if s.highPriority == nil {
+            s.highPriority = make(map[string]bool, len(names))
+        }
+        for _, name := range names {
+            s.highPriority[name] = true
+        }
+    }
+}
+
+// batched reports whether LogEventAsync should queue ev rather than send it.
+func (s *ScarfEventLogger) batched(ev Event) bool {
+    return s.batchInterval > 0 && !s.highPriority[ev.Name]
+}
+
+// eventQueue holds events logged with LogEventAsync until their batch is
+// sent. Each queued event counts as a send in progress.
+type eventQueue struct {
+    mu       sync.Mutex
+    events   []Event
+    receipts []*Receipt
+    timer    Timer
+}
+
+// enqueue adds an event that has been through prepare to the queue, sending
+// the queue once it is full.
+func (s *ScarfEventLogger) enqueue(ev Event, r *Receipt) {
+    q := &s.queue
+    q.mu.Lock()
+    q.events = append(q.events, ev)
+    q.receipts = append(q.receipts, r)
+    full := s.batchMax > 0 && len(q.events) >= s.batchMax
+    if !full && q.timer == nil {
+        q.timer = s.clock.AfterFunc(s.batchInterval, func() { s.flushQueue(context.Background()) })
+    }
+    q.mu.Unlock()
+    if full {
+        go s.flushQueue(context.Background())
+    }
+}
+
+// flushQueue sends the queued events as one batch and finishes their
+// receipts with the result.
+func (s *ScarfEventLogger) flushQueue(ctx context.Context) {
+    q := &s.queue
+    q.mu.Lock()
+    events, receipts := q.events, q.receipts
+    q.events, q.receipts = nil, nil
+    if q.timer != nil {
+        q.timer.Stop()
+        q.timer = nil
+    }
+    q.mu.Unlock()
+    if len(events) == 0 {
+        return
+    }
+
+    // The logger may have been disabled or closed since the events were
+    // queued.
+    refused, err := s.refuse()
+    if !refused {
+        i := 0
+        err = s.sendPrepared(ctx, func() (Event, bool) {
+            if i >= len(events) {
+                return Event{}, false
+            }
+            i++
+            return events[i-1], true
+        }, false)
+    }
+    for _, r := range receipts {
+        r.finish(err)
+        s.inflight.done()
+    }
+}
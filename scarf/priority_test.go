@@ -0,0 +1,116 @@
+package scarf
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+    "time"
+)
+
+// batchRecorder records the size of each request: the number of events in a
+// JSON batch, or 0 for a single event.
+type batchRecorder struct {
+    mu    sync.Mutex
+    sizes []int
+}
+
+func (b *batchRecorder) handler(w http.ResponseWriter, r *http.Request) {
+    n := 0
+    if r.Header.Get("Content-Type") == "application/json" {
+        var events []map[string]any
+        _ = json.NewDecoder(r.Body).Decode(&events)
+        n = len(events)
+    }
+    b.mu.Lock()
+    b.sizes = append(b.sizes, n)
+    b.mu.Unlock()
+}
+
+func (b *batchRecorder) got() []int {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return append([]int(nil), b.sizes...)
+}
+
+func TestBatchingPriority(t *testing.T) {
+    var rec batchRecorder
+    srv := httptest.NewServer(http.HandlerFunc(rec.handler))
+    defer srv.Close()
+    l := New(srv.URL, WithBatching(time.Hour, 3), WithHighPriority("uninstall"))
+
+    r1 := l.LogEventAsync(map[string]any{"event": "usage"})
+    r2 := l.LogEventAsync(map[string]any{"event": "usage"})
+    if err := l.LogEventAsync(map[string]any{"event": "uninstall"}).Err(); err != nil {
+        t.Fatalf("high-priority event: %v", err)
+    }
+    if got := rec.got(); len(got) != 1 || got[0] != 0 {
+        t.Fatalf("expected only the high-priority event to be sent, got %v", got)
+    }
+    select {
+    case <-r1.Done():
+        t.Fatal("expected routine events to wait for the batch")
+    default:
+    }
+
+    r3 := l.LogEventAsync(map[string]any{"event": "usage"})
+    for _, r := range []*Receipt{r1, r2, r3} {
+        if err := r.Err(); err != nil {
+            t.Fatalf("batched event: %v", err)
+        }
+    }
+    if got := rec.got(); len(got) != 2 || got[1] != 3 {
+        t.Fatalf("expected a full batch of 3 to be sent, got %v", got)
+    }
+}
+
+func TestBatchingInterval(t *testing.T) {
+    var rec batchRecorder
+    srv := httptest.NewServer(http.HandlerFunc(rec.handler))
+    defer srv.Close()
+    l := New(srv.URL, WithBatching(20*time.Millisecond, 0))
+
+    r1 := l.LogEventAsync(map[string]any{"event": "a"})
+    r2 := l.LogEventAsync(map[string]any{"event": "b"})
+    if err := r1.Err(); err != nil {
+        t.Fatalf("batched event: %v", err)
+    }
+    if err := r2.Err(); err != nil {
+        t.Fatalf("batched event: %v", err)
+    }
+    if got := rec.got(); len(got) != 1 || got[0] != 2 {
+        t.Fatalf("expected one batch of 2 after the interval, got %v", got)
+    }
+}
+
+func TestBatchingFlushAndClose(t *testing.T) {
+    var rec batchRecorder
+    srv := httptest.NewServer(http.HandlerFunc(rec.handler))
+    defer srv.Close()
+    l := New(srv.URL, WithBatching(time.Hour, 0))
+
+    l.LogEventAsync(map[string]any{"event": "a"})
+    l.LogEventAsync(map[string]any{"event": "b"})
+    if l.Pending() != 2 {
+        t.Fatalf("expected queued events to be pending, got %d", l.Pending())
+    }
+    if err := l.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if got := rec.got(); len(got) != 1 || got[0] != 2 {
+        t.Fatalf("expected Flush to send the queue, got %v", got)
+    }
+
+    r := l.LogEventAsync(map[string]any{"event": "c"})
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if err := r.Err(); err != nil {
+        t.Fatalf("expected Close to send the queue, got %v", err)
+    }
+    if got := rec.got(); len(got) != 2 {
+        t.Fatalf("expected 2 requests, got %v", got)
+    }
+}
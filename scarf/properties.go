@@ -0,0 +1,80 @@
+package scarf
+
+import (
+    "sync"
+    "sync/atomic"
+)
+
+// WithDefaultProperties attaches props to every event, e.g. the application
+// version or a plan tier. Properties passed with an event win over defaults
+// with the same key. See SetDefaultProperty to change them later.
+func WithDefaultProperties(props map[string]any) Option {
+    return func(s *ScarfEventLogger) {
+        s.defaults.update(func(m map[string]any) {
+            for k, v := range props {
+                m[k] = v
+            }
+        })
+    }
+}
+
+// SetDefaultProperty sets the default property key, attached to every event
+// logged afterwards; a nil value removes it. It is safe to call concurrently
+// with sends, which see either the old or the new set of defaults.
+func (s *ScarfEventLogger) SetDefaultProperty(key string, value any) {
+    if s == nil {
+        return
+    }
+    s.defaults.update(func(m map[string]any) {
+        if value == nil {
+            delete(m, key)
+        } else {
+            m[key] = value
+        }
+    })
+}
+
+// defaultProperties is a copy-on-write map: sends read it without locking
+// and setters replace it whole, so a map once published is never modified.
+type defaultProperties struct {
+    mu sync.Mutex // serializes updates
+    m  atomic.Pointer[map[string]any]
+}
+
+func (d *defaultProperties) load() map[string]any {
+    if p := d.m.Load(); p != nil {
+        return *p
+    }
+    return nil
+}
+
+func (d *defaultProperties) update(fn func(map[string]any)) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    m := copyProperties(d.load())
+    fn(m)
+    d.m.Store(&m)
+}
+
+// withDefaults returns props layered over the default properties. It returns
+// props itself when there are no defaults.
+func (s *ScarfEventLogger) withDefaults(props map[string]any) map[string]any {
+    if len(s.defaults.load()) == 0 {
+        return props
+    }
+    return s.copyWithDefaults(props)
+}
+
+// copyWithDefaults returns a new map holding props layered over the default
+// properties.
+func (s *ScarfEventLogger) copyWithDefaults(props map[string]any) map[string]any {
+    defaults := s.defaults.load()
+    out := make(map[string]any, len(defaults)+len(props))
+    for k, v := range defaults {
+        out[k] = v
+    }
+    for k, v := range props {
+        out[k] = v
+    }
+    return out
+}
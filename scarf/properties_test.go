@@ -0,0 +1,28 @@
+package scarf
+
+import (
+    "testing"
+)
+
+func TestDefaultProperties(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithDefaultProperties(map[string]any{"app_version": "1.2.0", "plan": "free"}), WithRedactedKeys([]string{"plan"}))
+    l.SetDefaultProperty("session", "s1")
+    props := map[string]any{"event": "x", "app_version": "override"}
+    if err := l.LogEvent(props); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    p := got[0].Properties
+    if p["app_version"] != "override" || p["session"] != "s1" || p["plan"] != RedactedValue {
+        t.Fatalf("expected defaults under the event's own properties, got %v", p)
+    }
+    if len(props) != 2 {
+        t.Fatalf("defaults leaked into the caller's map: %v", props)
+    }
+
+    l.SetDefaultProperty("session", nil)
+    _ = l.LogEvent(map[string]any{"event": "y"})
+    if _, ok := got[1].Properties["session"]; ok {
+        t.Fatalf("expected the removed default to be gone, got %v", got[1].Properties)
+    }
+}
@@ -0,0 +1,40 @@
+package scarf
+
+import (
+    "fmt"
+    "net/http"
+    "net/url"
+)
+
+// WithProxy sends requests through the proxy at rawURL, which may use the
+// http, https, socks5 or socks5h scheme. Without it the SDK honors the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables, which may name
+// a SOCKS5 proxy too. An explicit proxy applies to every request.
+//
+// WithProxy configures the client the SDK constructs; it has no effect
+// together with WithHTTPClient. An invalid URL makes every send fail.
+func WithProxy(rawURL string) Option {
+    return func(s *ScarfEventLogger) {
+        s.proxy = proxyFunc(rawURL)
+    }
+}
+
+// proxyFunc returns an http.Transport Proxy function for rawURL.
+func proxyFunc(rawURL string) func(*http.Request) (*url.URL, error) {
+    u, err := url.Parse(rawURL)
+    if err == nil {
+        switch u.Scheme {
+        case "http", "https", "socks5", "socks5h":
+            if u.Host == "" {
+                err = fmt.Errorf("missing host in %q", rawURL)
+            }
+        default:
+            err = fmt.Errorf("unsupported scheme in %q", rawURL)
+        }
+    }
+    if err != nil {
+        err = fmt.Errorf("scarf: invalid proxy URL: %w", err)
+        return func(*http.Request) (*url.URL, error) { return nil, err }
+    }
+    return http.ProxyURL(u)
+}
@@ -0,0 +1,43 @@
+package scarf
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestWithProxy(t *testing.T) {
+    var proxied string
+    proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        // A forward proxy receives the absolute target URL.
+        proxied = r.URL.String()
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer proxy.Close()
+
+    l := New("http://collector.invalid/events", WithProxy(proxy.URL))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if !strings.HasPrefix(proxied, "http://collector.invalid/events?") {
+        t.Fatalf("expected request to go through the proxy, got %q", proxied)
+    }
+}
+
+func TestWithProxy_Invalid(t *testing.T) {
+    for _, u := range []string{"ftp://proxy:21", "socks5://", "://bad"} {
+        err := New("http://collector.invalid/events", WithProxy(u)).LogEvent(map[string]any{"event": "x"})
+        if err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+            t.Errorf("WithProxy(%q): expected invalid proxy error, got %v", u, err)
+        }
+    }
+}
+
+func TestProxyFunc_SOCKS5(t *testing.T) {
+    req, _ := http.NewRequest(http.MethodPost, "https://scarf.sh/", nil)
+    u, err := proxyFunc("socks5://127.0.0.1:1080")(req)
+    if err != nil || u.String() != "socks5://127.0.0.1:1080" {
+        t.Fatalf("unexpected proxy %v (err=%v)", u, err)
+    }
+}
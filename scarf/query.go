@@ -0,0 +1,150 @@
+package scarf
+
+import (
+    "net/url"
+    "slices"
+    "sync"
+    "time"
+)
+
+// parsedEndpoint caches what encodeHTTP needs from an endpoint URL, so the
+// URL isn't parsed again for every event.
+type parsedEndpoint struct {
+    base   string     // the URL without its query string
+    params url.Values // the endpoint's own query parameters
+    fast   bool       // whether queryURL can build the URL
+}
+
+// endpointCache maps endpoint URLs to their *parsedEndpoint. A logger only
+// ever sees a handful of endpoints (primary, fallbacks and routes).
+type endpointCache struct {
+    m sync.Map
+}
+
+func (c *endpointCache) get(endpoint string) (*parsedEndpoint, error) {
+    if p, ok := c.m.Load(endpoint); ok {
+        return p.(*parsedEndpoint), nil
+    }
+    u, err := url.Parse(endpoint)
+    if err != nil {
+        return nil, err
+    }
+    p := &parsedEndpoint{params: u.Query(), fast: u.Fragment == "" && !u.ForceQuery}
+    u.RawQuery = ""
+    p.base = u.String()
+    c.m.Store(endpoint, p)
+    return p, nil
+}
+
+var (
+    queryBufPool  = sync.Pool{New: func() any { b := make([]byte, 0, 512); return &b }}
+    queryKeysPool = sync.Pool{New: func() any { k := make([]string, 0, 16); return &k }}
+)
+
+// queryURL returns the endpoint URL with ev's query parameters merged
+// into the endpoint's own. The result is identical to setting those values
+// on url.Values and calling Encode, but reuses buffers instead of building
+// maps.
+func (p *parsedEndpoint) queryURL(ev Event) string {
+    kp := queryKeysPool.Get().(*[]string)
+    keys := (*kp)[:0]
+    for k := range ev.Properties {
+        keys = append(keys, k)
+    }
+    for _, k := range [...]string{"event", "timestamp", "event_id"} {
+        if _, ok := ev.Properties[k]; !ok && ev.hasReserved(k) {
+            keys = append(keys, k)
+        }
+    }
+    for k := range p.params {
+        if _, ok := ev.Properties[k]; !ok && !ev.hasReserved(k) {
+            keys = append(keys, k)
+        }
+    }
+    slices.Sort(keys)
+
+    bp := queryBufPool.Get().(*[]byte)
+    b := append((*bp)[:0], p.base...)
+    b = append(b, '?')
+    start := len(b)
+    for _, k := range keys {
+        if ev.hasReserved(k) || hasKey(ev.Properties, k) {
+            if len(b) > start {
+                b = append(b, '&')
+            }
+            b = appendQueryEscape(b, k)
+            b = append(b, '=')
+            b = ev.appendQueryValue(b, k)
+            continue
+        }
+        for _, v := range p.params[k] {
+            if len(b) > start {
+                b = append(b, '&')
+            }
+            b = appendQueryEscape(b, k)
+            b = append(b, '=')
+            b = appendQueryEscape(b, v)
+        }
+    }
+    if len(b) == start {
+        b = b[:start-1] // no parameters: drop the '?'
+    }
+    out := string(b)
+
+    *bp = b[:0]
+    queryBufPool.Put(bp)
+    *kp = keys[:0]
+    queryKeysPool.Put(kp)
+    return out
+}
+
+func hasKey(m map[string]any, k string) bool {
+    _, ok := m[k]
+    return ok
+}
+
+// hasReserved reports whether k is a reserved parameter that ev sets.
+func (e Event) hasReserved(k string) bool {
+    switch k {
+    case "event":
+        return e.Name != ""
+    case "timestamp":
+        return !e.Timestamp.IsZero()
+    case "event_id":
+        return e.ID != ""
+    }
+    return false
+}
+
+// appendQueryValue appends the escaped value of ev's parameter k, as
+// queryValues would set it.
+func (e Event) appendQueryValue(b []byte, k string) []byte {
+    switch {
+    case k == "event" && e.Name != "":
+        return appendQueryEscape(b, e.Name)
+    case k == "timestamp" && !e.Timestamp.IsZero():
+        var tb [64]byte
+        return appendQueryEscape(b, e.Timestamp.UTC().AppendFormat(tb[:0], time.RFC3339Nano))
+    case k == "event_id" && e.ID != "":
+        return appendQueryEscape(b, e.ID)
+    }
+    return appendQueryEscape(b, stringifyParam(e.Properties[k]))
+}
+
+// appendQueryEscape appends s escaped as by url.QueryEscape.
+func appendQueryEscape[T string | []byte](b []byte, s T) []byte {
+    const hex = "0123456789ABCDEF"
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        switch {
+        case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+            c == '-', c == '_', c == '.', c == '~':
+            b = append(b, c)
+        case c == ' ':
+            b = append(b, '+')
+        default:
+            b = append(b, '%', hex[c>>4], hex[c&15])
+        }
+    }
+    return b
+}
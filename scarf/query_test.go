@@ -0,0 +1,35 @@
+package scarf
+
+import (
+    "net/url"
+    "testing"
+    "time"
+)
+
+func TestQueryURLMatchesEncode(t *testing.T) {
+    ts := time.Date(2024, 5, 1, 12, 30, 0, 123, time.FixedZone("X", 3600))
+    events := []Event{
+        {},
+        {Name: "x", ID: "id-1", Timestamp: ts},
+        {Name: "a b&c", Properties: map[string]any{"é": "ü/?#", "n": 3, "f": 1.5, "b": true, "nil": nil, "tags": []string{"a", "b"}}},
+        {Name: "x", Properties: map[string]any{"event": "shadowed", "pkg": "override", "timestamp": "kept"}},
+    }
+    for _, endpoint := range []string{"https://example.com", "https://example.com/e?pkg=abc&multi=1&multi=2", "https://u:p@example.com:8080/a%20b/?pkg="} {
+        u, _ := url.Parse(endpoint)
+        p, err := (&endpointCache{}).get(endpoint)
+        if err != nil {
+            t.Fatal(err)
+        }
+        for _, ev := range events {
+            q := u.Query()
+            for k, vs := range ev.queryValues() {
+                q[k] = vs
+            }
+            want := *u
+            want.RawQuery = q.Encode()
+            if got := p.queryURL(ev); got != want.String() {
+                t.Errorf("queryURL(%s, %+v)\n got: %s\nwant: %s", endpoint, ev, got, want.String())
+            }
+        }
+    }
+}
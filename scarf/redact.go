@@ -0,0 +1,53 @@
+package scarf
+
+import (
+    "strings"
+)
+
+// RedactedValue replaces the value of properties matched by WithRedactedKeys.
+const RedactedValue = "[REDACTED]"
+
+// WithAllowedKeys transmits only the listed property keys; all others are
+// dropped before the request is built, including those from enrichers.
+// Matching is case-insensitive. Properties the SDK adds itself (event, timestamp, event_id, install_id) are
+// not affected.
+func WithAllowedKeys(keys []string) Option {
+    return func(s *ScarfEventLogger) {
+        s.allowedKeys = keySet(keys)
+    }
+}
+
+// WithRedactedKeys masks the values of the listed property keys with
+// RedactedValue, so sensitive data such as tokens, emails or paths never
+// leaves the machine even if a call site passes it. Matching is
+// case-insensitive.
+func WithRedactedKeys(keys []string) Option {
+    return func(s *ScarfEventLogger) {
+        s.redactedKeys = keySet(keys)
+    }
+}
+
+func keySet(keys []string) map[string]bool {
+    set := make(map[string]bool, len(keys))
+    for _, k := range keys {
+        set[strings.ToLower(strings.TrimSpace(k))] = true
+    }
+    return set
+}
+
+// filterProperties applies the allowlist and redaction rules to props in place.
+func (s *ScarfEventLogger) filterProperties(props map[string]any) {
+    if s.allowedKeys == nil && len(s.redactedKeys) == 0 {
+        return
+    }
+    for k := range props {
+        lk := strings.ToLower(k)
+        if s.allowedKeys != nil && !s.allowedKeys[lk] {
+            delete(props, k)
+            continue
+        }
+        if s.redactedKeys[lk] {
+            props[k] = RedactedValue
+        }
+    }
+}
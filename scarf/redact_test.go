@@ -0,0 +1,69 @@
+package scarf
+
+import (
+    "context"
+    "testing"
+)
+
+func captureEvents(got *[]Event) Option {
+    return WithTransport(TransportFunc(func(_ context.Context, ev Event) error {
+        *got = append(*got, ev)
+        return nil
+    }))
+}
+
+func TestAllowedKeys(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithAllowedKeys([]string{"version", "OS"}))
+    props := map[string]any{"event": "x", "version": "1.0", "os": "linux", "email": "a@b.c"}
+    if err := l.LogEvent(props); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    p := got[0].Properties
+    if len(p) != 2 || p["version"] != "1.0" || p["os"] != "linux" {
+        t.Fatalf("expected only allowed keys, got %v", p)
+    }
+    if got[0].Name != "x" || got[0].ID == "" {
+        t.Fatalf("SDK fields should survive the allowlist, got %+v", got[0])
+    }
+    if len(props) != 4 {
+        t.Fatalf("caller's map must not be modified")
+    }
+}
+
+func TestRedactedKeys(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithRedactedKeys([]string{"token", "Email"}))
+    if err := l.LogEvent(map[string]any{"token": "s3cr3t", "EMAIL": "a@b.c", "n": 1}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    p := got[0].Properties
+    if p["token"] != RedactedValue || p["EMAIL"] != RedactedValue || p["n"] != 1 {
+        t.Fatalf("unexpected properties %v", p)
+    }
+}
+
+func TestAllowedKeys_Enrichers(t *testing.T) {
+    var got []Event
+    enricher := EnricherFunc(func(context.Context) map[string]any {
+        return map[string]any{"os": "linux", "home": "/home/alice/project", "token": "s3cr3t"}
+    })
+    l := New("", captureEvents(&got), WithEnrichers(enricher), WithAllowedKeys([]string{"os", "home", "token"}),
+        WithRedactedKeys([]string{"token"}), WithPIIScrubbing())
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    p := got[0].Properties
+    if p["os"] != "linux" || p["token"] != RedactedValue || p["home"] == "/home/alice/project" {
+        t.Fatalf("expected enricher output to be filtered and scrubbed, got %v", p)
+    }
+
+    got = nil
+    l = New("", captureEvents(&got), WithEnrichers(enricher), WithAllowedKeys([]string{"os"}))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if p := got[0].Properties; len(p) != 1 || p["os"] != "linux" {
+        t.Fatalf("expected enricher keys outside the allowlist to be dropped, got %v", p)
+    }
+}
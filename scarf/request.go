@@ -0,0 +1,136 @@
+package scarf
+
+import (
+    "bytes"
+    "compress/gzip"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "runtime"
+)
+
+// WithGzipThreshold gzip-compresses request bodies larger than threshold
+// bytes and marks them with Content-Encoding: gzip. Compression is disabled by
+// default and when threshold is negative.
+//
+// Query-parameter events carry no body and are unaffected; see
+// WithMaxPayloadSize for when events are sent as a body.
+func WithGzipThreshold(threshold int) Option {
+    return func(s *ScarfEventLogger) {
+        s.gzipThreshold = threshold
+    }
+}
+
+// newRequest builds a request to rawURL carrying body (which may be nil) with
+// the SDK's standard headers.
+func (s *ScarfEventLogger) newRequest(method, rawURL string, body []byte, contentType string) (*http.Request, error) {
+    gzipped := false
+    if body != nil && s.gzipThreshold >= 0 && len(body) > s.gzipThreshold {
+        compressed, err := gzipBytes(body)
+        if err != nil {
+            return nil, fmt.Errorf("gzip body: %w", err)
+        }
+        body = compressed
+        gzipped = true
+    }
+
+    var req *http.Request
+    var err error
+    if body != nil {
+        req, err = http.NewRequest(method, rawURL, bytes.NewReader(body))
+    } else {
+        req, err = http.NewRequest(method, rawURL, nil)
+    }
+    if err != nil {
+        return nil, err
+    }
+    s.setHeaders(req, body != nil, contentType, gzipped)
+    if s.signingKey != nil {
+        signRequest(req, body, s.signingKey, s.clock.Now())
+    }
+    return req, nil
+}
+
+// newStreamRequest is newRequest for a body of unknown length, which is
+// gzip-compressed on the fly when compression is enabled. Stream requests
+// can't be signed.
+func (s *ScarfEventLogger) newStreamRequest(method, rawURL string, body io.Reader, contentType string) (*http.Request, error) {
+    gzipped := s.gzipThreshold >= 0
+    if gzipped {
+        pr, pw := io.Pipe()
+        src := body
+        go func() {
+            zw := gzip.NewWriter(pw)
+            _, err := io.Copy(zw, src)
+            if cerr := zw.Close(); err == nil {
+                err = cerr
+            }
+            pw.CloseWithError(err)
+        }()
+        body = pr
+    }
+    req, err := http.NewRequest(method, rawURL, body)
+    if err != nil {
+        return nil, err
+    }
+    s.setHeaders(req, true, contentType, gzipped)
+    return req, nil
+}
+
+// setHeaders sets the SDK's standard headers on req.
+func (s *ScarfEventLogger) setHeaders(req *http.Request, hasBody bool, contentType string, gzipped bool) {
+    req.Header.Set("User-Agent", s.userAgent())
+    if s.apiKey != "" {
+        req.Header.Set("Authorization", "Bearer "+s.apiKey)
+    }
+    if hasBody && contentType != "" {
+        req.Header.Set("Content-Type", contentType)
+    }
+    if gzipped {
+        req.Header.Set("Content-Encoding", "gzip")
+    }
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+    var buf bytes.Buffer
+    zw := gzip.NewWriter(&buf)
+    if _, err := zw.Write(b); err != nil {
+        return nil, err
+    }
+    if err := zw.Close(); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+// maxIdleConnsPerHost bounds the kept-alive connections to each endpoint. The
+// net/http default of 2 makes concurrent senders re-dial (and redo the TLS
+// handshake) as soon as more than two requests overlap.
+const maxIdleConnsPerHost = 16
+
+// newTransport returns the http.RoundTripper for the SDK's own client.
+func (s *ScarfEventLogger) newTransport() http.RoundTripper {
+    base, ok := http.DefaultTransport.(*http.Transport)
+    if !ok {
+        return http.DefaultTransport
+    }
+    t := base.Clone()
+    t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+    t.Proxy = http.ProxyFromEnvironment
+    if s.proxy != nil {
+        t.Proxy = s.proxy
+    }
+    if cfg := s.tls.config(); cfg != nil {
+        t.TLSClientConfig = cfg
+    }
+    // Under js/wasm, setting a dialer would bypass fetch.
+    if s.dns != nil && runtime.GOOS != "js" {
+        dial := t.DialContext
+        if dial == nil {
+            dial = (&net.Dialer{}).DialContext
+        }
+        t.DialContext = s.dns.dialContext(dial)
+    }
+    return t
+}
@@ -0,0 +1,105 @@
+package scarf
+
+import (
+    "bytes"
+    "compress/gzip"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestNewRequest_Gzip(t *testing.T) {
+    l := New("https://example.com", WithGzipThreshold(16))
+    body := []byte(strings.Repeat(`{"event":"x"}`, 10))
+
+    req, err := l.newRequest(http.MethodPost, "https://example.com", body, "application/json")
+    if err != nil {
+        t.Fatalf("newRequest: %v", err)
+    }
+    if got := req.Header.Get("Content-Encoding"); got != "gzip" {
+        t.Fatalf("expected gzip content encoding, got %q", got)
+    }
+    if got := req.Header.Get("Content-Type"); got != "application/json" {
+        t.Fatalf("expected content type to be kept, got %q", got)
+    }
+    zr, err := gzip.NewReader(req.Body)
+    if err != nil {
+        t.Fatalf("body is not gzip: %v", err)
+    }
+    plain, _ := io.ReadAll(zr)
+    if !bytes.Equal(plain, body) {
+        t.Fatalf("decompressed body mismatch: %q", plain)
+    }
+}
+
+func TestNewRequest_GzipThreshold(t *testing.T) {
+    small := []byte(`{"event":"x"}`)
+    for name, l := range map[string]*ScarfEventLogger{
+        "default": New("https://example.com"),
+        "below":   New("https://example.com", WithGzipThreshold(len(small))),
+    } {
+        req, err := l.newRequest(http.MethodPost, "https://example.com", small, "application/json")
+        if err != nil {
+            t.Fatalf("%s: newRequest: %v", name, err)
+        }
+        if got := req.Header.Get("Content-Encoding"); got != "" {
+            t.Fatalf("%s: expected no content encoding, got %q", name, got)
+        }
+    }
+
+    req, err := New("https://example.com", WithGzipThreshold(0)).newRequest(http.MethodPost, "https://example.com", nil, "")
+    if err != nil {
+        t.Fatalf("newRequest: %v", err)
+    }
+    if req.Body != nil || req.Header.Get("Content-Encoding") != "" {
+        t.Fatalf("requests without a body must not be compressed")
+    }
+}
+
+func TestConnectionReuse(t *testing.T) {
+    var conns atomic.Int64
+    srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(5 * time.Millisecond)
+    }))
+    srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+        if state == http.StateNew {
+            conns.Add(1)
+        }
+    }
+    srv.Start()
+    defer srv.Close()
+
+    l := New(srv.URL, WithTimeout(time.Second))
+    for i := 0; i < 10; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+            t.Fatalf("LogEvent: %v", err)
+        }
+    }
+    if n := conns.Load(); n != 1 {
+        t.Fatalf("expected sequential sends to share one connection, got %d", n)
+    }
+
+    // Concurrent senders keep more than the net/http default of two
+    // connections alive between rounds.
+    const senders = 8
+    for round := 0; round < 3; round++ {
+        var wg sync.WaitGroup
+        for i := 0; i < senders; i++ {
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                _ = l.LogEvent(map[string]any{"event": "x"})
+            }()
+        }
+        wg.Wait()
+    }
+    if n := conns.Load(); n > senders+1 {
+        t.Fatalf("expected at most %d connections, got %d", senders+1, n)
+    }
+}
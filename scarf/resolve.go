@@ -0,0 +1,134 @@
+package scarf
+
+import (
+    "errors"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// ResolvedSetting is the effective value of one setting and the layer that
+// set it.
+type ResolvedSetting struct {
+    // Key is the setting's config file key, e.g. "timeout".
+    Key string
+    // Value is the effective value as text. A configured API key is shown
+    // as "REDACTED".
+    Value  string
+    Source SettingSource
+}
+
+// ResolvedConfig reports the effective value of every setting a config file
+// can hold and where it came from, to make multi-source configuration
+// debuggable:
+//
+//   for _, st := range logger.ResolvedConfig() {
+//       fmt.Printf("%s = %s (%s)\n", st.Key, st.Value, st.Source)
+//   }
+//
+// A setting's source is the last layer that set it (see NewFromSources), or
+// SourceDefault if none did. "enabled" reports EnabledBy.
+func (s *ScarfEventLogger) ResolvedConfig() []ResolvedSetting {
+    if s == nil {
+        return nil
+    }
+    apiKey := ""
+    if s.apiKey != "" {
+        apiKey = "REDACTED"
+    }
+    sampleRate := 1.0
+    if s.sampling {
+        sampleRate = s.sampleRate
+    }
+    settings := []ResolvedSetting{
+        {Key: "endpoint_url", Value: s.endpointURL},
+        {Key: "api_key", Value: apiKey},
+        {Key: "timeout", Value: s.defaultTimeout.String()},
+        {Key: "sample_rate", Value: strconv.FormatFloat(sampleRate, 'g', -1, 64)},
+        {Key: "log_level", Value: s.logLevel.String()},
+        {Key: "policy", Value: s.policy.String()},
+    }
+    for i := range settings {
+        settings[i].Source = s.sources[settings[i].Key]
+    }
+    return append(settings, ResolvedSetting{Key: "enabled", Value: strconv.FormatBool(s.Enabled()), Source: s.EnabledBy()})
+}
+
+// NewFromSources creates a logger from layered configuration, each layer
+// overriding the settings it sets in the one before:
+//
+//   1. defaults
+//   2. the config file at path (see LoadConfig), skipped if path is "" or
+//      the file doesn't exist
+//   3. the SCARF_* environment variables read by NewFromEnv, and
+//      SCARF_VERBOSE
+//   4. opts
+//
+// Whether analytics are enabled is decided as described at SettingSource.
+// ResolvedConfig reports where each effective value came from.
+func NewFromSources(path string, opts ...Option) (*ScarfEventLogger, error) {
+    var fileCfg Config
+    if path != "" {
+        var err error
+        if fileCfg, err = LoadConfig(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+            return nil, err
+        }
+    }
+    envCfg, err := configFromEnv()
+    if err != nil {
+        return nil, err
+    }
+    if v := strings.TrimSpace(os.Getenv("SCARF_VERBOSE")); v != "" {
+        level, err := ParseLogLevel(v)
+        if err != nil {
+            return nil, err
+        }
+        envCfg.LogLevel = &level
+    }
+    return newFromLayers([]configLayer{{SourceConfig, fileCfg}, {SourceEnv, envCfg}}, opts...)
+}
+
+// configLayer is one source of configuration.
+type configLayer struct {
+    source SettingSource
+    cfg    Config
+}
+
+// newFromLayers creates a logger from layers, applied in order, followed by
+// opts.
+func newFromLayers(layers []configLayer, opts ...Option) (*ScarfEventLogger, error) {
+    var all []Option
+    for _, l := range layers {
+        if err := l.cfg.validateValues(); err != nil {
+            return nil, err
+        }
+        all = append(all, settingLayer(l.source))
+        if ep := strings.TrimSpace(l.cfg.EndpointURL); ep != "" {
+            all = append(all, WithEndpointURL(ep))
+        }
+        all = append(all, l.cfg.options()...)
+    }
+    all = append(all, settingLayer(SourceCode))
+    s := New("", append(all, opts...)...)
+    if strings.TrimSpace(s.endpointURL) == "" {
+        s.Close()
+        return nil, ErrNoEndpoint
+    }
+    return s, nil
+}
+
+// settingLayer attributes the settings made by the options that follow it
+// to src.
+func settingLayer(src SettingSource) Option {
+    return func(s *ScarfEventLogger) {
+        s.optSource = src
+    }
+}
+
+// mark records that the option being applied set the setting key.
+func (s *ScarfEventLogger) mark(key string) {
+    if s.sources == nil {
+        s.sources = make(map[string]SettingSource)
+    }
+    s.sources[key] = s.optSource
+}
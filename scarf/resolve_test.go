@@ -0,0 +1,105 @@
+package scarf
+
+import (
+    "errors"
+    "path/filepath"
+    "testing"
+)
+
+func resolved(l *ScarfEventLogger) map[string]ResolvedSetting {
+    m := map[string]ResolvedSetting{}
+    for _, st := range l.ResolvedConfig() {
+        m[st.Key] = st
+    }
+    return m
+}
+
+func TestNewFromSources(t *testing.T) {
+    clearCIEnv(t)
+    t.Setenv("DO_NOT_TRACK", "")
+    t.Setenv("SCARF_NO_ANALYTICS", "")
+    t.Setenv("SCARF_ENDPOINT_URL", "")
+    t.Setenv("SCARF_API_KEY", "")
+    t.Setenv("SCARF_SAMPLE_RATE", "")
+    t.Setenv("SCARF_TIMEOUT", "7s")
+    t.Setenv("SCARF_VERBOSE", "info")
+    path := writeConfig(t, "scarf.toml", `
+endpoint_url = "https://example.com/e"
+timeout = "5s"
+sample_rate = 0.5
+log_level = "trace"
+policy = "opt-in"
+`)
+
+    l, err := NewFromSources(path, WithSampleRate(0.25))
+    if err != nil {
+        t.Fatalf("NewFromSources: %v", err)
+    }
+    want := map[string]ResolvedSetting{
+        "endpoint_url": {Value: "https://example.com/e", Source: SourceConfig},
+        "api_key":      {Value: "", Source: SourceDefault},
+        "timeout":      {Value: "7s", Source: SourceEnv},
+        "sample_rate":  {Value: "0.25", Source: SourceCode},
+        "log_level":    {Value: "info", Source: SourceEnv},
+        "policy":       {Value: "opt-in", Source: SourceConfig},
+        "enabled":      {Value: "true", Source: SourceDefault},
+    }
+    got := resolved(l)
+    if len(got) != len(want) {
+        t.Fatalf("expected %d settings, got %v", len(want), got)
+    }
+    for key, w := range want {
+        if g := got[key]; g.Value != w.Value || g.Source != w.Source {
+            t.Errorf("%s: expected %q from %s, got %q from %s", key, w.Value, w.Source, g.Value, g.Source)
+        }
+    }
+}
+
+func TestNewFromSourcesMissingFile(t *testing.T) {
+    t.Setenv("SCARF_ENDPOINT_URL", "https://example.com/env")
+    t.Setenv("SCARF_API_KEY", "secret")
+    l, err := NewFromSources(filepath.Join(t.TempDir(), "missing.yaml"))
+    if err != nil {
+        t.Fatalf("expected a missing config file to be skipped, got %v", err)
+    }
+    got := resolved(l)
+    if got["endpoint_url"].Source != SourceEnv || got["api_key"].Value != "REDACTED" {
+        t.Fatalf("unexpected resolution %v", got)
+    }
+
+    t.Setenv("SCARF_ENDPOINT_URL", "")
+    if _, err := NewFromSources(""); !errors.Is(err, ErrNoEndpoint) {
+        t.Fatalf("expected ErrNoEndpoint without an endpoint, got %v", err)
+    }
+}
+
+func TestNewFromSourcesEndpointOption(t *testing.T) {
+    t.Setenv("SCARF_ENDPOINT_URL", "")
+    l, err := NewFromSources("", WithEndpointURL("https://example.com/code"))
+    if err != nil {
+        t.Fatalf("expected WithEndpointURL to satisfy the endpoint requirement, got %v", err)
+    }
+    if got := resolved(l)["endpoint_url"]; got.Value != "https://example.com/code" || got.Source != SourceCode {
+        t.Fatalf("expected the endpoint from code, got %q from %s", got.Value, got.Source)
+    }
+
+    t.Setenv("SCARF_ENDPOINT_URL", "https://example.com/env")
+    l, err = NewFromSources("", WithEndpointURL("https://example.com/code"))
+    if err != nil {
+        t.Fatalf("NewFromSources: %v", err)
+    }
+    if got := resolved(l)["endpoint_url"]; got.Value != "https://example.com/code" || got.Source != SourceCode {
+        t.Fatalf("expected WithEndpointURL to override the environment, got %q from %s", got.Value, got.Source)
+    }
+}
+
+func TestResolvedConfigNew(t *testing.T) {
+    t.Setenv("SCARF_VERBOSE", "")
+    got := resolved(New("https://example.com/e", WithAPIKey("k")))
+    if got["endpoint_url"].Source != SourceCode || got["api_key"].Source != SourceCode {
+        t.Fatalf("expected New's settings to come from code, got %v", got)
+    }
+    if got["timeout"].Source != SourceDefault || got["timeout"].Value != "3s" {
+        t.Fatalf("expected the default timeout, got %v", got["timeout"])
+    }
+}
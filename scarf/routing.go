@@ -0,0 +1,61 @@
+package scarf
+
+import (
+    "context"
+    "path"
+    "strings"
+    "time"
+)
+
+// Route sends events whose name matches Pattern somewhere other than the
+// logger's default destination.
+type Route struct {
+    // Pattern is matched against the event name with path.Match, so
+    // "error.*" matches "error.io" and "error.parse". An event with no name
+    // matches only "".
+    Pattern string
+    // Transport, if set, delivers matching events.
+    Transport Transport
+    // Endpoint is the URL matching events are sent to when Transport is nil.
+    Endpoint string
+}
+
+// WithRoutes sends events to the first route whose pattern matches the event
+// name; events matching no route use the logger's transport or endpoint.
+// Routed events pass through the same pipeline (filtering, enrichment,
+// sampling, consent) as all others. Routes with malformed patterns never
+// match.
+//
+//   scarf.WithRoutes(
+//       scarf.Route{Pattern: "error.*", Endpoint: "https://errors.example.com/events"},
+//       scarf.Route{Pattern: "debug.*", Transport: localTransport},
+//   )
+func WithRoutes(routes ...Route) Option {
+    return func(s *ScarfEventLogger) {
+        s.routes = append(s.routes, routes...)
+    }
+}
+
+// route returns the first route matching name.
+func (s *ScarfEventLogger) route(name string) (Route, bool) {
+    for _, r := range s.routes {
+        if ok, err := path.Match(r.Pattern, name); err == nil && ok {
+            return r, true
+        }
+    }
+    return Route{}, false
+}
+
+// sendRoute delivers ev according to r.
+func (s *ScarfEventLogger) sendRoute(ctx context.Context, r Route, ev Event, timeout time.Duration) error {
+    s.debug("routing event", "event", ev.Name, "pattern", r.Pattern)
+    if r.Transport != nil {
+        return s.sendTransport(ctx, r.Transport, ev, timeout)
+    }
+    if strings.TrimSpace(r.Endpoint) == "" {
+        s.error("route has no endpoint or transport", "pattern", r.Pattern)
+        return ErrNoEndpoint
+    }
+    _, err := s.sendHTTPTo(ctx, r.Endpoint, ev, timeout)
+    return err
+}
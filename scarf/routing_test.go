@@ -0,0 +1,51 @@
+package scarf
+
+import (
+    "context"
+    "net/http"
+    "sync/atomic"
+    "testing"
+)
+
+func TestWithRoutes(t *testing.T) {
+    var errorsHits atomic.Int32
+    errorsSrv := statusServer(t, http.StatusOK, &errorsHits)
+
+    var routed, unrouted []string
+    l := New("",
+        WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+            unrouted = append(unrouted, ev.Name)
+            return nil
+        })),
+        WithRoutes(
+            Route{Pattern: "error.*", Endpoint: errorsSrv.URL},
+            Route{Pattern: "usage.*", Transport: TransportFunc(func(ctx context.Context, ev Event) error {
+                routed = append(routed, ev.Name)
+                return nil
+            })},
+            Route{Pattern: "[", Endpoint: "https://never.example.com"},
+        ),
+    )
+
+    for _, name := range []string{"error.io", "usage.start", "usage.stop", "install", ""} {
+        if err := l.LogEvent(map[string]any{"event": name}); err != nil {
+            t.Fatalf("LogEvent(%q): %v", name, err)
+        }
+    }
+    if errorsHits.Load() != 1 {
+        t.Fatalf("expected error event at the errors endpoint, got %d hits", errorsHits.Load())
+    }
+    if len(routed) != 2 || routed[0] != "usage.start" || routed[1] != "usage.stop" {
+        t.Fatalf("unexpected routed events: %v", routed)
+    }
+    if len(unrouted) != 2 || unrouted[0] != "install" {
+        t.Fatalf("unmatched events should use the default transport, got %v", unrouted)
+    }
+}
+
+func TestRoute_RequiresDestination(t *testing.T) {
+    l := New("https://example.com", WithRoutes(Route{Pattern: "*"}))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil {
+        t.Fatalf("expected error for route without endpoint or transport")
+    }
+}
@@ -0,0 +1,31 @@
+package scarf
+
+import "fmt"
+
+// Telemetry must never take down the application embedding it, so:
+//
+//   - every exported method of ScarfEventLogger may be called on a nil
+//     *ScarfEventLogger, and does nothing, as on a no-op logger: sends
+//     return nil, queries report analytics as off, and the functions that
+//     return a stop function return one that does nothing;
+//   - a panic in a function supplied to the logger (an Enricher, Observer,
+//     custom Transport, WithSampleKey or WithIDGenerator function, or the
+//     properties function passed to Every) is recovered and logged rather
+//     than propagated.
+
+// guard calls hook, recovering and logging any panic. It returns the panic
+// value, or nil if hook returned normally.
+func (s *ScarfEventLogger) guard(what string, hook func()) (recovered any) {
+    defer func() {
+        if recovered = recover(); recovered != nil {
+            s.error("recovered panic", "in", what, "panic", recovered)
+        }
+    }()
+    hook()
+    return nil
+}
+
+// errPanic describes a panic recovered from what as an error.
+func errPanic(what string, v any) error {
+    return fmt.Errorf("scarf: %s panicked: %v", what, v)
+}
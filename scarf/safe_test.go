@@ -0,0 +1,110 @@
+package scarf
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestNilLogger(t *testing.T) {
+    var s *ScarfEventLogger
+    ctx := context.Background()
+    props := map[string]any{"event": "x"}
+
+    errs := []error{
+        s.LogEvent(props),
+        s.LogEventContext(ctx, props),
+        s.LogEventWithTimeout(props, time.Second),
+        s.LogEventStruct(Event{Name: "x"}),
+        s.LogEvents(ctx, []Event{{Name: "x"}}),
+        s.StreamEvents(ctx, func() (Event, bool) { return Event{}, false }),
+        s.LogError(errors.New("boom"), nil),
+        s.LogInstallEvent(nil),
+        s.LogStartupEvent(nil),
+        s.LogFirstRunEvent(nil),
+        s.TrackPixel("p", nil),
+        s.StartSession(),
+        s.EndSession(),
+        s.SetConsent(true),
+        s.Flush(ctx),
+        s.Close(),
+        s.LogEventAsync(props).Err(),
+        Emit(EventLogger(s), "x", struct{}{}),
+    }
+    for i, err := range errs {
+        if err != nil {
+            t.Errorf("call %d on a nil logger returned %v", i, err)
+        }
+    }
+    if _, err := s.InstallID(); !errors.Is(err, ErrDisabled) {
+        t.Errorf("expected ErrDisabled from InstallID, got %v", err)
+    }
+    if !errors.Is(s.Ping(ctx), ErrDisabled) {
+        t.Errorf("expected ErrDisabled from Ping")
+    }
+    if s.Enabled() || s.DisabledReason() == "" || s.Pending() != 0 || s.SessionID() != "" {
+        t.Errorf("expected a nil logger to report analytics as off")
+    }
+    s.SetEnabled(true)
+    s.SetDefaultProperty("k", "v")
+    s.Count("n", 1)
+    s.Gauge("g", 1)
+    s.StartHeartbeat(time.Hour, nil)()
+    s.Every(time.Hour, "daily", nil)()
+    s.HandleSignals(0)()
+    _, _ = s.IsFirstRun()
+    _, _ = s.AskConsent(strings.NewReader("y\n"), &bytes.Buffer{}, "")
+    _ = s.ConsentState()
+    _ = s.EnabledBy()
+    _ = s.Policy()
+    _ = s.DryRunRequests()
+    _ = s.ResolvedConfig()
+    _ = s.Stats()
+
+    func() {
+        defer func() {
+            if recover() == nil {
+                t.Errorf("expected Recover on a nil logger to let the panic through")
+            }
+        }()
+        defer s.Recover(nil)
+        panic("boom")
+    }()
+}
+
+func TestHookPanics(t *testing.T) {
+    logs := &errorCounter{}
+    l := New("",
+        WithEnrichers(EnricherFunc(func(context.Context) map[string]any { panic("enricher") })),
+        WithObserver(ObserverFuncs{Failure: func(Delivery) { panic("observer") }}),
+        WithIDGenerator(func() string { panic("id") }),
+        WithTransport(TransportFunc(func(context.Context, Event) error { panic("transport") })),
+        WithLogger(logs),
+        WithLogLevel(LogError),
+    )
+    err := l.LogEvent(map[string]any{"event": "x"})
+    if err == nil || !strings.Contains(err.Error(), "transport panicked") {
+        t.Fatalf("expected the transport panic as an error, got %v", err)
+    }
+    if logs.n < 3 {
+        t.Fatalf("expected the recovered panics to be logged, got %d errors", logs.n)
+    }
+    _ = New("", WithSampleRate(0.5), WithSampleKey(func(Event) string { panic("sample key") })).LogEvent(nil)
+
+    var got []Event
+    l = New("", WithIDGenerator(func() string { panic("id") }), captureEvents(&got))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil || len(got) != 1 || got[0].ID == "" {
+        t.Fatalf("expected a default ID after the generator panicked, got %v %v", err, got)
+    }
+}
+
+// errorCounter counts the SDK's error diagnostics.
+type errorCounter struct{ n int }
+
+func (c *errorCounter) Debug(string, ...any) {}
+func (c *errorCounter) Info(string, ...any)  {}
+func (c *errorCounter) Warn(string, ...any)  {}
+func (c *errorCounter) Error(string, ...any) { c.n++ }
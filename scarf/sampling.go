@@ -0,0 +1,73 @@
+package scarf
+
+import (
+    "errors"
+    "hash/fnv"
+    "math/rand"
+)
+
+// ErrSampledOut is returned by PrepareRequest for an event that sampling
+// drops.
+var ErrSampledOut = errors.New("scarf: event sampled out")
+
+// WithSampleRate sends only the given fraction of events, between 0 and 1.
+// Sampled-out events are dropped silently and LogEvent returns nil.
+//
+// By default each event is sampled independently at random; combine with
+// WithSampleKey to make the decision deterministic.
+func WithSampleRate(rate float64) Option {
+    return func(s *ScarfEventLogger) {
+        if rate < 0 {
+            rate = 0
+        }
+        if rate > 1 {
+            rate = 1
+        }
+        s.sampleRate = rate
+        s.sampling = true
+        s.mark("sample_rate")
+    }
+}
+
+// WithSampleKey makes sampling deterministic: events whose key hashes to the
+// same value are consistently sampled in or out, so e.g. keying on an install
+// ID keeps or drops all events of that install. Events for which fn returns
+// an empty key fall back to random sampling.
+func WithSampleKey(fn func(Event) string) Option {
+    return func(s *ScarfEventLogger) {
+        s.sampleKey = fn
+    }
+}
+
+// sampledIn reports whether an event should be sent under the configured sampling.
+func (s *ScarfEventLogger) sampledIn(ev Event) bool {
+    if !s.sampling || s.sampleRate >= 1 {
+        return true
+    }
+    if s.sampleRate <= 0 {
+        return false
+    }
+    if s.sampleKey != nil {
+        var key string
+        s.guard("sample key", func() { key = s.sampleKey(ev) })
+        if key != "" {
+            return hashFraction(key) < s.sampleRate
+        }
+    }
+    return rand.Float64() < s.sampleRate
+}
+
+// hashFraction maps key uniformly onto [0, 1).
+func hashFraction(key string) float64 {
+    h := fnv.New64a()
+    _, _ = h.Write([]byte(key))
+    // FNV alone distributes similar keys poorly in its high bits; apply the
+    // splitmix64 finalizer before taking the top 53 bits.
+    x := h.Sum64()
+    x ^= x >> 30
+    x *= 0xbf58476d1ce4e5b9
+    x ^= x >> 27
+    x *= 0x94d049bb133111eb
+    x ^= x >> 31
+    return float64(x>>11) / (1 << 53)
+}
@@ -0,0 +1,55 @@
+package scarf
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+)
+
+func TestSampleRate_Extremes(t *testing.T) {
+    var hits int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&hits, 1)
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+
+    none := New(srv.URL, WithSampleRate(0))
+    all := New(srv.URL, WithSampleRate(1))
+    for i := 0; i < 10; i++ {
+        if err := none.LogEvent(map[string]any{"event": "x"}); err != nil {
+            t.Fatalf("sampled-out event should return nil, got %v", err)
+        }
+        if err := all.LogEvent(map[string]any{"event": "x"}); err != nil {
+            t.Fatalf("expected success, got %v", err)
+        }
+    }
+    if got := atomic.LoadInt32(&hits); got != 10 {
+        t.Fatalf("expected 10 requests, got %d", got)
+    }
+}
+
+func TestSampleKey_Deterministic(t *testing.T) {
+    l := New("https://example.com",
+        WithSampleRate(0.5),
+        WithSampleKey(func(ev Event) string { return fmt.Sprint(ev.Properties["install"]) }),
+    )
+    in := 0
+    for i := 0; i < 1000; i++ {
+        ev := Event{Properties: map[string]any{"install": fmt.Sprintf("id-%d", i)}}
+        first := l.sampledIn(ev)
+        for j := 0; j < 3; j++ {
+            if l.sampledIn(ev) != first {
+                t.Fatalf("sampling decision for %v is not stable", ev.Properties)
+            }
+        }
+        if first {
+            in++
+        }
+    }
+    if in < 400 || in > 600 {
+        t.Fatalf("expected roughly half of keys sampled in, got %d/1000", in)
+    }
+}
@@ -0,0 +1,150 @@
+package scarf
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+)
+
+// defaultEveryInterval is used by Every for non-positive intervals.
+const defaultEveryInterval = 24 * time.Hour
+
+// scheduleRetry bounds how long Every waits to try again after a failed send.
+const scheduleRetry = time.Minute
+
+// Every emits the named event once per interval (24 hours if interval is zero
+// or less) until the returned stop function is called or the logger is
+// closed, e.g. a daily active usage event from a daemon:
+//
+//   stop := logger.Every(24*time.Hour, "daily_active", func() map[string]any {
+//       return map[string]any{"version": version}
+//   })
+//   defer stop()
+//
+// props, which may be nil, is called for the properties of each event. The
+// first event is sent right away unless one was sent less than interval ago:
+// with WithAppName or WithStateDir, the time of the last successful send is
+// persisted in the schedules directory of the app's state (see InstallID), so
+// restarts don't cause duplicates. Failed sends are retried after a minute.
+// Nothing is read or written while analytics are disabled or consent is
+// missing.
+func (s *ScarfEventLogger) Every(interval time.Duration, name string, props func() map[string]any) (stop func()) {
+    if buildDisabled || s == nil || s.noop {
+        return func() {}
+    }
+    if interval <= 0 {
+        interval = defaultEveryInterval
+    }
+    sched := &schedule{name: name, interval: interval, props: props}
+
+    ctx, cancel := context.WithCancel(context.Background())
+    go func() {
+        timer := s.clock.NewTimer(0)
+        defer timer.Stop()
+        for {
+            select {
+            case <-timer.C():
+                timer.Reset(s.runSchedule(ctx, sched))
+            case <-ctx.Done():
+                return
+            case <-s.done:
+                return
+            }
+        }
+    }()
+    return cancel
+}
+
+// schedule is the state of one Every loop.
+type schedule struct {
+    name     string
+    interval time.Duration
+    props    func() map[string]any
+
+    mu   sync.Mutex
+    last time.Time
+}
+
+// runSchedule sends the scheduled event if it is due and returns how long to
+// wait before checking again.
+func (s *ScarfEventLogger) runSchedule(ctx context.Context, sched *schedule) time.Duration {
+    if refused, _ := s.refuse(); refused {
+        return min(sched.interval, scheduleRetry)
+    }
+    path := s.schedulePath(sched.name)
+    if wait := sched.interval - s.clock.Now().Sub(sched.lastSent(path)); wait > 0 {
+        return wait
+    }
+
+    var props map[string]any
+    if sched.props != nil && s.guard("schedule properties", func() { props = sched.props() }) != nil {
+        return min(sched.interval, scheduleRetry)
+    }
+    ev := eventFromProperties(copyProperties(props))
+    ev.Name = sched.name
+    if err := s.logEventInternal(ctx, ev, s.defaultTimeout); err != nil {
+        s.debug("scheduled event not sent", "event", sched.name, "error", err)
+        return min(sched.interval, scheduleRetry)
+    }
+    if err := sched.markSent(path, s.clock.Now()); err != nil {
+        s.warn("scheduled event time not persisted", "event", sched.name, "error", err)
+    }
+    return sched.interval
+}
+
+// schedulePath returns the file recording when the named scheduled event was
+// last sent, or "" if it isn't persisted.
+func (s *ScarfEventLogger) schedulePath(name string) string {
+    dir, err := s.appStateDir()
+    if err != nil || dir == "" {
+        return ""
+    }
+    return filepath.Join(dir, "schedules", scheduleFileName(name))
+}
+
+// scheduleFileName maps an event name to a safe file name.
+func scheduleFileName(name string) string {
+    return strings.Map(func(r rune) rune {
+        switch {
+        case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+            return r
+        }
+        return '_'
+    }, name)
+}
+
+// lastSent returns when the event was last sent by this process or, as
+// recorded at path, by an earlier one.
+func (sc *schedule) lastSent(path string) time.Time {
+    sc.mu.Lock()
+    defer sc.mu.Unlock()
+    if path == "" {
+        return sc.last
+    }
+    if data, err := os.ReadFile(path); err == nil {
+        if t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data))); err == nil && t.After(sc.last) {
+            sc.last = t
+        }
+    }
+    return sc.last
+}
+
+func (sc *schedule) markSent(path string, t time.Time) error {
+    sc.mu.Lock()
+    defer sc.mu.Unlock()
+    sc.last = t
+    if path == "" {
+        return nil
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+        return fmt.Errorf("scarf: persist schedule: %w", err)
+    }
+    if err := os.WriteFile(path, []byte(t.UTC().Format(time.RFC3339Nano)+"\n"), 0o600); err != nil {
+        return fmt.Errorf("scarf: persist schedule: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,81 @@
+package scarf
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestEvery(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    var hits atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Query().Get("event") == "daily_active" && r.URL.Query().Get("version") == "1.0" {
+            hits.Add(1)
+        }
+    }))
+    defer srv.Close()
+    props := func() map[string]any { return map[string]any{"version": "1.0"} }
+
+    l := New(srv.URL, WithAppName("scheduled"))
+    stop := l.Every(time.Hour, "daily_active", props)
+    path := l.schedulePath("daily_active")
+    deadline := time.Now().Add(2 * time.Second)
+    for time.Now().Before(deadline) {
+        if _, err := os.Stat(path); err == nil {
+            break
+        }
+        time.Sleep(time.Millisecond)
+    }
+    stop()
+    if hits.Load() != 1 {
+        t.Fatalf("expected the first event right away, got %d", hits.Load())
+    }
+
+    // A restarted process finds the persisted time and waits.
+    l = New(srv.URL, WithAppName("scheduled"))
+    stop = l.Every(time.Hour, "daily_active", props)
+    time.Sleep(50 * time.Millisecond)
+    stop()
+    if hits.Load() != 1 {
+        t.Fatalf("expected no duplicate after a restart, got %d events", hits.Load())
+    }
+
+    // Without persistence, events recur every interval.
+    l = New(srv.URL)
+    stop = l.Every(10*time.Millisecond, "daily_active", props)
+    deadline = time.Now().Add(2 * time.Second)
+    for hits.Load() < 4 && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond)
+    }
+    stop()
+    if hits.Load() < 4 {
+        t.Fatalf("expected recurring events, got %d", hits.Load())
+    }
+}
+
+func TestEveryDisabled(t *testing.T) {
+    var hits atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        hits.Add(1)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithEnabled(false))
+    stop := l.Every(time.Millisecond, "daily_active", nil)
+    time.Sleep(20 * time.Millisecond)
+    stop()
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if hits.Load() != 0 {
+        t.Fatalf("expected nothing sent while disabled, got %d", hits.Load())
+    }
+}
@@ -0,0 +1,69 @@
+package scarf
+
+import (
+    "fmt"
+    "net"
+    "regexp"
+)
+
+var (
+    emailPattern    = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+    ipv4Pattern     = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`)
+    ipv6Candidate   = regexp.MustCompile(`[0-9A-Fa-f]*:[0-9A-Fa-f:.]*:[0-9A-Fa-f.]*`)
+    unixHomePath    = regexp.MustCompile(`(/(?:home|Users)/)[^/\s"']+`)
+    windowsHomePath = regexp.MustCompile(`(?i)([A-Z]:\\(?:Users|Documents and Settings)\\)[^\\\s"']+`)
+)
+
+// ScrubPII masks common personal data inside s: email addresses become
+// "[EMAIL]", IPv4 and IPv6 addresses become "[IP]", and the user name in home
+// directory paths (/home/alice, /Users/alice, C:\Users\alice) becomes
+// "<user>".
+//
+// It is a best-effort safety net on top of explicit redaction, not a
+// guarantee.
+func ScrubPII(s string) string {
+    s = emailPattern.ReplaceAllString(s, "[EMAIL]")
+    s = unixHomePath.ReplaceAllString(s, "${1}<user>")
+    s = windowsHomePath.ReplaceAllString(s, "${1}<user>")
+    s = ipv6Candidate.ReplaceAllStringFunc(s, func(m string) string {
+        if ip := net.ParseIP(m); ip != nil && ip.To4() == nil {
+            return "[IP]"
+        }
+        return m
+    })
+    s = ipv4Pattern.ReplaceAllString(s, "[IP]")
+    return s
+}
+
+// WithPIIScrubbing applies ScrubPII to every property value before sending.
+// Strings are scrubbed directly; other values are scrubbed in their encoded
+// form and replaced by the scrubbed string if anything was masked.
+func WithPIIScrubbing() Option {
+    return func(s *ScarfEventLogger) {
+        s.scrubPII = true
+    }
+}
+
+// scrubProperties applies ScrubPII to props in place.
+func scrubProperties(props map[string]any) {
+    for k, v := range props {
+        switch vv := v.(type) {
+        case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+            continue
+        case string:
+            props[k] = ScrubPII(vv)
+        default:
+            var str string
+            if err, ok := v.(error); ok {
+                str = err.Error()
+            } else if st, ok := v.(fmt.Stringer); ok {
+                str = st.String()
+            } else {
+                str = stringifyParam(v)
+            }
+            if scrubbed := ScrubPII(str); scrubbed != str {
+                props[k] = scrubbed
+            }
+        }
+    }
+}
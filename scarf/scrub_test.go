@@ -0,0 +1,56 @@
+package scarf
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestScrubPII(t *testing.T) {
+    cases := map[string]string{
+        "contact alice.smith+x@example.co.uk now": "contact [EMAIL] now",
+        "from 192.168.1.20:8080":                  "from [IP]:8080",
+        "peer 2001:db8::1 connected":              "peer [IP] connected",
+        "open /home/alice/projects/app":           "open /home/<user>/projects/app",
+        "at /Users/bob/Library":                   "at /Users/<user>/Library",
+        `C:\Users\carol\AppData\x`:                `C:\Users\<user>\AppData\x`,
+        "at 12:30:45 ok":                          "at 12:30:45 ok",
+        "/usr/local/bin/tool":                     "/usr/local/bin/tool",
+        "plain":                                   "plain",
+    }
+    for in, want := range cases {
+        if got := ScrubPII(in); got != want {
+            t.Errorf("ScrubPII(%q) = %q, want %q", in, got, want)
+        }
+    }
+}
+
+func TestWithPIIScrubbing(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithPIIScrubbing())
+    err := l.LogEvent(map[string]any{
+        "msg":   "failed for bob@example.com",
+        "err":   errors.New("open /home/bob/.config: denied"),
+        "paths": []string{"/home/bob/a"},
+        "count": 3,
+        "clean": map[string]int{"a": 1},
+    })
+    if err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    p := got[0].Properties
+    if p["msg"] != "failed for [EMAIL]" {
+        t.Errorf("msg = %v", p["msg"])
+    }
+    if p["err"] != "open /home/<user>/.config: denied" {
+        t.Errorf("err = %v", p["err"])
+    }
+    if p["paths"] != `["/home/<user>/a"]` {
+        t.Errorf("paths = %v", p["paths"])
+    }
+    if p["count"] != 3 {
+        t.Errorf("non-string scalars must be untouched, got %v", p["count"])
+    }
+    if _, ok := p["clean"].(map[string]int); !ok {
+        t.Errorf("values without PII must keep their type, got %T", p["clean"])
+    }
+}
@@ -0,0 +1,105 @@
+package scarf
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "sync/atomic"
+    "time"
+)
+
+const (
+    // serverlessTimeout is the default timeout in serverless mode.
+    serverlessTimeout = 500 * time.Millisecond
+    // serverlessDeadlineMargin is left between a send and the invocation
+    // deadline so the handler can still return in time.
+    serverlessDeadlineMargin = 50 * time.Millisecond
+)
+
+// coldStart is claimed by the first event sent in serverless mode.
+var coldStart atomic.Bool
+
+// IsServerless reports whether the process appears to run on a serverless
+// platform: AWS Lambda, Google Cloud Functions / Cloud Run or Azure Functions.
+func IsServerless() bool {
+    for _, key := range []string{"AWS_LAMBDA_FUNCTION_NAME", "FUNCTION_TARGET", "K_SERVICE", "FUNCTIONS_WORKER_RUNTIME"} {
+        if os.Getenv(key) != "" {
+            return true
+        }
+    }
+    return false
+}
+
+// WithServerlessMode tunes the logger for AWS Lambda, Cloud Functions and
+// similar environments, where background work is unreliable because the
+// execution environment is frozen between invocations:
+//
+//   - sends are synchronous, including LogEventAsync, which ignores
+//     WithBatching, and the default timeout is 500ms (a later WithTimeout
+//     still applies);
+//   - with LogEventContext, sends are bounded by the invocation's context
+//     deadline minus a small margin, and skipped if too little time is left;
+//   - the first event of the process carries "cold_start": true, later ones
+//     "cold_start": false.
+//
+// Use FlushBeforeDeadline in the handler to send aggregated metrics and
+// anything else still pending before the invocation ends.
+func WithServerlessMode() Option {
+    return func(s *ScarfEventLogger) {
+        s.serverless = true
+        s.defaultTimeout = serverlessTimeout
+    }
+}
+
+// serverlessBudget clamps timeout to the time remaining before ctx's
+// deadline, keeping a margin for the handler to return.
+func serverlessBudget(ctx context.Context, timeout time.Duration) (time.Duration, error) {
+    deadline, ok := ctx.Deadline()
+    if !ok {
+        return timeout, nil
+    }
+    remaining := time.Until(deadline) - serverlessDeadlineMargin
+    if remaining <= 0 {
+        return 0, fmt.Errorf("scarf: not enough time before invocation deadline: %w", context.DeadlineExceeded)
+    }
+    if remaining < timeout {
+        return remaining, nil
+    }
+    return timeout, nil
+}
+
+// FlushBeforeDeadline arranges for Flush to run shortly before ctx's
+// deadline, leaving the logger's timeout for the sends it starts, and returns
+// a function that flushes right away instead and cancels the timer. Call it
+// at the start of a serverless handler with the invocation's context:
+//
+//   defer logger.FlushBeforeDeadline(ctx)()
+//
+// Flushing is bounded by the deadline minus a small margin, so the handler
+// can still return in time. Without a deadline, only the returned function
+// flushes.
+func (s *ScarfEventLogger) FlushBeforeDeadline(ctx context.Context) func() {
+    if s == nil {
+        return func() {}
+    }
+    deadline, hasDeadline := ctx.Deadline()
+    flush := func() {
+        fctx := context.Background()
+        if hasDeadline {
+            var cancel context.CancelFunc
+            fctx, cancel = context.WithDeadline(fctx, deadline.Add(-serverlessDeadlineMargin))
+            defer cancel()
+        }
+        if err := s.Flush(fctx); err != nil {
+            s.warn("flush before invocation deadline incomplete", "error", err)
+        }
+    }
+    if !hasDeadline {
+        return flush
+    }
+    t := s.clock.AfterFunc(time.Until(deadline)-serverlessDeadlineMargin-s.defaultTimeout, flush)
+    return func() {
+        t.Stop()
+        flush()
+    }
+}
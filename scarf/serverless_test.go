@@ -0,0 +1,113 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestServerlessMode_ColdStart(t *testing.T) {
+    coldStart.Store(false)
+    t.Cleanup(func() { coldStart.Store(false) })
+
+    var got []Event
+    l := New("", captureEvents(&got), WithServerlessMode())
+    if l.defaultTimeout != serverlessTimeout {
+        t.Fatalf("expected serverless default timeout, got %s", l.defaultTimeout)
+    }
+    for i := 0; i < 2; i++ {
+        if err := l.LogEvent(map[string]any{"event": "invoke"}); err != nil {
+            t.Fatalf("LogEvent: %v", err)
+        }
+    }
+    if got[0].Properties["cold_start"] != true || got[1].Properties["cold_start"] != false {
+        t.Fatalf("expected cold start only on first event, got %v / %v", got[0].Properties, got[1].Properties)
+    }
+}
+
+func TestServerlessMode_Deadline(t *testing.T) {
+    var deadline time.Time
+    l := New("", WithServerlessMode(), WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        deadline, _ = ctx.Deadline()
+        return nil
+    })))
+
+    ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+    defer cancel()
+    if err := l.LogEventContext(ctx, map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEventContext: %v", err)
+    }
+    invocation, _ := ctx.Deadline()
+    if !deadline.Before(invocation) {
+        t.Fatalf("send deadline %s should leave a margin before invocation deadline %s", deadline, invocation)
+    }
+
+    short, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel2()
+    if err := l.LogEventContext(short, map[string]any{"event": "x"}); !errors.Is(err, context.DeadlineExceeded) {
+        t.Fatalf("expected event to be skipped near deadline, got %v", err)
+    }
+}
+
+func TestServerlessMode_AsyncInline(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithServerlessMode(), WithBatching(time.Hour, 100))
+    r := l.LogEventAsync(map[string]any{"event": "invoke"})
+    select {
+    case <-r.Done():
+    default:
+        t.Fatalf("expected LogEventAsync to send before returning in serverless mode")
+    }
+    if r.Err() != nil || len(got) != 1 {
+        t.Fatalf("expected the event to be sent, got %v, %d events", r.Err(), len(got))
+    }
+}
+
+func TestFlushBeforeDeadline(t *testing.T) {
+    sent := make(chan Event, 1)
+    l := New("", WithTimeout(150*time.Millisecond), WithBatching(time.Hour, 100),
+        WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+            sent <- ev
+            return nil
+        })))
+    ctx, cancel := context.WithTimeout(context.Background(), serverlessDeadlineMargin+200*time.Millisecond)
+    defer cancel()
+    flush := l.FlushBeforeDeadline(ctx)
+    defer flush()
+
+    r := l.LogEventAsync(map[string]any{"event": "queued"})
+    select {
+    case <-sent:
+    case <-ctx.Done():
+        t.Fatalf("expected the queued event to be flushed before the deadline")
+    }
+    if err := r.Err(); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+
+    // The returned function flushes right away.
+    l.LogEventAsync(map[string]any{"event": "late"})
+    flush()
+    select {
+    case ev := <-sent:
+        if ev.Name != "late" {
+            t.Fatalf("unexpected event %q", ev.Name)
+        }
+    default:
+        t.Fatalf("expected the returned function to flush")
+    }
+}
+
+func TestIsServerless(t *testing.T) {
+    for _, k := range []string{"AWS_LAMBDA_FUNCTION_NAME", "FUNCTION_TARGET", "K_SERVICE", "FUNCTIONS_WORKER_RUNTIME"} {
+        t.Setenv(k, "")
+    }
+    if IsServerless() {
+        t.Fatalf("expected not serverless")
+    }
+    t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "fn")
+    if !IsServerless() {
+        t.Fatalf("expected Lambda to be detected")
+    }
+}
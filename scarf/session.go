@@ -0,0 +1,83 @@
+package scarf
+
+import (
+    "errors"
+    "time"
+)
+
+// session is a session begun by StartSession.
+type session struct {
+    id    string
+    start time.Time
+}
+
+// StartSession begins a session, giving funnel-style insight into a CLI
+// invocation or a daemon's lifetime: it sends a "session_start" event, and
+// every event logged until EndSession carries the new random session ID as
+// "session_id" (unless it sets one itself). A session already in progress is
+// ended first, and Close ends the current one.
+//
+// When events are refused (analytics disabled, no consent, or the logger
+// closed), no session is started and the refusal is returned.
+func (s *ScarfEventLogger) StartSession() error {
+    if s == nil {
+        return nil
+    }
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    sess := &session{id: newRandomUUID(), start: s.clock.Now()}
+    var endErr error
+    if prev := s.session.Swap(sess); prev != nil {
+        endErr = s.sendSessionEnd(prev)
+    }
+    startErr := s.LogEventStruct(Event{Name: "session_start", Properties: map[string]any{"session_id": sess.id}})
+    return errors.Join(endErr, startErr)
+}
+
+// EndSession ends the current session, sending a "session_end" event with
+// its duration in milliseconds as "duration_ms". It does nothing if no
+// session is in progress.
+func (s *ScarfEventLogger) EndSession() error {
+    if s == nil {
+        return nil
+    }
+    sess := s.session.Swap(nil)
+    if sess == nil {
+        return nil
+    }
+    return s.sendSessionEnd(sess)
+}
+
+// SessionID returns the ID of the current session, or "" if none is in
+// progress.
+func (s *ScarfEventLogger) SessionID() string {
+    if s == nil {
+        return ""
+    }
+    if sess := s.session.Load(); sess != nil {
+        return sess.id
+    }
+    return ""
+}
+
+func (s *ScarfEventLogger) sendSessionEnd(sess *session) error {
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    return s.LogEventStruct(Event{Name: "session_end", Properties: map[string]any{
+        "session_id":  sess.id,
+        "duration_ms": s.clock.Now().Sub(sess.start).Milliseconds(),
+    }})
+}
+
+// tagSession attaches the current session ID to props.
+func (s *ScarfEventLogger) tagSession(props map[string]any) {
+    sess := s.session.Load()
+    if sess == nil {
+        return
+    }
+    if _, ok := props["session_id"]; !ok {
+        props["session_id"] = sess.id
+    }
+}
@@ -0,0 +1,77 @@
+package scarf
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestSession(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+
+    if err := l.StartSession(); err != nil {
+        t.Fatalf("StartSession: %v", err)
+    }
+    id := l.SessionID()
+    if id == "" {
+        t.Fatal("expected a session ID")
+    }
+    _ = l.LogEvent(map[string]any{"event": "command"})
+    _ = l.LogEvent(map[string]any{"event": "custom", "session_id": "mine"})
+    if err := l.EndSession(); err != nil {
+        t.Fatalf("EndSession: %v", err)
+    }
+    _ = l.LogEvent(map[string]any{"event": "after"})
+
+    if len(got) != 5 {
+        t.Fatalf("expected 5 events, got %d", len(got))
+    }
+    if got[0].Name != "session_start" || got[0].Properties["session_id"] != id {
+        t.Fatalf("unexpected start event %+v", got[0])
+    }
+    if got[1].Properties["session_id"] != id || got[2].Properties["session_id"] != "mine" {
+        t.Fatalf("expected the session ID on events logged during the session, got %v and %v", got[1].Properties, got[2].Properties)
+    }
+    end := got[3]
+    if end.Name != "session_end" || end.Properties["session_id"] != id {
+        t.Fatalf("unexpected end event %+v", end)
+    }
+    if _, ok := end.Properties["duration_ms"].(int64); !ok {
+        t.Fatalf("expected duration_ms, got %v", end.Properties)
+    }
+    if _, ok := got[4].Properties["session_id"]; ok || l.SessionID() != "" {
+        t.Fatal("expected no session ID after EndSession")
+    }
+}
+
+func TestSessionRestartAndClose(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+
+    _ = l.StartSession()
+    first := l.SessionID()
+    _ = l.StartSession()
+    if l.SessionID() == first {
+        t.Fatal("expected a new session ID")
+    }
+    if len(got) != 3 || got[1].Name != "session_end" || got[1].Properties["session_id"] != first {
+        t.Fatalf("expected the first session to be ended, got %v", got)
+    }
+    if err := l.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    if len(got) != 4 || got[3].Name != "session_end" {
+        t.Fatalf("expected Close to end the session, got %v", got)
+    }
+}
+
+func TestSessionDisabled(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithEnabled(false))
+    if err := l.StartSession(); !errors.Is(err, ErrDisabled) {
+        t.Fatalf("expected ErrDisabled, got %v", err)
+    }
+    if l.SessionID() != "" || len(got) != 0 {
+        t.Fatal("expected no session while disabled")
+    }
+}
@@ -0,0 +1,73 @@
+package scarf
+
+import (
+    "context"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+)
+
+// defaultDrainTimeout bounds how long HandleSignals waits for pending sends.
+const defaultDrainTimeout = 2 * time.Second
+
+// HandleSignals installs handlers for SIGINT and SIGTERM (or sigs, if given)
+// that close the logger, waiting at most drain for pending sends, and then
+// re-raise the signal so the process exits as it otherwise would have. A drain
+// of zero or less uses a 2 second default. The returned function uninstalls
+// the handlers.
+//
+// HandleSignals is meant for programs that don't handle these signals
+// themselves; those should call Flush or Close from their own shutdown path.
+func (s *ScarfEventLogger) HandleSignals(drain time.Duration, sigs ...os.Signal) (stop func()) {
+    if s == nil {
+        return func() {}
+    }
+    if len(sigs) == 0 {
+        sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+    }
+    ch := make(chan os.Signal, 1)
+    signal.Notify(ch, sigs...)
+    done := make(chan struct{})
+    go func() {
+        select {
+        case sig := <-ch:
+            s.shutdown(drain)
+            signal.Stop(ch)
+            reraise(sig)
+        case <-done:
+        }
+    }()
+    return func() {
+        signal.Stop(ch)
+        select {
+        case <-done:
+        default:
+            close(done)
+        }
+    }
+}
+
+// shutdown closes the logger, waiting at most drain for pending sends.
+func (s *ScarfEventLogger) shutdown(drain time.Duration) {
+    if drain <= 0 {
+        drain = defaultDrainTimeout
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), drain)
+    defer cancel()
+    if err := s.closeContext(ctx); err != nil {
+        s.warn("pending events not sent before shutdown", "error", err)
+    }
+}
+
+// reraise delivers sig to the current process again now that it is no longer
+// caught, falling back to exiting where signals can't be sent.
+var reraise = func(sig os.Signal) {
+    p, err := os.FindProcess(os.Getpid())
+    if err == nil {
+        err = p.Signal(sig)
+    }
+    if err != nil {
+        os.Exit(1)
+    }
+}
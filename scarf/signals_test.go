@@ -0,0 +1,68 @@
+//go:build unix
+
+package scarf
+
+import (
+    "context"
+    "os"
+    "syscall"
+    "testing"
+    "time"
+)
+
+func TestHandleSignals_DrainsAndReraises(t *testing.T) {
+    reraised := make(chan os.Signal, 1)
+    orig := reraise
+    reraise = func(sig os.Signal) { reraised <- sig }
+    t.Cleanup(func() { reraise = orig })
+
+    release := make(chan struct{})
+    started := make(chan struct{})
+    var sent bool
+    l := New("", WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        close(started)
+        <-release
+        sent = true
+        return nil
+    })))
+    stop := l.HandleSignals(time.Second, syscall.SIGUSR1)
+    defer stop()
+
+    go func() { _ = l.LogEvent(map[string]any{"event": "slow"}) }()
+    <-started
+    if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+        t.Fatalf("kill: %v", err)
+    }
+    time.Sleep(20 * time.Millisecond)
+    close(release)
+
+    select {
+    case sig := <-reraised:
+        if sig != syscall.SIGUSR1 {
+            t.Fatalf("expected SIGUSR1 to be re-raised, got %v", sig)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatalf("signal was not handled")
+    }
+    if !sent {
+        t.Fatalf("expected pending send to finish before re-raising")
+    }
+    if err := l.LogEvent(map[string]any{"event": "late"}); err != ErrClosed {
+        t.Fatalf("expected logger to be closed, got %v", err)
+    }
+}
+
+func TestHandleSignals_BoundedDrain(t *testing.T) {
+    l := New("", WithTransport(TransportFunc(func(ctx context.Context, ev Event) error {
+        <-ctx.Done()
+        return ctx.Err()
+    })))
+    go func() { _ = l.LogEventWithTimeout(map[string]any{"event": "stuck"}, time.Minute) }()
+    time.Sleep(10 * time.Millisecond)
+
+    start := time.Now()
+    l.shutdown(30 * time.Millisecond)
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Fatalf("shutdown should stop waiting after the drain deadline, took %s", elapsed)
+    }
+}
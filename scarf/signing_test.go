@@ -0,0 +1,59 @@
+package scarf
+
+import (
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestWithRequestSigning(t *testing.T) {
+    secret := []byte("s3cret")
+    var verifyErr error
+    var signed *http.Request
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        verifyErr = VerifySignature(r, body, secret, time.Minute)
+        signed = r.Clone(r.Context())
+        w.WriteHeader(http.StatusOK)
+    }))
+    defer srv.Close()
+
+    if err := New(srv.URL+"/events?pkg=a", WithRequestSigning(secret)).LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if verifyErr != nil {
+        t.Fatalf("expected a valid signature, got %v", verifyErr)
+    }
+
+    if err := VerifySignature(signed, nil, []byte("wrong"), time.Minute); !errors.Is(err, ErrInvalidSignature) {
+        t.Fatalf("expected wrong secret to be rejected, got %v", err)
+    }
+    tampered := signed.Clone(signed.Context())
+    q := tampered.URL.Query()
+    q.Set("event", "y")
+    tampered.URL.RawQuery = q.Encode()
+    if err := VerifySignature(tampered, nil, secret, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+        t.Fatalf("expected tampered query to be rejected, got %v", err)
+    }
+}
+
+func TestVerifySignature_Replay(t *testing.T) {
+    secret := []byte("s3cret")
+    req := httptest.NewRequest(http.MethodPost, "https://collector.example.com/events?event=x", nil)
+    signRequest(req, []byte("body"), secret, time.Now().Add(-10*time.Minute))
+    if err := VerifySignature(req, []byte("body"), secret, 24*time.Hour); err != nil {
+        t.Fatalf("expected signature to verify within skew, got %v", err)
+    }
+    if err := VerifySignature(req, []byte("body"), secret, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+        t.Fatalf("expected stale request to be rejected, got %v", err)
+    }
+    if err := VerifySignature(req, []byte("other"), secret, 24*time.Hour); !errors.Is(err, ErrInvalidSignature) {
+        t.Fatalf("expected modified body to be rejected, got %v", err)
+    }
+    if err := VerifySignature(httptest.NewRequest(http.MethodPost, "/", nil), nil, secret, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+        t.Fatalf("expected unsigned request to be rejected, got %v", err)
+    }
+}
@@ -0,0 +1,58 @@
+package scarf
+
+import (
+    "runtime"
+    "runtime/debug"
+)
+
+// LogInstallEvent sends a standardized "install" event. See standardEvent for
+// the properties it carries; props are added on top and win on conflicts.
+func (s *ScarfEventLogger) LogInstallEvent(props map[string]any) error {
+    if s == nil {
+        return nil
+    }
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    return s.LogEventStruct(s.standardEvent("install", props))
+}
+
+// LogStartupEvent sends a standardized "startup" event, with the same
+// properties as LogInstallEvent.
+func (s *ScarfEventLogger) LogStartupEvent(props map[string]any) error {
+    if s == nil {
+        return nil
+    }
+    if refused, err := s.refuse(); refused {
+        return err
+    }
+    return s.LogEventStruct(s.standardEvent("startup", props))
+}
+
+// standardEvent builds the event shape shared by the convenience methods:
+// "version" (the WithApplication version or else the main module's version
+// from the build info, when it was built from a tagged module), "platform",
+// "arch" and, when WithAppName or WithStateDir is set and an ID can be
+// obtained, "install_id".
+func (s *ScarfEventLogger) standardEvent(name string, props map[string]any) Event {
+    out := map[string]any{
+        "platform": platformName(),
+        "arch":     runtime.GOARCH,
+    }
+    if s.appVersion != "" {
+        out["version"] = s.appVersion
+    } else if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+        out["version"] = bi.Main.Version
+    }
+    if s.appName != "" || s.stateDir != "" {
+        if id, err := s.InstallID(); err == nil {
+            out["install_id"] = id
+        } else {
+            s.debug("install ID unavailable", "error", err)
+        }
+    }
+    for k, v := range props {
+        out[k] = v
+    }
+    return Event{Name: name, Properties: out}
+}
@@ -0,0 +1,50 @@
+package scarf
+
+import (
+    "runtime"
+    "testing"
+)
+
+func TestLogInstallAndStartupEvents(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    var got []Event
+    l := New("", captureEvents(&got), WithAppName("mytool"))
+    if err := l.LogInstallEvent(map[string]any{"version": "1.4.2", "channel": "brew"}); err != nil {
+        t.Fatalf("LogInstallEvent: %v", err)
+    }
+    if err := l.LogStartupEvent(nil); err != nil {
+        t.Fatalf("LogStartupEvent: %v", err)
+    }
+    id, _ := l.InstallID()
+
+    install, startup := got[0], got[1]
+    if install.Name != "install" || startup.Name != "startup" {
+        t.Fatalf("unexpected event names %q, %q", install.Name, startup.Name)
+    }
+    if install.Properties["version"] != "1.4.2" || install.Properties["channel"] != "brew" {
+        t.Fatalf("caller properties should win: %v", install.Properties)
+    }
+    for _, ev := range got {
+        if ev.Properties["platform"] != platformName() || ev.Properties["arch"] != runtime.GOARCH {
+            t.Fatalf("missing platform fields: %v", ev.Properties)
+        }
+        if ev.Properties["install_id"] != id || id == "" {
+            t.Fatalf("expected install_id %q, got %v", id, ev.Properties["install_id"])
+        }
+    }
+}
+
+func TestLogStartupEvent_WithoutAppName(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got))
+    if err := l.LogStartupEvent(nil); err != nil {
+        t.Fatalf("LogStartupEvent: %v", err)
+    }
+    if _, ok := got[0].Properties["install_id"]; ok {
+        t.Fatalf("install_id requires an app name: %v", got[0].Properties)
+    }
+}
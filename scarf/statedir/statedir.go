@@ -0,0 +1,74 @@
+// Package statedir resolves where the scarf SDK keeps per-user state such as
+// install IDs, first-run markers and the times of scheduled events.
+//
+// The location follows each platform's conventions: $XDG_STATE_HOME (or
+// ~/.local/state) on Linux and other Unix systems, ~/Library/Application
+// Support on macOS and iOS, and %LocalAppData% on Windows. Setting
+// SCARF_STATE_DIR overrides it for every application, e.g. to keep state on a
+// writable volume in a container.
+package statedir
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+)
+
+// EnvVar names the environment variable that overrides Root.
+const EnvVar = "SCARF_STATE_DIR"
+
+// Root returns the directory holding the SDK's state for all applications:
+// $SCARF_STATE_DIR if set, or a "scarf" directory in the platform's per-user
+// state directory.
+func Root() (string, error) {
+    if dir := strings.TrimSpace(os.Getenv(EnvVar)); dir != "" {
+        return filepath.Clean(dir), nil
+    }
+    base, err := userStateDir()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(base, "scarf"), nil
+}
+
+// Dir returns the state directory of the application app, under Root. The
+// directory is not created.
+func Dir(app string) (string, error) {
+    if app == "" {
+        return "", errors.New("statedir: empty application name")
+    }
+    root, err := Root()
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(root, app), nil
+}
+
+// Overridden reports whether SCARF_STATE_DIR is set.
+func Overridden() bool {
+    return strings.TrimSpace(os.Getenv(EnvVar)) != ""
+}
+
+// userStateDir returns the platform's per-user state directory.
+func userStateDir() (string, error) {
+    switch runtime.GOOS {
+    case "windows":
+        if dir := os.Getenv("LocalAppData"); dir != "" {
+            return dir, nil
+        }
+        return os.UserConfigDir()
+    case "darwin", "ios":
+        return os.UserConfigDir()
+    default:
+        if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+            return dir, nil
+        }
+        home, err := os.UserHomeDir()
+        if err != nil {
+            return "", err
+        }
+        return filepath.Join(home, ".local", "state"), nil
+    }
+}
@@ -0,0 +1,38 @@
+package statedir
+
+import (
+    "path/filepath"
+    "runtime"
+    "testing"
+)
+
+func TestRoot(t *testing.T) {
+    dir := t.TempDir()
+    t.Setenv(EnvVar, "")
+    t.Setenv("XDG_STATE_HOME", dir)
+    t.Setenv("LocalAppData", dir)
+    t.Setenv("HOME", dir)
+
+    root, err := Root()
+    if err != nil {
+        t.Fatalf("Root: %v", err)
+    }
+    if runtime.GOOS != "darwin" && runtime.GOOS != "ios" && root != filepath.Join(dir, "scarf") {
+        t.Fatalf("expected the platform state directory, got %q", root)
+    }
+    if Overridden() {
+        t.Fatal("expected no override")
+    }
+
+    override := filepath.Join(dir, "override")
+    t.Setenv(EnvVar, override)
+    if root, _ := Root(); root != override || !Overridden() {
+        t.Fatalf("expected %s to override the root, got %q", EnvVar, root)
+    }
+    if app, err := Dir("mytool"); err != nil || app != filepath.Join(override, "mytool") {
+        t.Fatalf("Dir: %q, %v", app, err)
+    }
+    if _, err := Dir(""); err == nil {
+        t.Fatal("expected an error for an empty app name")
+    }
+}
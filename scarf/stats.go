@@ -0,0 +1,113 @@
+package scarf
+
+import (
+    "net/http"
+    "sync"
+    "sync/atomic"
+)
+
+// Stats is a snapshot of a logger's delivery counters, for debugging
+// delivery issues without verbose logging. It marshals to JSON, so it can be
+// published with expvar:
+//
+//   expvar.Publish("scarf", expvar.Func(func() any { return logger.Stats() }))
+type Stats struct {
+    // Attempts counts delivery attempts that reached a transport or
+    // endpoint, including retries.
+    Attempts int64
+    // Successes counts attempts that succeeded.
+    Successes int64
+    // Failures counts failed attempts by class.
+    Failures FailureStats
+    // Retries counts attempts repeating an earlier one: fallback endpoints
+    // and JSON re-sends after an endpoint refused MessagePack.
+    Retries int64
+    // Rejected counts sends refused without an attempt because of backoff
+    // or an open circuit breaker.
+    Rejected int64
+    // Dropped counts events dropped before delivery, by reason.
+    Dropped map[DropReason]int64
+    // Pending is the number of sends in progress.
+    Pending int
+}
+
+// FailureStats counts failed delivery attempts by class.
+type FailureStats struct {
+    // Network counts attempts that got no response, including transport
+    // errors.
+    Network int64
+    // RateLimited counts 429 responses.
+    RateLimited int64
+    // Client counts other 4xx responses.
+    Client int64
+    // Server counts 5xx responses.
+    Server int64
+    // Other counts other non-2xx responses.
+    Other int64
+}
+
+// statsCounters holds the counters behind Stats.
+type statsCounters struct {
+    attempts, successes                         atomic.Int64
+    network, rateLimited, client, server, other atomic.Int64
+    retries, rejected                           atomic.Int64
+
+    mu      sync.Mutex
+    dropped map[DropReason]int64
+}
+
+func (c *statsCounters) recordDelivery(d Delivery) {
+    c.attempts.Add(1)
+    switch {
+    case d.Err == nil:
+        c.successes.Add(1)
+    case d.StatusCode == 0:
+        c.network.Add(1)
+    case d.StatusCode == http.StatusTooManyRequests:
+        c.rateLimited.Add(1)
+    case d.StatusCode >= 400 && d.StatusCode < 500:
+        c.client.Add(1)
+    case d.StatusCode >= 500:
+        c.server.Add(1)
+    default:
+        c.other.Add(1)
+    }
+}
+
+func (c *statsCounters) recordDrop(reason DropReason) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.dropped == nil {
+        c.dropped = make(map[DropReason]int64)
+    }
+    c.dropped[reason]++
+}
+
+// Stats returns a snapshot of the logger's delivery counters.
+func (s *ScarfEventLogger) Stats() Stats {
+    if s == nil {
+        return Stats{}
+    }
+    c := &s.stats
+    st := Stats{
+        Attempts:  c.attempts.Load(),
+        Successes: c.successes.Load(),
+        Failures: FailureStats{
+            Network:     c.network.Load(),
+            RateLimited: c.rateLimited.Load(),
+            Client:      c.client.Load(),
+            Server:      c.server.Load(),
+            Other:       c.other.Load(),
+        },
+        Retries:  c.retries.Load(),
+        Rejected: c.rejected.Load(),
+        Dropped:  map[DropReason]int64{},
+        Pending:  s.Pending(),
+    }
+    c.mu.Lock()
+    for k, v := range c.dropped {
+        st.Dropped[k] = v
+    }
+    c.mu.Unlock()
+    return st
+}
@@ -0,0 +1,52 @@
+package scarf
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestStats(t *testing.T) {
+    status := http.StatusOK
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(status)
+    }))
+    defer srv.Close()
+    down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+    down.Close()
+
+    l := New(down.URL, WithFallbackEndpoints(srv.URL))
+    _ = l.LogEvent(map[string]any{"event": "x"}) // network failure, then success on the fallback
+    status = http.StatusBadRequest
+    _ = l.LogEvent(map[string]any{"event": "x"}) // network failure, then 400
+    l.Close()
+    _ = l.LogEvent(map[string]any{"event": "x"})
+
+    st := l.Stats()
+    want := FailureStats{Network: 2, Client: 1}
+    if st.Attempts != 4 || st.Successes != 1 || st.Failures != want || st.Retries != 2 || st.Pending != 0 {
+        t.Fatalf("unexpected stats %+v", st)
+    }
+    if st.Dropped[DropClosed] != 1 || len(st.Dropped) != 1 {
+        t.Fatalf("unexpected drops %v", st.Dropped)
+    }
+    if _, err := json.Marshal(st); err != nil {
+        t.Fatalf("Stats must marshal to JSON for expvar: %v", err)
+    }
+}
+
+func TestStats_Rejected(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Retry-After", "60")
+        w.WriteHeader(http.StatusTooManyRequests)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL)
+    _ = l.LogEvent(map[string]any{"event": "x"})
+    _ = l.LogEvent(map[string]any{"event": "x"})
+    if st := l.Stats(); st.Attempts != 1 || st.Failures.RateLimited != 1 || st.Rejected != 1 {
+        t.Fatalf("unexpected stats %+v", st)
+    }
+}
@@ -0,0 +1,107 @@
+package scarf
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "sort"
+    "time"
+    "unicode/utf8"
+)
+
+// ErrInvalidProperty matches the errors WithStrict reports for properties
+// that can't be sent faithfully.
+var ErrInvalidProperty = errors.New("scarf: invalid property")
+
+// PropertyError is returned under WithStrict for an event with an invalid
+// property, or an invalid name when Key is "event". It matches
+// ErrInvalidProperty.
+type PropertyError struct {
+    Key    string
+    Reason string
+}
+
+func (e *PropertyError) Error() string {
+    return fmt.Sprintf("%v %q: %s", ErrInvalidProperty, e.Key, e.Reason)
+}
+
+func (e *PropertyError) Unwrap() error {
+    return ErrInvalidProperty
+}
+
+// WithStrict makes mistakes fail loudly instead of being sent best effort,
+// e.g. in development and tests. Events fail with a *PropertyError, without
+// being sent, when a key, string value or the event name isn't valid UTF-8,
+// a float is NaN or infinite, or a value isn't one of nil, a string, a bool,
+// an integer, a float, a []byte, a time.Time, a time.Duration, an error or a
+// fmt.Stringer (maps, slices and structs would otherwise be JSON-encoded into
+// a query parameter). Events over the WithMaxPayloadSize limit fail with
+// ErrPayloadTooLarge rather than being truncated or losing properties;
+// OversizeBody still moves them into a body. Properties added by enrichers
+// are checked too. In LogEvents and StreamEvents, invalid events are left out
+// and their errors joined to the result.
+func WithStrict(strict bool) Option {
+    return func(s *ScarfEventLogger) {
+        s.strict = strict
+    }
+}
+
+// checkStrict validates ev under WithStrict, reporting the first invalid
+// property in key order.
+func (s *ScarfEventLogger) checkStrict(ev Event) error {
+    if !s.strict {
+        return nil
+    }
+    err := strictEvent(ev)
+    if err != nil {
+        s.warn("refusing invalid event", "event", ev.Name, "error", err)
+        s.notifyDrop(DropInvalid)
+    }
+    return err
+}
+
+func strictEvent(ev Event) error {
+    if !utf8.ValidString(ev.Name) {
+        return &PropertyError{Key: "event", Reason: "name is not valid UTF-8"}
+    }
+    keys := make([]string, 0, len(ev.Properties))
+    for k := range ev.Properties {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        if !utf8.ValidString(k) {
+            return &PropertyError{Key: k, Reason: "key is not valid UTF-8"}
+        }
+        if reason := strictValue(ev.Properties[k]); reason != "" {
+            return &PropertyError{Key: k, Reason: reason}
+        }
+    }
+    return nil
+}
+
+// strictValue returns why v can't be sent under WithStrict, or "".
+func strictValue(v any) string {
+    switch vv := v.(type) {
+    case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+        []byte, time.Time, time.Duration, error, fmt.Stringer:
+        return ""
+    case string:
+        if !utf8.ValidString(vv) {
+            return "value is not valid UTF-8"
+        }
+        return ""
+    case float32:
+        return strictFloat(float64(vv))
+    case float64:
+        return strictFloat(vv)
+    }
+    return fmt.Sprintf("unsupported value type %T", v)
+}
+
+func strictFloat(f float64) string {
+    if math.IsNaN(f) || math.IsInf(f, 0) {
+        return fmt.Sprintf("value %v is not a finite number", f)
+    }
+    return ""
+}
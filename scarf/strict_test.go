@@ -0,0 +1,75 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "math"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestWithStrict(t *testing.T) {
+    var hits atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        hits.Add(1)
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithStrict(true))
+    cases := []struct {
+        props map[string]any
+        key   string
+    }{
+        {map[string]any{"event": "x", "nested": map[string]any{"a": 1}}, "nested"},
+        {map[string]any{"event": "x", "ratio": math.NaN()}, "ratio"},
+        {map[string]any{"event": "x", "big": math.Inf(1)}, "big"},
+        {map[string]any{"event": "x", "name": "bad\xff"}, "name"},
+        {map[string]any{"event": "bad\xff"}, "event"},
+        {map[string]any{"event": "x", "list": []string{"a"}}, "list"},
+    }
+    for _, c := range cases {
+        err := l.LogEvent(c.props)
+        var pe *PropertyError
+        if !errors.As(err, &pe) || pe.Key != c.key || !errors.Is(err, ErrInvalidProperty) {
+            t.Errorf("LogEvent(%v): expected a PropertyError for %q, got %v", c.props, c.key, err)
+        }
+    }
+    if hits.Load() != 0 {
+        t.Fatalf("expected invalid events not to be sent, got %d requests", hits.Load())
+    }
+
+    ok := map[string]any{"event": "x", "n": 3, "f": 1.5, "ok": true, "at": time.Now(), "took": time.Second, "s": "é", "none": nil}
+    if err := l.LogEvent(ok); err != nil {
+        t.Fatalf("expected valid properties to be sent, got %v", err)
+    }
+    if err := New(srv.URL).LogEvent(cases[1].props); err != nil {
+        t.Fatalf("expected best-effort sends without WithStrict, got %v", err)
+    }
+
+    err := l.LogEvents(context.Background(), []Event{{Name: "a"}, {Name: "b", Properties: map[string]any{"f": math.NaN()}}})
+    if !errors.Is(err, ErrInvalidProperty) {
+        t.Fatalf("expected the invalid batch event to be reported, got %v", err)
+    }
+    if r := l.LogEventAsync(cases[0].props); !errors.Is(r.Err(), ErrInvalidProperty) {
+        t.Fatalf("expected the async receipt to report the invalid event, got %v", r.Err())
+    }
+}
+
+func TestWithStrictPayloadSize(t *testing.T) {
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+    defer srv.Close()
+
+    big := map[string]any{"event": "x", "blob": strings.Repeat("a", 500)}
+    l := New(srv.URL, WithStrict(true), WithMaxPayloadSize(200, OversizeTruncate))
+    if err := l.LogEvent(big); !errors.Is(err, ErrPayloadTooLarge) {
+        t.Fatalf("expected ErrPayloadTooLarge instead of truncation, got %v", err)
+    }
+    l = New(srv.URL, WithStrict(true), WithMaxPayloadSize(200, OversizeBody))
+    if err := l.LogEvent(big); err != nil {
+        t.Fatalf("expected OversizeBody to still send, got %v", err)
+    }
+}
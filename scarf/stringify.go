@@ -0,0 +1,51 @@
+//go:build !tinygo
+
+package scarf
+
+import (
+    "encoding/json"
+    "fmt"
+    "strconv"
+)
+
+// stringifyParam converts a property value into a string suitable for URL query parameters.
+// Simple types use fmt.Sprint; complex types are JSON-encoded.
+func stringifyParam(v any) string {
+    switch vv := CanonicalValue(v).(type) {
+    case string:
+        return vv
+    case fmt.Stringer:
+        return vv.String()
+    // Common scalars skip encoding/json; the output is the same.
+    case bool:
+        return strconv.FormatBool(vv)
+    case int:
+        return strconv.Itoa(vv)
+    case int64:
+        return strconv.FormatInt(vv, 10)
+    case uint64:
+        return strconv.FormatUint(vv, 10)
+    case float64:
+        return string(appendFloat(nil, vv, 64))
+    case float32:
+        return string(appendFloat(nil, float64(vv), 32))
+    default:
+        // Try to JSON-encode complex types for stability.
+        b, err := json.Marshal(vv)
+        if err == nil {
+            // Use the JSON as-is for objects/arrays, but avoid quoting simple scalars twice.
+            // If result is a quoted string, trim quotes for more natural query values.
+            s := string(b)
+            if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+                return s[1 : len(s)-1]
+            }
+            return s
+        }
+        return fmt.Sprint(vv)
+    }
+}
+
+// marshalObject JSON-encodes the fields of an event body.
+func marshalObject(fields map[string]any) ([]byte, error) {
+    return json.Marshal(fields)
+}
@@ -0,0 +1,28 @@
+//go:build !tinygo
+
+package scarf
+
+import "testing"
+
+func TestStringifyParamJSON(t *testing.T) {
+    type build struct {
+        Target string `json:"target"`
+        Cached bool   `json:"cached,omitempty"`
+    }
+    n := 7
+    cases := []struct {
+        in   any
+        want string
+    }{
+        {build{Target: "linux"}, `{"target":"linux"}`},
+        {&n, "7"},
+        {[]byte("hi"), "aGk="},
+        {[]string{"a", "b"}, `["a","b"]`},
+        {0.1, "0.1"},
+    }
+    for _, c := range cases {
+        if got := stringifyParam(c.in); got != c.want {
+            t.Errorf("stringifyParam(%#v) = %q, want %q", c.in, got, c.want)
+        }
+    }
+}
@@ -0,0 +1,164 @@
+//go:build tinygo
+
+package scarf
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// stringifyParam converts a property value into a string suitable for URL query parameters.
+// Under TinyGo, common slices and maps are encoded as JSON by hand instead of
+// via encoding/json's reflection; other types fall back to fmt.Sprint.
+func stringifyParam(v any) string {
+    switch vv := CanonicalValue(v).(type) {
+    case string:
+        return vv
+    case fmt.Stringer:
+        return vv.String()
+    default:
+        if s, ok := appendJSON(nil, vv, true); ok {
+            return string(s)
+        }
+        return fmt.Sprint(vv)
+    }
+}
+
+// marshalObject JSON-encodes the fields of an event body by hand, so body
+// mode, batches and transports avoid encoding/json's reflection. Bodies with
+// values appendJSON doesn't know, such as structs, fall back to
+// encoding/json.
+func marshalObject(fields map[string]any) ([]byte, error) {
+    if b, ok := appendJSONObject(nil, fields); ok {
+        return b, nil
+    }
+    return json.Marshal(fields)
+}
+
+// appendJSON encodes the subset of types it knows without reflection. At the
+// top level strings are left unquoted, matching the default encoder.
+func appendJSON(b []byte, v any, top bool) ([]byte, bool) {
+    switch vv := v.(type) {
+    case nil:
+        return append(b, "null"...), true
+    case string:
+        if top {
+            return append(b, vv...), true
+        }
+        return appendJSONString(b, vv), true
+    case bool:
+        return strconv.AppendBool(b, vv), true
+    case int:
+        return strconv.AppendInt(b, int64(vv), 10), true
+    case int8:
+        return strconv.AppendInt(b, int64(vv), 10), true
+    case int16:
+        return strconv.AppendInt(b, int64(vv), 10), true
+    case int32:
+        return strconv.AppendInt(b, int64(vv), 10), true
+    case int64:
+        return strconv.AppendInt(b, vv, 10), true
+    case uint:
+        return strconv.AppendUint(b, uint64(vv), 10), true
+    case uint8:
+        return strconv.AppendUint(b, uint64(vv), 10), true
+    case uint16:
+        return strconv.AppendUint(b, uint64(vv), 10), true
+    case uint32:
+        return strconv.AppendUint(b, uint64(vv), 10), true
+    case uint64:
+        return strconv.AppendUint(b, vv, 10), true
+    case float32:
+        return appendFloat(b, float64(vv), 32), true
+    case float64:
+        return appendFloat(b, vv, 64), true
+    case []string:
+        items := make([]any, len(vv))
+        for i, s := range vv {
+            items[i] = s
+        }
+        return appendJSONArray(b, items)
+    case []int:
+        items := make([]any, len(vv))
+        for i, n := range vv {
+            items[i] = n
+        }
+        return appendJSONArray(b, items)
+    case []any:
+        return appendJSONArray(b, vv)
+    case map[string]string:
+        m := make(map[string]any, len(vv))
+        for k, s := range vv {
+            m[k] = s
+        }
+        return appendJSONObject(b, m)
+    case map[string]any:
+        return appendJSONObject(b, vv)
+    }
+    return b, false
+}
+
+func appendJSONArray(b []byte, items []any) ([]byte, bool) {
+    b = append(b, '[')
+    for i, item := range items {
+        if i > 0 {
+            b = append(b, ',')
+        }
+        var ok bool
+        if b, ok = appendJSON(b, item, false); !ok {
+            return b, false
+        }
+    }
+    return append(b, ']'), true
+}
+
+func appendJSONObject(b []byte, m map[string]any) ([]byte, bool) {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    b = append(b, '{')
+    for i, k := range keys {
+        if i > 0 {
+            b = append(b, ',')
+        }
+        b = appendJSONString(b, k)
+        b = append(b, ':')
+        var ok bool
+        if b, ok = appendJSON(b, m[k], false); !ok {
+            return b, false
+        }
+    }
+    return append(b, '}'), true
+}
+
+func appendJSONString(b []byte, s string) []byte {
+    var sb strings.Builder
+    sb.WriteByte('"')
+    for _, r := range s {
+        switch r {
+        case '"':
+            sb.WriteString(`\"`)
+        case '\\':
+            sb.WriteString(`\\`)
+        case '\n':
+            sb.WriteString(`\n`)
+        case '\r':
+            sb.WriteString(`\r`)
+        case '\t':
+            sb.WriteString(`\t`)
+        default:
+            if r < 0x20 {
+                fmt.Fprintf(&sb, `\u%04x`, r)
+            } else {
+                sb.WriteRune(r)
+            }
+        }
+    }
+    sb.WriteByte('"')
+    return append(b, sb.String()...)
+}
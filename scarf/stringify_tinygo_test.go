@@ -0,0 +1,48 @@
+//go:build tinygo
+
+package scarf
+
+import (
+    "testing"
+)
+
+func TestStringifyParam_TinyGo(t *testing.T) {
+    cases := []struct {
+        in   any
+        want string
+    }{
+        {"plain", "plain"},
+        {42, "42"},
+        {2.5, "2.5"},
+        {true, "true"},
+        {[]string{"a", `b"c`}, `["a","b\"c"]`},
+        {[]any{1, "x", nil}, `[1,"x",null]`},
+        {map[string]any{"b": 1, "a": []int{2}}, `{"a":[2],"b":1}`},
+        {struct{ X int }{1}, "{1}"},
+    }
+    for _, c := range cases {
+        if got := stringifyParam(c.in); got != c.want {
+            t.Errorf("stringifyParam(%#v) = %q, want %q", c.in, got, c.want)
+        }
+    }
+}
+
+func TestJSONBody_TinyGo(t *testing.T) {
+    ev := Event{Name: "x", ID: "id", Properties: map[string]any{
+        "n": 3, "tags": []string{"a"}, "nested": map[string]any{"ok": true}, "s": struct{ X int }{1},
+    }}
+    body, err := ev.jsonBody()
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := `{"event":"x","event_id":"id","n":3,"nested":{"ok":true},"s":{"X":1},"tags":["a"]}`
+    if string(body) != want {
+        t.Fatalf("unexpected JSON body:\n got %s\nwant %s", body, want)
+    }
+
+    // encoding/json would escape "<" as \u003c.
+    ev.Properties["s"] = "a<b"
+    if body, _ := ev.jsonBody(); string(body) != `{"event":"x","event_id":"id","n":3,"nested":{"ok":true},"s":"a<b","tags":["a"]}` {
+        t.Fatalf("expected a hand-encoded JSON body, got %s", body)
+    }
+}
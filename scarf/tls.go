@@ -0,0 +1,78 @@
+package scarf
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "os"
+)
+
+// WithTLSConfig uses cfg as the base TLS configuration for the SDK's own
+// client. Certificates from WithClientCert and WithCACert are added to a
+// copy of it, independent of option order. Like WithProxy, TLS options have
+// no effect together with WithHTTPClient.
+func WithTLSConfig(cfg *tls.Config) Option {
+    return func(s *ScarfEventLogger) {
+        s.tls.base = cfg
+    }
+}
+
+// WithClientCert presents the PEM-encoded certificate and key in certFile and
+// keyFile to servers requiring client certificates (mTLS). If they can't be
+// loaded, every send fails with the load error.
+func WithClientCert(certFile, keyFile string) Option {
+    return func(s *ScarfEventLogger) {
+        cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+        if err != nil {
+            s.tls.err = errors.Join(s.tls.err, fmt.Errorf("scarf: load client certificate: %w", err))
+            return
+        }
+        s.tls.certs = append(s.tls.certs, cert)
+    }
+}
+
+// WithCACert trusts the PEM-encoded CA certificates in path, in addition to
+// the system roots, e.g. for self-hosted gateways behind a private CA. If the
+// file can't be loaded, every send fails with the load error.
+func WithCACert(path string) Option {
+    return func(s *ScarfEventLogger) {
+        pem, err := os.ReadFile(path)
+        if err != nil {
+            s.tls.err = errors.Join(s.tls.err, fmt.Errorf("scarf: load CA certificate: %w", err))
+            return
+        }
+        if s.tls.roots == nil {
+            if s.tls.roots, err = x509.SystemCertPool(); err != nil {
+                s.tls.roots = x509.NewCertPool()
+            }
+        }
+        if !s.tls.roots.AppendCertsFromPEM(pem) {
+            s.tls.err = errors.Join(s.tls.err, fmt.Errorf("scarf: load CA certificate: no certificates found in %s", path))
+        }
+    }
+}
+
+// tlsOptions collects the TLS options for the SDK's own client.
+type tlsOptions struct {
+    base  *tls.Config
+    certs []tls.Certificate
+    roots *x509.CertPool
+    err   error
+}
+
+// config returns the TLS configuration to use, or nil for the default.
+func (o *tlsOptions) config() *tls.Config {
+    if o.base == nil && o.certs == nil && o.roots == nil {
+        return nil
+    }
+    cfg := &tls.Config{}
+    if o.base != nil {
+        cfg = o.base.Clone()
+    }
+    cfg.Certificates = append(cfg.Certificates, o.certs...)
+    if o.roots != nil {
+        cfg.RootCAs = o.roots
+    }
+    return cfg
+}
@@ -0,0 +1,100 @@
+package scarf
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/tls"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// writeClientCert writes a self-signed client certificate and key to dir.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+    t.Helper()
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    tmpl := &x509.Certificate{
+        SerialNumber: big.NewInt(1),
+        Subject:      pkix.Name{CommonName: "scarf-test-client"},
+        NotBefore:    time.Now().Add(-time.Hour),
+        NotAfter:     time.Now().Add(time.Hour),
+        ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+    }
+    der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    keyDER, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    certFile, keyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+    if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return certFile, keyFile
+}
+
+func TestTLSOptions_CAAndClientCert(t *testing.T) {
+    var clientCN string
+    srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if len(r.TLS.PeerCertificates) > 0 {
+            clientCN = r.TLS.PeerCertificates[0].Subject.CommonName
+        }
+        w.WriteHeader(http.StatusOK)
+    }))
+    srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+    srv.StartTLS()
+    defer srv.Close()
+
+    dir := t.TempDir()
+    caFile := filepath.Join(dir, "ca.pem")
+    if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    certFile, keyFile := writeClientCert(t, dir)
+
+    if err := New(srv.URL).LogEvent(map[string]any{"event": "x"}); err == nil {
+        t.Fatalf("expected the private CA to be rejected by default")
+    }
+    l := New(srv.URL, WithCACert(caFile), WithClientCert(certFile, keyFile), WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if clientCN != "scarf-test-client" {
+        t.Fatalf("expected client certificate to be presented, got %q", clientCN)
+    }
+}
+
+func TestTLSOptions_LoadErrors(t *testing.T) {
+    dir := t.TempDir()
+    empty := filepath.Join(dir, "empty.pem")
+    if err := os.WriteFile(empty, []byte("not a cert"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    for name, opt := range map[string]Option{
+        "missing CA":   WithCACert(filepath.Join(dir, "nope.pem")),
+        "empty CA":     WithCACert(empty),
+        "missing cert": WithClientCert(filepath.Join(dir, "c.pem"), filepath.Join(dir, "k.pem")),
+    } {
+        err := New("https://collector.invalid", opt).LogEvent(map[string]any{"event": "x"})
+        if err == nil || !strings.Contains(err.Error(), "scarf: load") {
+            t.Errorf("%s: expected load error, got %v", name, err)
+        }
+    }
+}
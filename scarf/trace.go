@@ -0,0 +1,104 @@
+package scarf
+
+import (
+    "context"
+    "net/http"
+)
+
+// TraceparentHeader is the W3C Trace Context request header set by
+// WithTraceparentHeader.
+const TraceparentHeader = "traceparent"
+
+// SpanContext identifies the span an event was logged in. TraceID and SpanID
+// are lowercase hex, 32 and 16 characters long.
+type SpanContext struct {
+    TraceID string
+    SpanID  string
+    Sampled bool
+}
+
+// TraceExtractor returns the span carried by ctx, if any.
+// scarfotel.TraceContext extracts OpenTelemetry spans.
+type TraceExtractor func(ctx context.Context) (SpanContext, bool)
+
+// WithTraceContext attaches trace_id and span_id properties to events logged
+// with a context that carries a span, as reported by extract, so telemetry
+// can be correlated with distributed traces. Properties passed by the caller
+// take precedence.
+func WithTraceContext(extract TraceExtractor) Option {
+    return func(s *ScarfEventLogger) {
+        s.traceExtract = extract
+    }
+}
+
+// WithTraceparentHeader also sends the span as a traceparent header on HTTP
+// requests. It has no effect without WithTraceContext.
+func WithTraceparentHeader() Option {
+    return func(s *ScarfEventLogger) {
+        s.traceparent = true
+    }
+}
+
+// spanContext returns the span in ctx, if tracing is configured and the IDs
+// are well-formed. An extractor that panics reports no span.
+func (s *ScarfEventLogger) spanContext(ctx context.Context) (SpanContext, bool) {
+    if s.traceExtract == nil {
+        return SpanContext{}, false
+    }
+    var sc SpanContext
+    var ok bool
+    if s.guard("trace extractor", func() { sc, ok = s.traceExtract(ctx) }) != nil {
+        return SpanContext{}, false
+    }
+    if !ok || !validTraceID(sc.TraceID, 32) || !validTraceID(sc.SpanID, 16) {
+        return SpanContext{}, false
+    }
+    return sc, true
+}
+
+// tagTrace adds the span in ctx to props.
+func (s *ScarfEventLogger) tagTrace(ctx context.Context, props map[string]any) {
+    sc, ok := s.spanContext(ctx)
+    if !ok {
+        return
+    }
+    if _, set := props["trace_id"]; !set {
+        props["trace_id"] = sc.TraceID
+    }
+    if _, set := props["span_id"]; !set {
+        props["span_id"] = sc.SpanID
+    }
+}
+
+// setTraceparent sets the traceparent header on req from the span in ctx.
+func (s *ScarfEventLogger) setTraceparent(ctx context.Context, req *http.Request) {
+    if !s.traceparent {
+        return
+    }
+    sc, ok := s.spanContext(ctx)
+    if !ok {
+        return
+    }
+    flags := "00"
+    if sc.Sampled {
+        flags = "01"
+    }
+    req.Header.Set(TraceparentHeader, "00-"+sc.TraceID+"-"+sc.SpanID+"-"+flags)
+}
+
+// validTraceID reports whether id is n lowercase hex characters and not all
+// zeros, which the W3C Trace Context spec reserves as invalid.
+func validTraceID(id string, n int) bool {
+    if len(id) != n {
+        return false
+    }
+    zero := true
+    for i := 0; i < len(id); i++ {
+        c := id[i]
+        if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+            return false
+        }
+        zero = zero && c == '0'
+    }
+    return !zero
+}
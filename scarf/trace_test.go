@@ -0,0 +1,85 @@
+package scarf
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+type spanKey struct{}
+
+func testExtractor(ctx context.Context) (SpanContext, bool) {
+    sc, ok := ctx.Value(spanKey{}).(SpanContext)
+    return sc, ok
+}
+
+func TestWithTraceContext(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithTraceContext(testExtractor))
+    sc := SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}
+    ctx := context.WithValue(context.Background(), spanKey{}, sc)
+
+    if err := l.LogEventContext(ctx, map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEventContext: %v", err)
+    }
+    if err := l.LogEventContext(ctx, map[string]any{"event": "x", "span_id": "mine"}); err != nil {
+        t.Fatalf("LogEventContext: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    bad := context.WithValue(context.Background(), spanKey{}, SpanContext{TraceID: "00000000000000000000000000000000", SpanID: sc.SpanID})
+    if err := l.LogEventContext(bad, map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEventContext: %v", err)
+    }
+
+    if p := got[0].Properties; p["trace_id"] != sc.TraceID || p["span_id"] != sc.SpanID {
+        t.Fatalf("expected trace properties, got %v", p)
+    }
+    if p := got[1].Properties; p["span_id"] != "mine" || p["trace_id"] != sc.TraceID {
+        t.Fatalf("caller properties should win, got %v", p)
+    }
+    for _, ev := range got[2:] {
+        if _, ok := ev.Properties["trace_id"]; ok {
+            t.Fatalf("expected no trace properties without a valid span, got %v", ev.Properties)
+        }
+    }
+}
+
+func TestWithTraceContext_Panic(t *testing.T) {
+    var got []Event
+    l := New("", captureEvents(&got), WithTraceContext(func(context.Context) (SpanContext, bool) {
+        panic("boom")
+    }))
+    if err := l.LogEventContext(context.Background(), map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("expected the event to be sent despite the panic, got %v", err)
+    }
+    if len(got) != 1 {
+        t.Fatalf("expected 1 event, got %d", len(got))
+    }
+    if _, ok := got[0].Properties["trace_id"]; ok {
+        t.Fatalf("expected no trace properties after a panic, got %v", got[0].Properties)
+    }
+}
+
+func TestWithTraceparentHeader(t *testing.T) {
+    var headers []string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        headers = append(headers, r.Header.Get(TraceparentHeader))
+    }))
+    defer srv.Close()
+
+    l := New(srv.URL, WithTraceContext(testExtractor), WithTraceparentHeader())
+    sc := SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"}
+    ctx := context.WithValue(context.Background(), spanKey{}, sc)
+    if err := l.LogEventContext(ctx, map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEventContext: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if headers[0] != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00" || headers[1] != "" {
+        t.Fatalf("unexpected traceparent headers %q", headers)
+    }
+}
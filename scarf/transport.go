@@ -0,0 +1,82 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+)
+
+// Transport delivers a single event. The default transport POSTs the event
+// to the endpoint URL over HTTP; a custom Transport replaces it entirely, so
+// the endpoint URL may be left empty.
+//
+// The context carries the per-call timeout. Send may be called concurrently.
+type Transport interface {
+    Send(ctx context.Context, ev Event) error
+}
+
+// TransportFunc adapts a function to the Transport interface.
+type TransportFunc func(ctx context.Context, ev Event) error
+
+// Send calls f(ctx, ev).
+func (f TransportFunc) Send(ctx context.Context, ev Event) error {
+    return f(ctx, ev)
+}
+
+// WithTransport delivers events through t instead of HTTP. Sampling,
+// timestamps, IDs, the circuit breaker, observers and dry-run mode still
+// apply.
+func WithTransport(t Transport) Option {
+    return func(s *ScarfEventLogger) {
+        s.transport = t
+    }
+}
+
+// TeeTransport sends every event to both a and b, e.g. to Scarf and also to
+// a local log. The sends run concurrently; the result joins both errors. A
+// panic in b, which runs on its own goroutine, is recovered and returned as
+// an error. A nil transport is ignored.
+func TeeTransport(a, b Transport) Transport {
+    switch {
+    case a == nil && b == nil:
+        return TransportFunc(func(context.Context, Event) error { return nil })
+    case a == nil:
+        return b
+    case b == nil:
+        return a
+    }
+    return TransportFunc(func(ctx context.Context, ev Event) error {
+        errc := make(chan error, 1)
+        go func() {
+            defer func() {
+                if v := recover(); v != nil {
+                    errc <- errPanic("transport", v)
+                }
+            }()
+            errc <- b.Send(ctx, ev)
+        }()
+        errA := a.Send(ctx, ev)
+        return errors.Join(errA, <-errc)
+    })
+}
+
+// ChainTransport sends every event to primary and, only if that fails, to
+// fallback. It fails only if both do, with both errors. A nil transport is
+// ignored.
+func ChainTransport(primary, fallback Transport) Transport {
+    switch {
+    case primary == nil:
+        return TeeTransport(nil, fallback)
+    case fallback == nil:
+        return primary
+    }
+    return TransportFunc(func(ctx context.Context, ev Event) error {
+        err := primary.Send(ctx, ev)
+        if err == nil {
+            return nil
+        }
+        if ferr := fallback.Send(ctx, ev); ferr != nil {
+            return errors.Join(err, ferr)
+        }
+        return nil
+    })
+}
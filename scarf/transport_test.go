@@ -0,0 +1,114 @@
+package scarf
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestWithTransport(t *testing.T) {
+    var got []Event
+    var deadline time.Time
+    tr := TransportFunc(func(ctx context.Context, ev Event) error {
+        deadline, _ = ctx.Deadline()
+        got = append(got, ev)
+        return nil
+    })
+
+    l := New("", WithTransport(tr), WithTimeout(time.Second))
+    if err := l.LogEvent(map[string]any{"event": "custom", "k": "v"}); err != nil {
+        t.Fatalf("expected success, got %v", err)
+    }
+    if len(got) != 1 || got[0].Name != "custom" || got[0].Properties["k"] != "v" || got[0].ID == "" {
+        t.Fatalf("unexpected events: %+v", got)
+    }
+    if time.Until(deadline) > time.Second || deadline.IsZero() {
+        t.Fatalf("expected context deadline within timeout, got %s", deadline)
+    }
+}
+
+func TestWithTransport_Error(t *testing.T) {
+    boom := errors.New("boom")
+    var failures int
+    l := New("",
+        WithTransport(TransportFunc(func(context.Context, Event) error { return boom })),
+        WithOnFailure(func(Delivery) { failures++ }),
+    )
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, boom) {
+        t.Fatalf("expected transport error to be wrapped, got %v", err)
+    }
+    if failures != 1 {
+        t.Fatalf("expected observer to see the failure, got %d", failures)
+    }
+}
+
+func TestTeeTransport(t *testing.T) {
+    var a, b []Event
+    var mu sync.Mutex
+    record := func(got *[]Event, err error) Transport {
+        return TransportFunc(func(_ context.Context, ev Event) error {
+            mu.Lock()
+            defer mu.Unlock()
+            *got = append(*got, ev)
+            return err
+        })
+    }
+    l := New("", WithTransport(TeeTransport(record(&a, nil), record(&b, nil))))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    if len(a) != 1 || len(b) != 1 || a[0].ID != b[0].ID {
+        t.Fatalf("expected the event on both transports, got %v / %v", a, b)
+    }
+
+    boom := errors.New("boom")
+    l = New("", WithTransport(TeeTransport(record(&a, nil), record(&b, boom))))
+    if err := l.LogEvent(map[string]any{"event": "y"}); !errors.Is(err, boom) {
+        t.Fatalf("expected the failing side's error, got %v", err)
+    }
+    if len(a) != 2 {
+        t.Fatalf("a failure on one side must not stop the other")
+    }
+}
+
+func TestChainTransport(t *testing.T) {
+    boom, bust := errors.New("boom"), errors.New("bust")
+    var fallbacks int
+    fallback := func(err error) Transport {
+        return TransportFunc(func(context.Context, Event) error {
+            fallbacks++
+            return err
+        })
+    }
+    ok := TransportFunc(func(context.Context, Event) error { return nil })
+    fail := TransportFunc(func(context.Context, Event) error { return boom })
+
+    if err := New("", WithTransport(ChainTransport(ok, fallback(nil)))).LogEvent(map[string]any{"event": "x"}); err != nil || fallbacks != 0 {
+        t.Fatalf("expected the primary only, got %v (%d fallbacks)", err, fallbacks)
+    }
+    if err := New("", WithTransport(ChainTransport(fail, fallback(nil)))).LogEvent(map[string]any{"event": "x"}); err != nil || fallbacks != 1 {
+        t.Fatalf("expected the fallback to succeed, got %v (%d fallbacks)", err, fallbacks)
+    }
+    err := New("", WithTransport(ChainTransport(fail, fallback(bust)))).LogEvent(map[string]any{"event": "x"})
+    if !errors.Is(err, boom) || !errors.Is(err, bust) {
+        t.Fatalf("expected both errors, got %v", err)
+    }
+}
+
+func TestTeeTransport_PanicAndNil(t *testing.T) {
+    ok := TransportFunc(func(context.Context, Event) error { return nil })
+    panicky := TransportFunc(func(context.Context, Event) error { panic("boom") })
+    l := New("", WithTransport(TeeTransport(ok, panicky)))
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil || !strings.Contains(err.Error(), "panicked: boom") {
+        t.Fatalf("expected the secondary's panic as an error, got %v", err)
+    }
+
+    for _, tr := range []Transport{TeeTransport(nil, ok), TeeTransport(ok, nil), TeeTransport(nil, nil), ChainTransport(nil, ok), ChainTransport(ok, nil)} {
+        if err := tr.Send(context.Background(), Event{Name: "x"}); err != nil {
+            t.Fatalf("expected nil transports to be ignored, got %v", err)
+        }
+    }
+}
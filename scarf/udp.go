@@ -0,0 +1,71 @@
+package scarf
+
+import (
+    "context"
+    "fmt"
+    "net"
+)
+
+// maxUDPPayload is the largest UDP payload that can be sent over IPv4.
+const maxUDPPayload = 65507
+
+// UDPTransport sends each event as a single JSON datagram, statsd-style. It
+// never waits for a reply, so delivery is at most once and a send costs only a
+// local syscall, even if the collector is down. Use it where an HTTP
+// round trip is unacceptable:
+//
+//   t, err := scarf.NewUDPTransport("collector.internal:8125")
+//   if err != nil { ... }
+//   defer t.Close()
+//   logger := scarf.New("", scarf.WithTransport(t))
+//
+// Events larger than a datagram fail with ErrPayloadTooLarge.
+type UDPTransport struct {
+    conn net.Conn
+}
+
+// NewUDPTransport returns a UDPTransport sending to addr ("host:port"). The
+// address is resolved once, here.
+func NewUDPTransport(addr string) (*UDPTransport, error) {
+    if buildDisabled {
+        return &UDPTransport{}, nil
+    }
+    conn, err := net.Dial("udp", addr)
+    if err != nil {
+        return nil, fmt.Errorf("scarf: dial udp: %w", err)
+    }
+    return &UDPTransport{conn: conn}, nil
+}
+
+// Send writes ev as one datagram. Errors only reflect local failures (or, on
+// some platforms, an ICMP error from an earlier send); they never mean the
+// event was received.
+func (t *UDPTransport) Send(ctx context.Context, ev Event) error {
+    if buildDisabled {
+        return ErrDisabled
+    }
+    b, err := ev.jsonBody()
+    if err != nil {
+        return fmt.Errorf("scarf: encode event: %w", err)
+    }
+    if len(b) > maxUDPPayload {
+        return ErrPayloadTooLarge
+    }
+    // A zero deadline clears any earlier one. Concurrent Sends may race on
+    // the deadline, but each covers a single non-blocking write, so the
+    // effect is at worst a slightly different timeout.
+    deadline, _ := ctx.Deadline()
+    _ = t.conn.SetWriteDeadline(deadline)
+    if _, err := t.conn.Write(b); err != nil {
+        return fmt.Errorf("scarf: udp send: %w", err)
+    }
+    return nil
+}
+
+// Close releases the socket.
+func (t *UDPTransport) Close() error {
+    if t.conn == nil {
+        return nil
+    }
+    return t.conn.Close()
+}
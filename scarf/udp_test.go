@@ -0,0 +1,44 @@
+package scarf
+
+import (
+    "encoding/json"
+    "errors"
+    "net"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestUDPTransport(t *testing.T) {
+    pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+    if err != nil {
+        t.Skipf("udp not available: %v", err)
+    }
+    defer pc.Close()
+
+    tr, err := NewUDPTransport(pc.LocalAddr().String())
+    if err != nil {
+        t.Fatalf("NewUDPTransport: %v", err)
+    }
+    defer tr.Close()
+
+    l := New("", WithTransport(tr))
+    if err := l.LogEvent(map[string]any{"event": "x", "n": 1}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+    buf := make([]byte, maxUDPPayload)
+    pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+    n, _, err := pc.ReadFrom(buf)
+    if err != nil {
+        t.Fatalf("ReadFrom: %v", err)
+    }
+    var fields map[string]any
+    if err := json.Unmarshal(buf[:n], &fields); err != nil || fields["event"] != "x" || fields["n"] != 1.0 || fields["event_id"] == nil {
+        t.Fatalf("unexpected datagram %s (%v)", buf[:n], err)
+    }
+
+    err = l.LogEvent(map[string]any{"event": "big", "v": strings.Repeat("a", maxUDPPayload)})
+    if !errors.Is(err, ErrPayloadTooLarge) {
+        t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+    }
+}
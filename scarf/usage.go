@@ -0,0 +1,41 @@
+package scarf
+
+import (
+    "strconv"
+    "time"
+)
+
+// latencyBuckets are the upper bounds used by LatencyBucket.
+var latencyBuckets = []struct {
+    max   time.Duration
+    label string
+}{
+    {10 * time.Millisecond, "<10ms"},
+    {50 * time.Millisecond, "<50ms"},
+    {100 * time.Millisecond, "<100ms"},
+    {250 * time.Millisecond, "<250ms"},
+    {500 * time.Millisecond, "<500ms"},
+    {time.Second, "<1s"},
+    {5 * time.Second, "<5s"},
+}
+
+// LatencyBucket returns a coarse label for d ("<10ms", "<50ms", ... "<5s",
+// ">=5s"). Usage events from the middleware and interceptor integrations
+// report latency this way, so it aggregates well and reveals nothing
+// precise about the host.
+func LatencyBucket(d time.Duration) string {
+    for _, b := range latencyBuckets {
+        if d < b.max {
+            return b.label
+        }
+    }
+    return ">=5s"
+}
+
+// StatusClass returns the class of an HTTP status code, e.g. "2xx" for 204.
+func StatusClass(code int) string {
+    if code < 100 || code > 999 {
+        return "unknown"
+    }
+    return strconv.Itoa(code/100) + "xx"
+}
@@ -0,0 +1,31 @@
+package scarf
+
+import (
+    "testing"
+    "time"
+)
+
+func TestLatencyBucket(t *testing.T) {
+    cases := map[time.Duration]string{
+        0:                      "<10ms",
+        10 * time.Millisecond:  "<50ms",
+        120 * time.Millisecond: "<250ms",
+        999 * time.Millisecond: "<1s",
+        5 * time.Second:        ">=5s",
+        time.Hour:              ">=5s",
+    }
+    for d, want := range cases {
+        if got := LatencyBucket(d); got != want {
+            t.Errorf("LatencyBucket(%s) = %q, want %q", d, got, want)
+        }
+    }
+}
+
+func TestStatusClass(t *testing.T) {
+    cases := map[int]string{200: "2xx", 204: "2xx", 302: "3xx", 404: "4xx", 503: "5xx", 0: "unknown"}
+    for code, want := range cases {
+        if got := StatusClass(code); got != want {
+            t.Errorf("StatusClass(%d) = %q, want %q", code, got, want)
+        }
+    }
+}
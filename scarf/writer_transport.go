@@ -0,0 +1,41 @@
+package scarf
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "sync"
+)
+
+// WriterTransport prints each event as a single JSON line to a writer, so
+// developers can check exact payloads during integration without a network
+// endpoint:
+//
+//   logger := scarf.New("", scarf.WithTransport(scarf.NewWriterTransport(os.Stderr)))
+//
+// Lines are written whole, one at a time, even with concurrent sends.
+type WriterTransport struct {
+    mu sync.Mutex
+    w  io.Writer
+}
+
+// NewWriterTransport returns a WriterTransport writing to w, typically
+// os.Stdout or os.Stderr.
+func NewWriterTransport(w io.Writer) *WriterTransport {
+    return &WriterTransport{w: w}
+}
+
+// Send writes ev as one JSON line.
+func (t *WriterTransport) Send(_ context.Context, ev Event) error {
+    b, err := ev.jsonBody()
+    if err != nil {
+        return fmt.Errorf("scarf: encode event: %w", err)
+    }
+    b = append(b, '\n')
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if _, err := t.w.Write(b); err != nil {
+        return fmt.Errorf("scarf: write event: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,33 @@
+package scarf
+
+import (
+    "bytes"
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestWriterTransport(t *testing.T) {
+    var buf bytes.Buffer
+    l := New("", WithTransport(NewWriterTransport(&buf)))
+    ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+    if err := l.LogEventStruct(Event{Name: "x", Timestamp: ts, ID: "id", Properties: map[string]any{"n": 1, "ok": true}}); err != nil {
+        t.Fatalf("LogEventStruct: %v", err)
+    }
+    if err := l.LogEvent(map[string]any{"event": "y"}); err != nil {
+        t.Fatalf("LogEvent: %v", err)
+    }
+
+    lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+    if len(lines) != 2 {
+        t.Fatalf("expected 2 lines, got %q", buf.String())
+    }
+    var fields map[string]any
+    if err := json.Unmarshal([]byte(lines[0]), &fields); err != nil {
+        t.Fatalf("invalid line %q: %v", lines[0], err)
+    }
+    if fields["event"] != "x" || fields["event_id"] != "id" || fields["timestamp"] != "2024-05-01T12:00:00Z" || fields["n"] != 1.0 || fields["ok"] != true {
+        t.Fatalf("unexpected line %q", lines[0])
+    }
+}
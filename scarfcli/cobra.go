@@ -0,0 +1,25 @@
+package scarfcli
+
+import (
+    "context"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/spf13/cobra"
+    "github.com/spf13/pflag"
+)
+
+// ExecuteCobra executes root with ctx like root.ExecuteContext, then logs
+// the invocation of the command that ran and flushes the logger. It returns
+// the command's error; telemetry failures are never returned.
+func ExecuteCobra(ctx context.Context, logger scarf.EventLogger, root *cobra.Command) error {
+    cmd, err := root.ExecuteContextC(ctx)
+    if cmd == nil {
+        cmd = root
+    }
+    var flags []string
+    cmd.Flags().Visit(func(f *pflag.Flag) {
+        flags = append(flags, f.Name)
+    })
+    _ = LogInvocation(ctx, logger, Invocation{Command: cmd.CommandPath(), Flags: flags, Err: err})
+    return err
+}
@@ -0,0 +1,38 @@
+package scarfcli
+
+import (
+    "context"
+    "errors"
+    "io"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+    "github.com/spf13/cobra"
+)
+
+func TestExecuteCobra(t *testing.T) {
+    boom := errors.New("boom")
+    root := &cobra.Command{Use: "app", SilenceUsage: true, SilenceErrors: true}
+    root.PersistentFlags().Bool("verbose", false, "")
+    deploy := &cobra.Command{Use: "deploy", RunE: func(*cobra.Command, []string) error { return boom }}
+    deploy.Flags().String("env", "", "")
+    deploy.Flags().String("region", "", "")
+    root.AddCommand(deploy)
+    root.SetOut(io.Discard)
+    root.SetArgs([]string{"deploy", "--env", "prod", "--verbose"})
+
+    rec := scarftest.NewRecorder()
+    err := ExecuteCobra(context.Background(), scarf.New("", scarf.WithTransport(rec)), root)
+    if !errors.Is(err, boom) {
+        t.Fatalf("expected the command's error, got %v", err)
+    }
+    events := rec.Events()
+    if len(events) != 1 {
+        t.Fatalf("expected 1 event, got %d", len(events))
+    }
+    p := events[0].Properties
+    if p["command"] != "app deploy" || p["flags"] != "env,verbose" || p["exit_code"] != 1 || p["success"] != false {
+        t.Fatalf("unexpected event %+v", events[0])
+    }
+}
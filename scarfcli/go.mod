@@ -0,0 +1,15 @@
+module github.com/scarf-sh/scarf-go/scarfcli
+
+go 1.25.0
+
+require (
+	github.com/scarf-sh/scarf-go v0.2.0
+	github.com/spf13/cobra v1.10.2
+	github.com/spf13/pflag v1.0.9
+	github.com/urfave/cli/v3 v3.13.0
+)
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
+
+// Build against this checkout; the require above is the release users get.
+replace github.com/scarf-sh/scarf-go => ../
@@ -0,0 +1,80 @@
+// Package scarfcli instruments command-line apps built with cobra or
+// urfave/cli: each run emits a command-invocation event with the command
+// path, the names (never the values) of the flags that were set, and the
+// exit status, and the logger is flushed before the app exits.
+//
+//   func main() {
+//       err := scarfcli.ExecuteCobra(context.Background(), logger, rootCmd)
+//       if err != nil {
+//           os.Exit(1)
+//       }
+//   }
+package scarfcli
+
+import (
+    "context"
+    "errors"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// EventName names the command-invocation events.
+const EventName = "command"
+
+// FlushTimeout bounds how long ExecuteCobra and RunUrfave wait for pending
+// telemetry before returning.
+var FlushTimeout = 2 * time.Second
+
+// Invocation describes a completed command run.
+type Invocation struct {
+    // Command is the command path, e.g. "app deploy".
+    Command string
+    // Flags are the names of the flags that were set.
+    Flags []string
+    // Err is the error the command returned, if any.
+    Err error
+}
+
+// exitCoder is implemented by errors that carry a process exit code, such
+// as urfave/cli's cli.Exit errors.
+type exitCoder interface {
+    ExitCode() int
+}
+
+// ExitCode returns the exit code for inv: 0 on success, the code carried by
+// the error if it has one, and 1 otherwise.
+func (inv Invocation) ExitCode() int {
+    if inv.Err == nil {
+        return 0
+    }
+    var ec exitCoder
+    if errors.As(inv.Err, &ec) {
+        return ec.ExitCode()
+    }
+    return 1
+}
+
+// LogInvocation emits the event for inv, with command, flags (sorted and
+// comma-separated), exit_code and success properties, and flushes the
+// logger within FlushTimeout. Apps on other CLI frameworks can call it
+// directly.
+func LogInvocation(ctx context.Context, logger scarf.EventLogger, inv Invocation) error {
+    if !logger.Enabled() {
+        return nil
+    }
+    flags := append([]string(nil), inv.Flags...)
+    sort.Strings(flags)
+    err := logger.LogEventContext(ctx, map[string]any{
+        "event":     EventName,
+        "command":   inv.Command,
+        "flags":     strings.Join(flags, ","),
+        "exit_code": inv.ExitCode(),
+        "success":   inv.Err == nil,
+    })
+    flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), FlushTimeout)
+    defer cancel()
+    return errors.Join(err, logger.Flush(flushCtx))
+}
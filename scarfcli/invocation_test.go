@@ -0,0 +1,56 @@
+package scarfcli
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+type codeErr int
+
+func (e codeErr) Error() string { return fmt.Sprintf("exit %d", int(e)) }
+func (e codeErr) ExitCode() int { return int(e) }
+
+func TestInvocationExitCode(t *testing.T) {
+    cases := []struct {
+        err  error
+        want int
+    }{
+        {nil, 0},
+        {errors.New("boom"), 1},
+        {fmt.Errorf("wrapped: %w", codeErr(3)), 3},
+    }
+    for _, c := range cases {
+        if got := (Invocation{Err: c.err}).ExitCode(); got != c.want {
+            t.Errorf("ExitCode(%v) = %d, want %d", c.err, got, c.want)
+        }
+    }
+}
+
+func TestLogInvocation(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    logger := scarf.New("", scarf.WithTransport(rec))
+    inv := Invocation{Command: "app deploy", Flags: []string{"verbose", "env"}, Err: codeErr(2)}
+    if err := LogInvocation(context.Background(), logger, inv); err != nil {
+        t.Fatalf("LogInvocation: %v", err)
+    }
+    events := rec.Events()
+    if len(events) != 1 {
+        t.Fatalf("expected 1 event, got %d", len(events))
+    }
+    p := events[0].Properties
+    if events[0].Name != EventName || p["command"] != "app deploy" || p["flags"] != "env,verbose" || p["exit_code"] != 2 || p["success"] != false {
+        t.Fatalf("unexpected event %+v", events[0])
+    }
+    if inv.Flags[0] != "verbose" {
+        t.Fatalf("caller's flags must not be reordered")
+    }
+
+    if err := LogInvocation(context.Background(), scarf.NoopLogger{}, inv); err != nil {
+        t.Fatalf("expected nil for a disabled logger, got %v", err)
+    }
+}
@@ -0,0 +1,79 @@
+package scarfcli
+
+import (
+    "context"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/urfave/cli/v3"
+)
+
+// RunUrfave runs cmd with args like cmd.Run, then logs the invocation of the
+// (sub)command that ran and flushes the logger. It returns the command's
+// error; telemetry failures are never returned.
+//
+// Commands that fail with a cli.ExitCoder still exit the process through
+// cmd.ExitErrHandler (or cli.HandleExitCoder), but only after the event has
+// been sent.
+func RunUrfave(ctx context.Context, logger scarf.EventLogger, cmd *cli.Command, args []string) error {
+    ran := cmd
+    defer wrapBefore(cmd, &ran)()
+
+    var exitErr error
+    handler := cmd.ExitErrHandler
+    cmd.ExitErrHandler = func(_ context.Context, _ *cli.Command, err error) {
+        exitErr = err
+    }
+    err := cmd.Run(ctx, args)
+    cmd.ExitErrHandler = handler
+
+    _ = LogInvocation(ctx, logger, Invocation{Command: ran.FullName(), Flags: urfaveFlags(ran), Err: err})
+
+    if exitErr != nil {
+        if handler != nil {
+            handler(ctx, cmd, exitErr)
+        } else {
+            cli.HandleExitCoder(exitErr)
+        }
+    }
+    return err
+}
+
+// wrapBefore records in ran the innermost command whose Before hook runs,
+// which is the command being executed, until the returned func restores the
+// original hooks.
+func wrapBefore(cmd *cli.Command, ran **cli.Command) (restore func()) {
+    before := cmd.Before
+    cmd.Before = func(ctx context.Context, c *cli.Command) (context.Context, error) {
+        *ran = c
+        if before != nil {
+            return before(ctx, c)
+        }
+        return ctx, nil
+    }
+    restores := make([]func(), 0, len(cmd.Commands))
+    for _, sub := range cmd.Commands {
+        restores = append(restores, wrapBefore(sub, ran))
+    }
+    return func() {
+        cmd.Before = before
+        for _, r := range restores {
+            r()
+        }
+    }
+}
+
+// urfaveFlags returns the primary names of the flags set on cmd or its
+// ancestors.
+func urfaveFlags(cmd *cli.Command) []string {
+    var names []string
+    seen := map[string]bool{}
+    for _, c := range cmd.Lineage() {
+        for _, f := range c.Flags {
+            if n := f.Names(); f.IsSet() && len(n) > 0 && !seen[n[0]] {
+                seen[n[0]] = true
+                names = append(names, n[0])
+            }
+        }
+    }
+    return names
+}
@@ -0,0 +1,60 @@
+package scarfcli
+
+import (
+    "context"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+    "github.com/urfave/cli/v3"
+)
+
+func TestRunUrfave(t *testing.T) {
+    var before int
+    cmd := &cli.Command{
+        Name:  "app",
+        Flags: []cli.Flag{&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}}},
+        Commands: []*cli.Command{{
+            Name:   "deploy",
+            Flags:  []cli.Flag{&cli.StringFlag{Name: "env"}, &cli.StringFlag{Name: "region"}},
+            Before: func(ctx context.Context, _ *cli.Command) (context.Context, error) { before++; return ctx, nil },
+            Action: func(context.Context, *cli.Command) error { return nil },
+        }},
+    }
+
+    rec := scarftest.NewRecorder()
+    logger := scarf.New("", scarf.WithTransport(rec))
+    if err := RunUrfave(context.Background(), logger, cmd, []string{"app", "-v", "deploy", "--env", "prod"}); err != nil {
+        t.Fatalf("RunUrfave: %v", err)
+    }
+    events := rec.Events()
+    if len(events) != 1 {
+        t.Fatalf("expected 1 event, got %d", len(events))
+    }
+    p := events[0].Properties
+    if p["command"] != "app deploy" || p["flags"] != "env,verbose" || p["exit_code"] != 0 || p["success"] != true {
+        t.Fatalf("unexpected event %+v", events[0])
+    }
+    if before != 1 || cmd.Before != nil {
+        t.Fatalf("expected the app's hooks to run once and be restored (ran %d)", before)
+    }
+}
+
+func TestRunUrfave_ExitCoder(t *testing.T) {
+    var exited int
+    cmd := &cli.Command{
+        Name:           "app",
+        Action:         func(context.Context, *cli.Command) error { return cli.Exit("", 4) },
+        ExitErrHandler: func(_ context.Context, _ *cli.Command, err error) { exited = err.(cli.ExitCoder).ExitCode() },
+    }
+    rec := scarftest.NewRecorder()
+    RunUrfave(context.Background(), scarf.New("", scarf.WithTransport(rec)), cmd, []string{"app"})
+
+    events := rec.Events()
+    if len(events) != 1 || events[0].Properties["exit_code"] != 4 {
+        t.Fatalf("unexpected events %+v", events)
+    }
+    if exited != 4 {
+        t.Fatalf("expected the app's exit handler to run after logging, got %d", exited)
+    }
+}
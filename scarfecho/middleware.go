@@ -0,0 +1,57 @@
+// Package scarfecho reports route-level API usage from Echo servers through
+// a scarf logger:
+//
+//   e.Use(scarfecho.Middleware(logger, scarfhttp.Options{}))
+//
+// Requests are aggregated into events like those of scarfhttp.Middleware,
+// with the matched route template (e.g. "/users/:id") as the route.
+package scarfecho
+
+import (
+    "time"
+
+    "github.com/labstack/echo/v4"
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarfhttp"
+)
+
+// UnmatchedRoute is reported for requests that matched no route.
+const UnmatchedRoute = "unmatched"
+
+// Middleware returns Echo middleware that counts every request after it has
+// been served with a scarfhttp.Aggregator, which sends one event per method,
+// route, status class and latency bucket every opts.FlushInterval. Counts
+// still pending when the process exits are lost. opts.Route, if set,
+// overrides the route template.
+//
+// Handler errors are passed to the Echo error handler before the status is
+// read, as Echo's own logger middleware does, so the event reflects the
+// response actually sent.
+func Middleware(logger scarf.EventLogger, opts scarfhttp.Options) echo.MiddlewareFunc {
+    agg := scarfhttp.NewAggregator(logger, opts)
+    return func(next echo.HandlerFunc) echo.HandlerFunc {
+        return func(c echo.Context) error {
+            if !logger.Enabled() {
+                return next(c)
+            }
+            start := time.Now()
+            err := next(c)
+            if err != nil {
+                c.Error(err)
+            }
+            latency := time.Since(start)
+
+            req := c.Request()
+            route := c.Path()
+            if opts.Route != nil {
+                route = opts.Route(req)
+            } else if route == "" {
+                route = UnmatchedRoute
+            }
+            agg.Record(req.Method, route, c.Response().Status, latency)
+            // The error has been handled; returning it would write a second
+            // response.
+            return nil
+        }
+    }
+}
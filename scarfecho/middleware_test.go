@@ -0,0 +1,52 @@
+package scarfecho
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/labstack/echo/v4"
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarfhttp"
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+func TestMiddleware(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    e := echo.New()
+    e.Use(Middleware(scarf.New("", scarf.WithTransport(rec)), scarfhttp.Options{FlushInterval: 10 * time.Millisecond}))
+    e.GET("/users/:id", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+    e.GET("/fail", func(c echo.Context) error { return echo.NewHTTPError(http.StatusBadGateway) })
+
+    var codes []int
+    for _, path := range []string{"/users/42", "/fail", "/nope"} {
+        resp := httptest.NewRecorder()
+        e.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+        codes = append(codes, resp.Code)
+    }
+    if codes[1] != http.StatusBadGateway || codes[2] != http.StatusNotFound {
+        t.Fatalf("responses must be unaffected, got %v", codes)
+    }
+
+    events := rec.WaitForEvents(t, 3)
+    if len(events) != 3 {
+        t.Fatalf("expected 3 events, got %d", len(events))
+    }
+    byRoute := map[any]map[string]any{}
+    for _, ev := range events {
+        if ev.Name != scarfhttp.DefaultEventName {
+            t.Fatalf("unexpected event name %q", ev.Name)
+        }
+        byRoute[ev.Properties["route"]] = ev.Properties
+    }
+    if p := byRoute["/users/:id"]; p["status_class"] != "2xx" || p["method"] != "GET" || p["latency_bucket"] == nil {
+        t.Fatalf("unexpected properties %v", byRoute)
+    }
+    if p := byRoute["/fail"]; p["status_class"] != "5xx" {
+        t.Fatalf("unexpected properties %v", byRoute)
+    }
+    if p := byRoute[UnmatchedRoute]; p["status_class"] != "4xx" {
+        t.Fatalf("unexpected properties %v", byRoute)
+    }
+}
@@ -0,0 +1,45 @@
+// Package scarfgin reports route-level API usage from Gin servers through a
+// scarf logger:
+//
+//   router.Use(scarfgin.Middleware(logger, scarfhttp.Options{}))
+//
+// Requests are aggregated into events like those of scarfhttp.Middleware,
+// with the matched route template (e.g. "/users/:id") as the route.
+package scarfgin
+
+import (
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarfhttp"
+)
+
+// UnmatchedRoute is reported for requests that matched no route.
+const UnmatchedRoute = "unmatched"
+
+// Middleware returns Gin middleware that counts every request after it has
+// been served with a scarfhttp.Aggregator, which sends one event per method,
+// route, status class and latency bucket every opts.FlushInterval. Counts
+// still pending when the process exits are lost. opts.Route, if set,
+// overrides the route template.
+func Middleware(logger scarf.EventLogger, opts scarfhttp.Options) gin.HandlerFunc {
+    agg := scarfhttp.NewAggregator(logger, opts)
+    return func(c *gin.Context) {
+        if !logger.Enabled() {
+            c.Next()
+            return
+        }
+        start := time.Now()
+        c.Next()
+        latency := time.Since(start)
+
+        route := c.FullPath()
+        if opts.Route != nil {
+            route = opts.Route(c.Request)
+        } else if route == "" {
+            route = UnmatchedRoute
+        }
+        agg.Record(c.Request.Method, route, c.Writer.Status(), latency)
+    }
+}
@@ -0,0 +1,43 @@
+package scarfgin
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarfhttp"
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+func TestMiddleware(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    rec := scarftest.NewRecorder()
+    router := gin.New()
+    router.Use(Middleware(scarf.New("", scarf.WithTransport(rec)), scarfhttp.Options{FlushInterval: 10 * time.Millisecond}))
+    router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+    for _, path := range []string{"/users/42", "/nope"} {
+        router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+    }
+
+    events := rec.WaitForEvents(t, 2)
+    if len(events) != 2 {
+        t.Fatalf("expected 2 events, got %d", len(events))
+    }
+    byRoute := map[any]map[string]any{}
+    for _, ev := range events {
+        if ev.Name != scarfhttp.DefaultEventName {
+            t.Fatalf("unexpected event name %q", ev.Name)
+        }
+        byRoute[ev.Properties["route"]] = ev.Properties
+    }
+    if p := byRoute["/users/:id"]; p["status_class"] != "2xx" || p["method"] != "GET" || p["latency_bucket"] == nil {
+        t.Fatalf("unexpected properties %v", p)
+    }
+    if p := byRoute[UnmatchedRoute]; p["status_class"] != "4xx" {
+        t.Fatalf("unexpected properties %v", p)
+    }
+}
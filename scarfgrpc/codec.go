@@ -0,0 +1,45 @@
+package scarfgrpc
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+    "google.golang.org/grpc/mem"
+)
+
+// errUnsupportedMessage is returned by codec for types it doesn't handle.
+var errUnsupportedMessage = errors.New("scarf: unsupported grpc message")
+
+// codec marshals the scarf/pb messages without the protobuf runtime. It is
+// named "proto", so requests carry the standard application/grpc+proto
+// content type and any protobuf-based server can decode them.
+type codec struct{}
+
+func (codec) Name() string { return "proto" }
+
+func (codec) Marshal(v any) (mem.BufferSlice, error) {
+    switch m := v.(type) {
+    case *pb.Event:
+        return mem.BufferSlice{mem.SliceBuffer(m.Marshal())}, nil
+    case *pb.Batch:
+        return mem.BufferSlice{mem.SliceBuffer(m.Marshal())}, nil
+    case *sendResponse:
+        return nil, nil
+    }
+    return nil, fmt.Errorf("%w: %T", errUnsupportedMessage, v)
+}
+
+func (codec) Unmarshal(data mem.BufferSlice, v any) error {
+    b := data.Materialize()
+    switch m := v.(type) {
+    case *pb.Event:
+        return m.Unmarshal(b)
+    case *pb.Batch:
+        return m.Unmarshal(b)
+    case *sendResponse:
+        // Unknown fields are ignored, so newer collectors may add some.
+        return nil
+    }
+    return fmt.Errorf("%w: %T", errUnsupportedMessage, v)
+}
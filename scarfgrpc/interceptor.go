@@ -0,0 +1,65 @@
+package scarfgrpc
+
+import (
+    "context"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/status"
+)
+
+// DefaultEventName names the per-call usage events emitted by the server
+// interceptors.
+const DefaultEventName = "grpc_request"
+
+// UnaryServerInterceptor returns an interceptor that emits a usage event for
+// every unary call, with its full method name ("/pkg.Service/Method"),
+// status code ("OK", "NotFound", ...) and latency bucket:
+//
+//   srv := grpc.NewServer(
+//       grpc.ChainUnaryInterceptor(scarfgrpc.UnaryServerInterceptor(logger)),
+//       grpc.ChainStreamInterceptor(scarfgrpc.StreamServerInterceptor(logger)),
+//   )
+//
+// Events are sent in the background with scarf.LogAsync once the call
+// completes, so Flush and Close wait for them, and go through the logger's
+// pipeline, so its sampling rate bounds the volume.
+func UnaryServerInterceptor(logger scarf.EventLogger) grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+        if !logger.Enabled() {
+            return handler(ctx, req)
+        }
+        start := time.Now()
+        resp, err := handler(ctx, req)
+        logCall(ctx, logger, info.FullMethod, "unary", err, time.Since(start))
+        return resp, err
+    }
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor; latency covers the whole stream.
+func StreamServerInterceptor(logger scarf.EventLogger) grpc.StreamServerInterceptor {
+    return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        if !logger.Enabled() {
+            return handler(srv, ss)
+        }
+        start := time.Now()
+        err := handler(srv, ss)
+        logCall(ss.Context(), logger, info.FullMethod, "stream", err, time.Since(start))
+        return err
+    }
+}
+
+// logCall sends the usage event for a call in the background, with a context
+// detached from the call's cancellation.
+func logCall(ctx context.Context, logger scarf.EventLogger, method, kind string, err error, latency time.Duration) {
+    props := map[string]any{
+        "event":          DefaultEventName,
+        "method":         method,
+        "type":           kind,
+        "code":           status.Code(err).String(),
+        "latency_bucket": scarf.LatencyBucket(latency),
+    }
+    scarf.LogAsync(context.WithoutCancel(ctx), logger, props)
+}
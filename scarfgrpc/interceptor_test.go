@@ -0,0 +1,69 @@
+package scarfgrpc
+
+import (
+    "context"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    intercept := UnaryServerInterceptor(scarf.New("", scarf.WithTransport(rec)))
+    info := &grpc.UnaryServerInfo{FullMethod: "/scarf.v1.Collector/Send"}
+
+    resp, err := intercept(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+        return "resp", nil
+    })
+    if resp != "resp" || err != nil {
+        t.Fatalf("expected the handler's result, got %v, %v", resp, err)
+    }
+    _, err = intercept(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+        return nil, status.Error(codes.NotFound, "nope")
+    })
+    if status.Code(err) != codes.NotFound {
+        t.Fatalf("expected the handler's error, got %v", err)
+    }
+
+    events := rec.WaitForEvents(t, 2)
+    if len(events) != 2 {
+        t.Fatalf("expected 2 events, got %d", len(events))
+    }
+    codesSeen := map[any]bool{}
+    for _, ev := range events {
+        p := ev.Properties
+        if ev.Name != DefaultEventName || p["method"] != info.FullMethod || p["type"] != "unary" || p["latency_bucket"] != "<10ms" {
+            t.Fatalf("unexpected event %+v", ev)
+        }
+        codesSeen[p["code"]] = true
+    }
+    if !codesSeen["OK"] || !codesSeen["NotFound"] {
+        t.Fatalf("expected OK and NotFound codes, got %v", codesSeen)
+    }
+}
+
+type testStream struct {
+    grpc.ServerStream
+}
+
+func (testStream) Context() context.Context { return context.Background() }
+
+func TestStreamServerInterceptor(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    intercept := StreamServerInterceptor(scarf.New("", scarf.WithTransport(rec)))
+    info := &grpc.StreamServerInfo{FullMethod: "/pkg.Svc/Watch"}
+    err := intercept(nil, testStream{}, info, func(any, grpc.ServerStream) error {
+        return status.Error(codes.Canceled, "bye")
+    })
+    if status.Code(err) != codes.Canceled {
+        t.Fatalf("expected the handler's error, got %v", err)
+    }
+    events := rec.WaitForEvents(t, 1)
+    if len(events) != 1 || events[0].Properties["code"] != "Canceled" || events[0].Properties["type"] != "stream" || events[0].Properties["method"] != info.FullMethod {
+        t.Fatalf("unexpected events %+v", events)
+    }
+}
@@ -0,0 +1,120 @@
+// Package scarfgrpc integrates scarf with gRPC: a scarf.Transport that
+// delivers events to a self-hosted collector using the Collector service in
+// collector.proto, and server interceptors that report per-method usage.
+//
+//   t, err := scarfgrpc.New("collector.internal:443")
+//   if err != nil { ... }
+//   defer t.Close()
+//   logger := scarf.New("", scarf.WithTransport(t))
+package scarfgrpc
+
+import (
+    "context"
+    "crypto/tls"
+    "fmt"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials"
+    "google.golang.org/grpc/credentials/insecure"
+)
+
+// SendMethod is the full method name of Collector.Send.
+const SendMethod = "/scarf.v1.Collector/Send"
+
+// Transport sends each event as a Collector.Send call. The underlying
+// connection is shared by all calls, and each call is bounded by the
+// deadline of the context the logger passes to Send (the logger's timeout).
+type Transport struct {
+    conn  *grpc.ClientConn
+    owned bool
+}
+
+// Option configures a Transport created with New.
+type Option func(*options)
+
+type options struct {
+    creds    credentials.TransportCredentials
+    dialOpts []grpc.DialOption
+}
+
+// WithTLSConfig sets the TLS configuration, e.g. for a private CA or client
+// certificates. By default connections use TLS with the system roots.
+func WithTLSConfig(cfg *tls.Config) Option {
+    return func(o *options) {
+        o.creds = credentials.NewTLS(cfg)
+    }
+}
+
+// WithInsecure disables transport security, for collectors reachable only
+// on a trusted network.
+func WithInsecure() Option {
+    return func(o *options) {
+        o.creds = insecure.NewCredentials()
+    }
+}
+
+// WithDialOptions passes additional options to grpc.NewClient.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+    return func(o *options) {
+        o.dialOpts = append(o.dialOpts, opts...)
+    }
+}
+
+// New returns a Transport for the collector at target, in gRPC target
+// syntax (e.g. "collector.internal:443" or "dns:///collector:443"). The
+// connection is established lazily on the first send.
+func New(target string, opts ...Option) (*Transport, error) {
+    o := options{creds: credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})}
+    for _, opt := range opts {
+        opt(&o)
+    }
+    dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(o.creds)}, o.dialOpts...)
+    conn, err := grpc.NewClient(target, dialOpts...)
+    if err != nil {
+        return nil, fmt.Errorf("scarf: grpc client: %w", err)
+    }
+    return &Transport{conn: conn, owned: true}, nil
+}
+
+// NewFromConn returns a Transport using an existing connection, which the
+// caller remains responsible for closing.
+func NewFromConn(conn *grpc.ClientConn) *Transport {
+    return &Transport{conn: conn}
+}
+
+// Send delivers ev.
+func (t *Transport) Send(ctx context.Context, ev scarf.Event) error {
+    msg := eventMessage(ev)
+    if err := t.conn.Invoke(ctx, SendMethod, &msg, &sendResponse{}, grpc.ForceCodecV2(codec{})); err != nil {
+        return fmt.Errorf("scarf: grpc send: %w", err)
+    }
+    return nil
+}
+
+// Close closes the connection if the Transport created it.
+func (t *Transport) Close() error {
+    if !t.owned {
+        return nil
+    }
+    return t.conn.Close()
+}
+
+// eventMessage converts ev to its wire form.
+func eventMessage(ev scarf.Event) pb.Event {
+    msg := pb.Event{Name: ev.Name, ID: ev.ID}
+    if !ev.Timestamp.IsZero() {
+        msg.TimestampUnixNano = ev.Timestamp.UnixNano()
+    }
+    if len(ev.Properties) > 0 {
+        msg.Properties = make(map[string]pb.Value, len(ev.Properties))
+        for k, v := range ev.Properties {
+            msg.Properties[k] = pb.ValueOf(scarf.CanonicalValue(v))
+        }
+    }
+    return msg
+}
+
+// sendResponse is the empty SendResponse message.
+type sendResponse struct{}
@@ -0,0 +1,113 @@
+package scarfgrpc
+
+import (
+    "context"
+    "errors"
+    "net"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// collector serves Collector.Send, recording events and their deadlines.
+type collector struct {
+    events    chan pb.Event
+    deadlines chan time.Time
+    fail      bool
+}
+
+func startCollector(t *testing.T, c *collector) string {
+    lis, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("Listen: %v", err)
+    }
+    srv := grpc.NewServer(grpc.ForceServerCodecV2(codec{}))
+    srv.RegisterService(&grpc.ServiceDesc{
+        ServiceName: "scarf.v1.Collector",
+        HandlerType: (*any)(nil),
+        Methods: []grpc.MethodDesc{{
+            MethodName: "Send",
+            Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+                var ev pb.Event
+                if err := dec(&ev); err != nil {
+                    return nil, err
+                }
+                if c.fail {
+                    return nil, status.Error(codes.Unavailable, "down")
+                }
+                d, _ := ctx.Deadline()
+                c.events <- ev
+                c.deadlines <- d
+                return &sendResponse{}, nil
+            },
+        }},
+    }, nil)
+    go srv.Serve(lis)
+    t.Cleanup(srv.Stop)
+    return lis.Addr().String()
+}
+
+func TestTransport(t *testing.T) {
+    c := &collector{events: make(chan pb.Event, 2), deadlines: make(chan time.Time, 2)}
+    tr, err := New(startCollector(t, c), WithInsecure())
+    if err != nil {
+        t.Fatalf("New: %v", err)
+    }
+    defer tr.Close()
+
+    l := scarf.New("", scarf.WithTransport(tr), scarf.WithTimeout(5*time.Second))
+    for i := 0; i < 2; i++ {
+        if err := l.LogEvent(map[string]any{"event": "x", "n": i}); err != nil {
+            t.Fatalf("LogEvent: %v", err)
+        }
+    }
+    for i := 0; i < 2; i++ {
+        ev := <-c.events
+        if ev.Name != "x" || ev.ID == "" || ev.TimestampUnixNano == 0 || ev.Properties["n"] != (pb.Value{Kind: pb.KindInt, Int: int64(i)}) {
+            t.Fatalf("unexpected event: %+v", ev)
+        }
+        if d := <-c.deadlines; d.IsZero() || time.Until(d) > 5*time.Second {
+            t.Fatalf("expected the logger timeout as deadline, got %v", d)
+        }
+    }
+}
+
+func TestTransport_Error(t *testing.T) {
+    c := &collector{fail: true}
+    tr, err := New(startCollector(t, c), WithInsecure())
+    if err != nil {
+        t.Fatalf("New: %v", err)
+    }
+    defer tr.Close()
+
+    err = scarf.New("", scarf.WithTransport(tr)).LogEvent(map[string]any{"event": "x"})
+    if status.Code(err) != codes.Unavailable {
+        t.Fatalf("expected Unavailable, got %v", err)
+    }
+}
+
+func TestEventMessage_CanonicalValues(t *testing.T) {
+    ts := time.Date(2024, 5, 1, 12, 0, 0, 500, time.FixedZone("CEST", 2*3600))
+    msg := eventMessage(scarf.Event{Name: "x", Properties: map[string]any{
+        "err":      errors.New("boom"),
+        "duration": 1500 * time.Millisecond,
+        "at":       ts,
+        "raw":      []byte("hi"),
+    }})
+    want := map[string]pb.Value{
+        "err":      {Kind: pb.KindString, String: "boom"},
+        "duration": {Kind: pb.KindInt, Int: 1500},
+        "at":       {Kind: pb.KindString, String: "2024-05-01T10:00:00.0000005Z"},
+        "raw":      {Kind: pb.KindString, String: "aGk="},
+    }
+    for k, w := range want {
+        if got := msg.Properties[k]; got != w {
+            t.Errorf("%s = %+v, want %+v", k, got, w)
+        }
+    }
+}
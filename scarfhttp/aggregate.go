@@ -0,0 +1,118 @@
+package scarfhttp
+
+import (
+    "context"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// DefaultFlushInterval is used when Options.FlushInterval is zero or less.
+const DefaultFlushInterval = time.Minute
+
+// Aggregator counts served requests per method, route, status class and
+// latency bucket, and sends one event per combination, with the number of
+// requests as "count", every Options.FlushInterval, so a busy server sends
+// a bounded number of events however many requests it serves. Nothing is
+// scheduled while no requests are pending. It is safe for concurrent use.
+type Aggregator struct {
+    logger   scarf.EventLogger
+    opts     Options
+    interval time.Duration
+
+    mu     sync.Mutex
+    counts map[bucket]int64
+    timer  *time.Timer // armed while counts are pending
+}
+
+// bucket is the combination of properties requests are counted by.
+type bucket struct {
+    method, route, statusClass, latencyBucket string
+}
+
+// NewAggregator returns an Aggregator sending events through logger.
+// opts.Route is only used by its Middleware; Record takes the route.
+func NewAggregator(logger scarf.EventLogger, opts Options) *Aggregator {
+    interval := opts.FlushInterval
+    if interval <= 0 {
+        interval = DefaultFlushInterval
+    }
+    return &Aggregator{logger: logger, opts: opts, interval: interval}
+}
+
+// Record counts a served request. It does nothing while the logger is
+// disabled.
+func (a *Aggregator) Record(method, route string, status int, latency time.Duration) {
+    if !a.logger.Enabled() {
+        return
+    }
+    b := bucket{method, route, scarf.StatusClass(status), scarf.LatencyBucket(latency)}
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.counts == nil {
+        a.counts = make(map[bucket]int64)
+    }
+    a.counts[b]++
+    if a.timer == nil {
+        a.timer = time.AfterFunc(a.interval, func() { a.Flush(context.Background()) })
+    }
+}
+
+// Flush sends the pending counts right away, e.g. before the logger is
+// closed at shutdown. Events are sent in the background with scarf.LogAsync
+// using ctx, so the logger's Flush and Close wait for them.
+func (a *Aggregator) Flush(ctx context.Context) {
+    a.mu.Lock()
+    counts := a.counts
+    a.counts = nil
+    if a.timer != nil {
+        a.timer.Stop()
+        a.timer = nil
+    }
+    a.mu.Unlock()
+
+    buckets := make([]bucket, 0, len(counts))
+    for b := range counts {
+        buckets = append(buckets, b)
+    }
+    sort.Slice(buckets, func(i, j int) bool {
+        bi, bj := buckets[i], buckets[j]
+        if bi.route != bj.route {
+            return bi.route < bj.route
+        }
+        if bi.method != bj.method {
+            return bi.method < bj.method
+        }
+        if bi.statusClass != bj.statusClass {
+            return bi.statusClass < bj.statusClass
+        }
+        return bi.latencyBucket < bj.latencyBucket
+    })
+    for _, b := range buckets {
+        props := event(a.opts, b.method, b.route, b.statusClass, b.latencyBucket)
+        props["count"] = counts[b]
+        scarf.LogAsync(ctx, a.logger, props)
+    }
+}
+
+// Middleware records every request with a after it has been served, taking
+// the route from opts.Route, or the URL path if that is unset.
+func (a *Aggregator) Middleware(next http.Handler) http.Handler {
+    route := a.opts.Route
+    if route == nil {
+        route = func(r *http.Request) string { return r.URL.Path }
+    }
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !a.logger.Enabled() {
+            next.ServeHTTP(w, r)
+            return
+        }
+        rec := &statusRecorder{ResponseWriter: w}
+        start := time.Now()
+        next.ServeHTTP(rec, r)
+        a.Record(r.Method, route(r), rec.status(), time.Since(start))
+    })
+}
@@ -0,0 +1,129 @@
+// Package scarfhttp reports API usage from net/http servers through a scarf
+// logger:
+//
+//   handler = scarfhttp.Middleware(logger, scarfhttp.Options{})(handler)
+//
+// Requests are counted per method, route, status class and latency bucket,
+// and each combination is sent as one event with a "count" every flush
+// interval, so the number of events doesn't grow with traffic.
+package scarfhttp
+
+import (
+    "bufio"
+    "errors"
+    "net"
+    "net/http"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// DefaultEventName is the event name used when Options.EventName is empty.
+const DefaultEventName = "http_request"
+
+// Options configures Middleware. The zero value is ready to use.
+type Options struct {
+    // EventName names the emitted events. Defaults to DefaultEventName.
+    EventName string
+    // Route returns the route reported for a request, e.g. a router's
+    // pattern such as "/users/{id}". Defaults to the URL path, which
+    // should be replaced for servers with parameterized paths to keep the
+    // number of distinct routes small.
+    Route func(*http.Request) string
+    // Properties are added to every event.
+    Properties map[string]any
+    // FlushInterval is how often aggregated counts are sent. Defaults to
+    // DefaultFlushInterval.
+    FlushInterval time.Duration
+}
+
+// Middleware returns middleware that counts every request after it has been
+// served and sends the counts as described at Aggregator. Counts still
+// pending when the process exits are lost; to send them at shutdown, use
+// NewAggregator and call its Flush before closing the logger.
+func Middleware(logger scarf.EventLogger, opts Options) func(http.Handler) http.Handler {
+    return NewAggregator(logger, opts).Middleware
+}
+
+// Event returns the properties of the usage event for a served request. It
+// is exported for framework adapters that send an event per request; those
+// in the scarfgin and scarfecho modules aggregate with an Aggregator
+// instead. opts.Route is not consulted.
+func Event(opts Options, method, route string, status int, latency time.Duration) map[string]any {
+    return event(opts, method, route, scarf.StatusClass(status), scarf.LatencyBucket(latency))
+}
+
+// event returns the properties of a usage event for requests in the given
+// status class and latency bucket.
+func event(opts Options, method, route, statusClass, latencyBucket string) map[string]any {
+    name := opts.EventName
+    if name == "" {
+        name = DefaultEventName
+    }
+    props := make(map[string]any, len(opts.Properties)+6)
+    for k, v := range opts.Properties {
+        props[k] = v
+    }
+    props["event"] = name
+    props["method"] = method
+    props["route"] = route
+    props["status_class"] = statusClass
+    props["latency_bucket"] = latencyBucket
+    return props
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+    http.ResponseWriter
+    code int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+    if w.code == 0 {
+        w.code = code
+    }
+    w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+    if w.code == 0 {
+        w.code = http.StatusOK
+    }
+    return w.ResponseWriter.Write(b)
+}
+
+// status returns the response status; handlers that write nothing send 200.
+func (w *statusRecorder) status() int {
+    if w.code == 0 {
+        return http.StatusOK
+    }
+    return w.code
+}
+
+// Flush implements http.Flusher for handlers that stream responses.
+func (w *statusRecorder) Flush() {
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        if w.code == 0 {
+            w.code = http.StatusOK
+        }
+        f.Flush()
+    }
+}
+
+// Hijack implements http.Hijacker for handlers that take over the
+// connection, e.g. for WebSockets.
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    h, ok := w.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, errors.New("scarf: response writer does not support hijacking")
+    }
+    if w.code == 0 {
+        w.code = http.StatusSwitchingProtocols
+    }
+    return h.Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+    return w.ResponseWriter
+}
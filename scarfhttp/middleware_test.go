@@ -0,0 +1,154 @@
+package scarfhttp
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+func TestMiddleware(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    logger := scarf.New("", scarf.WithTransport(rec))
+    mux := http.NewServeMux()
+    mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+    mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) })
+    h := Middleware(logger, Options{Properties: map[string]any{"service": "api"}, FlushInterval: 10 * time.Millisecond})(mux)
+
+    for _, path := range []string{"/ok", "/missing"} {
+        resp := httptest.NewRecorder()
+        h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+    }
+
+    events := rec.WaitForEvents(t, 2)
+    if len(events) != 2 {
+        t.Fatalf("expected 2 events, got %d", len(events))
+    }
+    byRoute := map[string]map[string]any{}
+    for _, ev := range events {
+        if ev.Name != DefaultEventName {
+            t.Fatalf("unexpected event name %q", ev.Name)
+        }
+        byRoute[ev.Properties["route"].(string)] = ev.Properties
+    }
+    if p := byRoute["/ok"]; p["status_class"] != "2xx" || p["method"] != "GET" || p["latency_bucket"] != "<10ms" || p["service"] != "api" || p["count"] != int64(1) {
+        t.Fatalf("unexpected properties %v", p)
+    }
+    if p := byRoute["/missing"]; p["status_class"] != "4xx" {
+        t.Fatalf("unexpected properties %v", p)
+    }
+}
+
+func TestMiddleware_Options(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    logger := scarf.New("", scarf.WithTransport(rec))
+    h := Middleware(logger, Options{
+        EventName:     "api_call",
+        Route:         func(*http.Request) string { return "/users/{id}" },
+        FlushInterval: 10 * time.Millisecond,
+    })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusInternalServerError)
+    }))
+    h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/42", nil))
+
+    events := rec.WaitForEvents(t, 1)
+    if len(events) != 1 || events[0].Name != "api_call" || events[0].Properties["route"] != "/users/{id}" || events[0].Properties["status_class"] != "5xx" {
+        t.Fatalf("unexpected events %+v", events)
+    }
+}
+
+func TestMiddleware_Disabled(t *testing.T) {
+    h := Middleware(scarf.NoopLogger{}, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusTeapot)
+    }))
+    resp := httptest.NewRecorder()
+    h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+    if resp.Code != http.StatusTeapot {
+        t.Fatalf("expected the handler to run untouched, got %d", resp.Code)
+    }
+}
+
+func TestAggregator(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    logger := scarf.New("", scarf.WithTransport(rec))
+    agg := NewAggregator(logger, Options{FlushInterval: time.Hour})
+    h := agg.Middleware(http.NotFoundHandler())
+    for i := 0; i < 3; i++ {
+        h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+    }
+    h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/a", nil))
+    agg.Record(http.MethodGet, "/a", http.StatusOK, time.Millisecond)
+    if n := len(rec.Events()); n != 0 {
+        t.Fatalf("expected nothing sent before the flush, got %d events", n)
+    }
+
+    agg.Flush(context.Background())
+    if err := logger.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    events := rec.Events()
+    if len(events) != 3 {
+        t.Fatalf("expected one event per bucket, got %+v", events)
+    }
+    counts := map[string]any{}
+    for _, ev := range events {
+        p := ev.Properties
+        if p["route"] != "/a" {
+            t.Fatalf("unexpected route in %v", p)
+        }
+        counts[p["method"].(string)+" "+p["status_class"].(string)] = p["count"]
+    }
+    want := map[string]any{"GET 2xx": int64(1), "GET 4xx": int64(3), "POST 4xx": int64(1)}
+    for k, w := range want {
+        if counts[k] != w {
+            t.Errorf("%s: expected count %v, got %v", k, w, counts[k])
+        }
+    }
+
+    agg.Flush(context.Background())
+    if err := logger.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if n := len(rec.Events()); n != 3 {
+        t.Fatalf("expected an empty flush to send nothing, got %d events", n)
+    }
+}
+
+func TestAggregator_Interval(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    agg := NewAggregator(scarf.New("", scarf.WithTransport(rec)), Options{FlushInterval: 10 * time.Millisecond})
+    for i := 0; i < 5; i++ {
+        agg.Record(http.MethodGet, "/a", http.StatusOK, time.Millisecond)
+    }
+    if events := rec.WaitForEvents(t, 1); events[0].Properties["count"] != int64(5) {
+        t.Fatalf("expected the interval flush to send the count, got %+v", events)
+    }
+
+    agg.Record(http.MethodGet, "/a", http.StatusOK, time.Millisecond)
+    if events := rec.WaitForEvents(t, 2); events[1].Properties["count"] != int64(1) {
+        t.Fatalf("expected a new interval after later requests, got %+v", events)
+    }
+}
+
+func TestMiddleware_FlushWaits(t *testing.T) {
+    rec := scarftest.NewRecorder()
+    slow := scarf.TransportFunc(func(ctx context.Context, ev scarf.Event) error {
+        time.Sleep(50 * time.Millisecond)
+        return rec.Send(ctx, ev)
+    })
+    logger := scarf.New("", scarf.WithTransport(slow))
+    agg := NewAggregator(logger, Options{})
+    agg.Middleware(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+    agg.Flush(context.Background())
+    if err := logger.Flush(context.Background()); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if n := len(rec.Events()); n != 1 {
+        t.Fatalf("expected Flush to wait for the aggregated event, got %d events", n)
+    }
+}
@@ -0,0 +1,28 @@
+package scarfotel
+
+import (
+    "context"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// TraceContext is a scarf.TraceExtractor for OpenTelemetry spans, so events
+// logged with a span's context carry its trace_id and span_id:
+//
+//   logger := scarf.New(endpoint,
+//       scarf.WithTraceContext(scarfotel.TraceContext),
+//       scarf.WithTraceparentHeader(),
+//   )
+//   logger.LogEventContext(ctx, props)
+func TraceContext(ctx context.Context) (scarf.SpanContext, bool) {
+    sc := trace.SpanContextFromContext(ctx)
+    if !sc.IsValid() {
+        return scarf.SpanContext{}, false
+    }
+    return scarf.SpanContext{
+        TraceID: sc.TraceID().String(),
+        SpanID:  sc.SpanID().String(),
+        Sampled: sc.IsSampled(),
+    }, true
+}
@@ -0,0 +1,25 @@
+package scarfotel
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContext(t *testing.T) {
+    if _, ok := TraceContext(context.Background()); ok {
+        t.Fatalf("expected no span in an empty context")
+    }
+    tid, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+    sid, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+    ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+        TraceID:    tid,
+        SpanID:     sid,
+        TraceFlags: trace.FlagsSampled,
+    }))
+    sc, ok := TraceContext(ctx)
+    if !ok || sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID != "00f067aa0ba902b7" || !sc.Sampled {
+        t.Fatalf("unexpected span context %+v (%v)", sc, ok)
+    }
+}
@@ -0,0 +1,121 @@
+// Package scarfotel bridges Scarf events into OpenTelemetry, so
+// organizations with an existing OTel pipeline can route Scarf telemetry
+// through their collector. Events become log records, exported through
+// whatever LoggerProvider (e.g. an OTLP exporter) the application has set
+// up, or events on the active span.
+//
+//   exp, err := otlploggrpc.New(ctx)
+//   if err != nil { ... }
+//   provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(exp)))
+//   logger := scarf.New("", scarf.WithTransport(scarfotel.NewLogTransport(provider)))
+package scarfotel
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/log"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of records and span events emitted
+// by the bridge.
+const ScopeName = "github.com/scarf-sh/scarf-go/scarfotel"
+
+// EventIDKey is the attribute holding the Scarf event ID.
+const EventIDKey = "scarf.event_id"
+
+// ErrNoSpan is returned by the span event transport when the context passed
+// to the logger (see scarf.ScarfEventLogger.LogEventContext) carries no
+// recording span.
+var ErrNoSpan = errors.New("scarf: no recording span in context")
+
+// NewLogTransport returns a transport that emits each event as a log event
+// record (its event name set to the Scarf event name) through provider.
+// Properties become attributes; the event is delivered once the provider's
+// processor accepts it.
+func NewLogTransport(provider log.LoggerProvider) scarf.Transport {
+    logger := provider.Logger(ScopeName)
+    return scarf.TransportFunc(func(ctx context.Context, ev scarf.Event) error {
+        var r log.Record
+        r.SetEventName(ev.Name)
+        r.SetBody(attribute.StringValue(ev.Name))
+        r.SetSeverity(log.SeverityInfo)
+        r.SetTimestamp(ev.Timestamp)
+        r.SetObservedTimestamp(time.Now())
+        r.AddAttributes(attributes(ev)...)
+        logger.Emit(ctx, r)
+        return nil
+    })
+}
+
+// NewSpanEventTransport returns a transport that adds each event to the
+// span in the context passed to the logger, to be exported with the trace.
+// Events logged without a recording span fail with ErrNoSpan.
+func NewSpanEventTransport() scarf.Transport {
+    return scarf.TransportFunc(func(ctx context.Context, ev scarf.Event) error {
+        span := trace.SpanFromContext(ctx)
+        if !span.IsRecording() {
+            return ErrNoSpan
+        }
+        span.AddEvent(ev.Name, trace.WithTimestamp(ev.Timestamp), trace.WithAttributes(attributes(ev)...))
+        return nil
+    })
+}
+
+// attributes converts the event ID and properties to attributes. Properties
+// are first put in the form of scarf.CanonicalValue; values other than
+// strings, bools and numbers are then JSON-encoded, as are unsigned integers
+// that may not fit an int64.
+func attributes(ev scarf.Event) []attribute.KeyValue {
+    attrs := make([]attribute.KeyValue, 0, len(ev.Properties)+1)
+    if ev.ID != "" {
+        attrs = append(attrs, attribute.String(EventIDKey, ev.ID))
+    }
+    for k, v := range ev.Properties {
+        attrs = append(attrs, attribute.KeyValue{Key: attribute.Key(k), Value: value(v)})
+    }
+    return attrs
+}
+
+func value(v any) attribute.Value {
+    v = scarf.CanonicalValue(v)
+    switch vv := v.(type) {
+    case string:
+        return attribute.StringValue(vv)
+    case bool:
+        return attribute.BoolValue(vv)
+    case int:
+        return attribute.IntValue(vv)
+    case int8:
+        return attribute.Int64Value(int64(vv))
+    case int16:
+        return attribute.Int64Value(int64(vv))
+    case int32:
+        return attribute.Int64Value(int64(vv))
+    case int64:
+        return attribute.Int64Value(vv)
+    case uint8:
+        return attribute.Int64Value(int64(vv))
+    case uint16:
+        return attribute.Int64Value(int64(vv))
+    case uint32:
+        return attribute.Int64Value(int64(vv))
+    case float32:
+        return attribute.Float64Value(float64(vv))
+    case float64:
+        return attribute.Float64Value(vv)
+    case []string:
+        return attribute.StringSliceValue(vv)
+    }
+    b, err := json.Marshal(v)
+    if err != nil {
+        return attribute.StringValue(fmt.Sprint(v))
+    }
+    return attribute.StringValue(string(b))
+}
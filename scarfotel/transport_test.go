@@ -0,0 +1,115 @@
+package scarfotel
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "go.opentelemetry.io/otel/attribute"
+    sdklog "go.opentelemetry.io/otel/sdk/log"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// memoryExporter records exported log records.
+type memoryExporter struct {
+    mu      sync.Mutex
+    records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+    for _, r := range records {
+        e.records = append(e.records, r.Clone())
+    }
+    return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func TestLogTransport(t *testing.T) {
+    exp := &memoryExporter{}
+    provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+    defer provider.Shutdown(context.Background())
+
+    ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+    l := scarf.New("", scarf.WithTransport(NewLogTransport(provider)))
+    err := l.LogEventStruct(scarf.Event{Name: "install", Timestamp: ts, ID: "id", Properties: map[string]any{
+        "version": "1.2", "n": 3, "ok": true, "tags": map[string]int{"a": 1},
+    }})
+    if err != nil {
+        t.Fatalf("LogEventStruct: %v", err)
+    }
+
+    if len(exp.records) != 1 {
+        t.Fatalf("expected 1 record, got %d", len(exp.records))
+    }
+    r := exp.records[0]
+    if r.EventName() != "install" || !r.Timestamp().Equal(ts) || r.InstrumentationScope().Name != ScopeName {
+        t.Fatalf("unexpected record: %v", r)
+    }
+    attrs := map[string]string{}
+    r.WalkAttributes(func(kv attribute.KeyValue) bool {
+        attrs[string(kv.Key)] = kv.Value.Emit()
+        return true
+    })
+    want := map[string]string{EventIDKey: "id", "version": "1.2", "n": "3", "ok": "true", "tags": `{"a":1}`}
+    for k, v := range want {
+        if attrs[k] != v {
+            t.Fatalf("attribute %s = %q, want %q (all: %v)", k, attrs[k], v, attrs)
+        }
+    }
+}
+
+func TestValue_Canonical(t *testing.T) {
+    ts := time.Date(2024, 5, 1, 12, 0, 0, 500, time.FixedZone("CEST", 2*3600))
+    cases := []struct {
+        in   any
+        want attribute.Value
+    }{
+        {errors.New("boom"), attribute.StringValue("boom")},
+        {1500 * time.Millisecond, attribute.Int64Value(1500)},
+        {ts, attribute.StringValue("2024-05-01T10:00:00.0000005Z")},
+        {[]byte("hi"), attribute.StringValue("aGk=")},
+    }
+    for _, c := range cases {
+        if got := value(c.in); got != c.want {
+            t.Errorf("value(%#v) = %v, want %v", c.in, got.Emit(), c.want.Emit())
+        }
+    }
+}
+
+func TestSpanEventTransport(t *testing.T) {
+    rec := tracetest.NewSpanRecorder()
+    tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+    l := scarf.New("", scarf.WithTransport(NewSpanEventTransport()))
+
+    if err := l.LogEventContext(context.Background(), map[string]any{"event": "x"}); !errors.Is(err, ErrNoSpan) {
+        t.Fatalf("expected ErrNoSpan, got %v", err)
+    }
+
+    ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+    if err := l.LogEventContext(ctx, map[string]any{"event": "x", "n": 1}); err != nil {
+        t.Fatalf("LogEventContext: %v", err)
+    }
+    span.End()
+
+    events := rec.Ended()[0].Events()
+    if len(events) != 1 || events[0].Name != "x" {
+        t.Fatalf("unexpected span events: %v", events)
+    }
+    var n int64
+    for _, kv := range events[0].Attributes {
+        if kv.Key == "n" {
+            n = kv.Value.AsInt64()
+        }
+    }
+    if n != 1 {
+        t.Fatalf("expected the n attribute, got %v", events[0].Attributes)
+    }
+}
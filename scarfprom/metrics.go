@@ -0,0 +1,121 @@
+// Package scarfprom exposes the health of the telemetry pipeline as
+// Prometheus metrics, so operators can monitor it inside their own
+// services:
+//
+//   m := scarfprom.New("myapp")
+//   logger := scarf.New(endpoint, scarf.WithObserver(m))
+//   m.Track(logger)
+//   prometheus.MustRegister(m)
+package scarfprom
+
+import (
+    "strconv"
+    "sync"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// Metrics is a prometheus.Collector and scarf observer reporting, under an
+// optional namespace:
+//
+//   - scarf_events_sent_total: successful deliveries
+//   - scarf_events_failed_total{status}: failed delivery attempts, by HTTP
+//     status code ("0" when no response was received)
+//   - scarf_events_dropped_total{reason}: events dropped before delivery,
+//     by scarf.DropReason
+//   - scarf_send_latency_seconds: delivery attempt latency
+//   - scarf_queue_depth: sends in progress on the tracked loggers
+//
+// Register it with scarf.WithObserver on each logger to report on, and pass
+// those loggers to Track for the queue depth.
+type Metrics struct {
+    sent    prometheus.Counter
+    failed  *prometheus.CounterVec
+    dropped *prometheus.CounterVec
+    latency prometheus.Histogram
+    depth   *prometheus.Desc
+
+    mu      sync.Mutex
+    loggers []*scarf.ScarfEventLogger
+}
+
+var (
+    _ prometheus.Collector = (*Metrics)(nil)
+    _ scarf.Observer       = (*Metrics)(nil)
+    _ scarf.DropObserver   = (*Metrics)(nil)
+)
+
+// New returns Metrics with the given namespace prefix, which may be empty.
+func New(namespace string) *Metrics {
+    return &Metrics{
+        sent: prometheus.NewCounter(prometheus.CounterOpts{
+            Namespace: namespace, Subsystem: "scarf", Name: "events_sent_total",
+            Help: "Events delivered successfully.",
+        }),
+        failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace, Subsystem: "scarf", Name: "events_failed_total",
+            Help: "Failed event delivery attempts, by HTTP status code (0 when no response was received).",
+        }, []string{"status"}),
+        dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Namespace: namespace, Subsystem: "scarf", Name: "events_dropped_total",
+            Help: "Events dropped before delivery, by reason.",
+        }, []string{"reason"}),
+        latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+            Namespace: namespace, Subsystem: "scarf", Name: "send_latency_seconds",
+            Help:    "Latency of event delivery attempts.",
+            Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+        }),
+        depth: prometheus.NewDesc(prometheus.BuildFQName(namespace, "scarf", "queue_depth"),
+            "Event sends in progress.", nil, nil),
+    }
+}
+
+// Track adds logger's in-progress sends to the queue depth.
+func (m *Metrics) Track(logger *scarf.ScarfEventLogger) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.loggers = append(m.loggers, logger)
+}
+
+// OnSuccess implements scarf.Observer.
+func (m *Metrics) OnSuccess(d scarf.Delivery) {
+    m.sent.Inc()
+    m.latency.Observe(d.Latency.Seconds())
+}
+
+// OnFailure implements scarf.Observer.
+func (m *Metrics) OnFailure(d scarf.Delivery) {
+    m.failed.WithLabelValues(strconv.Itoa(d.StatusCode)).Inc()
+    m.latency.Observe(d.Latency.Seconds())
+}
+
+// OnDrop implements scarf.DropObserver.
+func (m *Metrics) OnDrop(reason scarf.DropReason) {
+    m.dropped.WithLabelValues(string(reason)).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+    m.sent.Describe(ch)
+    m.failed.Describe(ch)
+    m.dropped.Describe(ch)
+    m.latency.Describe(ch)
+    ch <- m.depth
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+    m.sent.Collect(ch)
+    m.failed.Collect(ch)
+    m.dropped.Collect(ch)
+    m.latency.Collect(ch)
+
+    m.mu.Lock()
+    var depth int
+    for _, l := range m.loggers {
+        depth += l.Pending()
+    }
+    m.mu.Unlock()
+    ch <- prometheus.MustNewConstMetric(m.depth, prometheus.GaugeValue, float64(depth))
+}
@@ -0,0 +1,56 @@
+package scarfprom
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+func TestMetrics(t *testing.T) {
+    m := New("app")
+    fail := false
+    logger := scarf.New("", scarf.WithObserver(m), scarf.WithTransport(scarf.TransportFunc(func(context.Context, scarf.Event) error {
+        if fail {
+            return errors.New("boom")
+        }
+        return nil
+    })))
+    m.Track(logger)
+
+    _ = logger.LogEvent(map[string]any{"event": "ok"})
+    _ = logger.LogEvent(map[string]any{"event": "ok"})
+    fail = true
+    _ = logger.LogEvent(map[string]any{"event": "fail"})
+    logger.Close()
+    _ = logger.LogEvent(map[string]any{"event": "late"})
+
+    reg := prometheus.NewPedanticRegistry()
+    reg.MustRegister(m)
+    want := `
+# HELP app_scarf_events_dropped_total Events dropped before delivery, by reason.
+# TYPE app_scarf_events_dropped_total counter
+app_scarf_events_dropped_total{reason="closed"} 1
+# HELP app_scarf_events_failed_total Failed event delivery attempts, by HTTP status code (0 when no response was received).
+# TYPE app_scarf_events_failed_total counter
+app_scarf_events_failed_total{status="0"} 1
+# HELP app_scarf_events_sent_total Events delivered successfully.
+# TYPE app_scarf_events_sent_total counter
+app_scarf_events_sent_total 2
+# HELP app_scarf_queue_depth Event sends in progress.
+# TYPE app_scarf_queue_depth gauge
+app_scarf_queue_depth 0
+`
+    err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+        "app_scarf_events_sent_total", "app_scarf_events_failed_total", "app_scarf_events_dropped_total", "app_scarf_queue_depth")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if n := testutil.CollectAndCount(m, "app_scarf_send_latency_seconds"); n != 1 {
+        t.Fatalf("expected the latency histogram, got %d series", n)
+    }
+}
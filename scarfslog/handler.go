@@ -0,0 +1,166 @@
+// Package scarfslog lets apps reuse their log/slog calls for telemetry. Its
+// Handler wraps another slog.Handler and, besides passing every record on,
+// forwards selected records to a scarf logger as events:
+//
+//   h := scarfslog.NewHandler(slog.NewJSONHandler(os.Stderr, nil), logger, &scarfslog.HandlerOptions{
+//       Level: slog.LevelError,
+//   })
+//   log := slog.New(h)
+//   log.Error("export failed", "format", "csv")            // forwarded: at/above Level
+//   log.Info("export", "format", "csv", "telemetry", true) // forwarded: tagged
+//
+// A forwarded record becomes an event named after its message (or its
+// "event" attribute), with a level property and its attributes as
+// properties; attributes in groups are flattened to "group.key".
+package scarfslog
+
+import (
+    "context"
+    "log/slog"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// TelemetryKey is the attribute that marks a record, or every record of a
+// logger created with slog.Logger.With, for forwarding regardless of level.
+const TelemetryKey = "telemetry"
+
+// HandlerOptions configures a Handler.
+type HandlerOptions struct {
+    // Level is the minimum level of records forwarded as events. If nil,
+    // only records with a telemetry=true attribute are forwarded.
+    Level slog.Leveler
+}
+
+// Handler is a slog.Handler that forwards selected records as Scarf
+// events. Events are sent in the background with scarf.LogAsync, so logging
+// calls never wait for the network, and Flush and Close wait for them.
+type Handler struct {
+    next   slog.Handler
+    logger scarf.EventLogger
+    level  slog.Leveler
+
+    attrs     []slog.Attr // attributes from WithAttrs, keys qualified by group
+    groups    []string
+    telemetry bool // a WithAttrs call added telemetry=true
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler that passes records to next and forwards
+// selected ones to logger. opts may be nil.
+func NewHandler(next slog.Handler, logger scarf.EventLogger, opts *HandlerOptions) *Handler {
+    h := &Handler{next: next, logger: logger}
+    if opts != nil {
+        h.level = opts.Level
+    }
+    return h
+}
+
+// Enabled reports whether next handles level or records at level are
+// forwarded. slog checks Enabled before a record's attributes are known, so
+// a record tagged telemetry=true in the logging call itself is only
+// forwarded if its level is enabled by next or Level; loggers tagged with
+// With forward every level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+    return h.next.Enabled(ctx, level) || h.telemetry || h.forwardsLevel(level)
+}
+
+func (h *Handler) forwardsLevel(level slog.Level) bool {
+    return h.level != nil && level >= h.level.Level()
+}
+
+// Handle passes r to next if it is enabled there, and forwards it as an
+// event if selected.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+    var err error
+    if h.next.Enabled(ctx, r.Level) {
+        err = h.next.Handle(ctx, r)
+    }
+    if !h.logger.Enabled() {
+        return err
+    }
+
+    props := make(map[string]any, len(h.attrs)+r.NumAttrs()+2)
+    for _, a := range h.attrs {
+        addAttr(props, "", a)
+    }
+    tagged := h.telemetry
+    prefix := groupPrefix(h.groups)
+    r.Attrs(func(a slog.Attr) bool {
+        if a.Key == TelemetryKey {
+            if v := a.Value.Resolve(); v.Kind() == slog.KindBool {
+                tagged = tagged || v.Bool()
+                return true
+            }
+        }
+        addAttr(props, prefix, a)
+        return true
+    })
+    if !tagged && !h.forwardsLevel(r.Level) {
+        return err
+    }
+    if _, ok := props["event"]; !ok {
+        props["event"] = r.Message
+    }
+    props["level"] = r.Level.String()
+    scarf.LogAsync(context.WithoutCancel(ctx), h.logger, props)
+    return err
+}
+
+// WithAttrs returns a Handler whose records include attrs.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    h2 := *h
+    h2.next = h.next.WithAttrs(attrs)
+    h2.attrs = append([]slog.Attr(nil), h.attrs...)
+    prefix := groupPrefix(h.groups)
+    for _, a := range attrs {
+        if a.Key == TelemetryKey && len(h.groups) == 0 {
+            if v := a.Value.Resolve(); v.Kind() == slog.KindBool {
+                h2.telemetry = h2.telemetry || v.Bool()
+                continue
+            }
+        }
+        h2.attrs = append(h2.attrs, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+    }
+    return &h2
+}
+
+// WithGroup returns a Handler that qualifies later attributes with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+    if name == "" {
+        return h
+    }
+    h2 := *h
+    h2.next = h.next.WithGroup(name)
+    h2.groups = append(append([]string(nil), h.groups...), name)
+    return &h2
+}
+
+func groupPrefix(groups []string) string {
+    var prefix string
+    for _, g := range groups {
+        prefix += g + "."
+    }
+    return prefix
+}
+
+// addAttr adds a to props under prefix+key, flattening groups.
+func addAttr(props map[string]any, prefix string, a slog.Attr) {
+    v := a.Value.Resolve()
+    if v.Kind() == slog.KindGroup {
+        if a.Key != "" {
+            prefix += a.Key + "."
+        }
+        for _, ga := range v.Group() {
+            addAttr(props, prefix, ga)
+        }
+        return
+    }
+    if a.Key == "" {
+        return
+    }
+    // Durations, times and errors are passed through as is, so the logger
+    // encodes them like any other property (see scarf.CanonicalValue).
+    props[prefix+a.Key] = v.Any()
+}
@@ -0,0 +1,76 @@
+package scarfslog
+
+import (
+    "bytes"
+    "errors"
+    "log/slog"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarftest"
+)
+
+func TestHandler(t *testing.T) {
+    var out bytes.Buffer
+    rec := scarftest.NewRecorder()
+    h := NewHandler(slog.NewTextHandler(&out, nil), scarf.New("", scarf.WithTransport(rec)), &HandlerOptions{Level: slog.LevelError})
+    log := slog.New(h)
+
+    log.Info("not forwarded", "k", "v")
+    log.Info("export", "format", "csv", TelemetryKey, true)
+    log.Error("export failed", "err", errors.New("disk full"), slog.Group("req", "id", 7), "took", 1500*time.Millisecond)
+
+    if n := strings.Count(out.String(), "\n"); n != 3 {
+        t.Fatalf("expected every record to reach the wrapped handler, got %q", out.String())
+    }
+    events := rec.WaitForEvents(t, 2)
+    if len(events) != 2 {
+        t.Fatalf("expected 2 events, got %+v", events)
+    }
+    byName := map[string]map[string]any{}
+    for _, ev := range events {
+        byName[ev.Name] = ev.Properties
+    }
+    if p := byName["export"]; p["format"] != "csv" || p["level"] != "INFO" || p[TelemetryKey] != nil {
+        t.Fatalf("unexpected tagged event %v", p)
+    }
+    if p := byName["export failed"]; scarf.CanonicalValue(p["err"]) != "disk full" || p["req.id"] != int64(7) || p["level"] != "ERROR" {
+        t.Fatalf("unexpected error event %v", p)
+    }
+    if p := byName["export failed"]; scarf.CanonicalValue(p["took"]) != int64(1500) {
+        t.Fatalf("expected the duration to be encoded in milliseconds, got %v", p["took"])
+    }
+}
+
+func TestHandler_WithAttrs(t *testing.T) {
+    var out bytes.Buffer
+    rec := scarftest.NewRecorder()
+    base := slog.New(NewHandler(slog.NewTextHandler(&out, nil), scarf.New("", scarf.WithTransport(rec)), nil))
+
+    usage := base.With(TelemetryKey, true, "component", "exporter")
+    usage.Debug("run", "event", "export_run")
+    usage.WithGroup("job").Debug("rows", "n", 3)
+    base.Error("untagged")
+
+    rec.WaitForEvents(t, 2)
+    time.Sleep(20 * time.Millisecond)
+    events := rec.Events()
+    if len(events) != 2 {
+        t.Fatalf("expected only the tagged logger's records, got %+v", events)
+    }
+    byName := map[string]map[string]any{}
+    for _, ev := range events {
+        byName[ev.Name] = ev.Properties
+    }
+    if p := byName["export_run"]; p["component"] != "exporter" || p["level"] != "DEBUG" {
+        t.Fatalf("unexpected event %v", byName)
+    }
+    if p := byName["rows"]; p["job.n"] != int64(3) || p["component"] != "exporter" {
+        t.Fatalf("unexpected grouped event %v", byName)
+    }
+    if strings.Contains(out.String(), "run") {
+        t.Fatalf("debug records must still be filtered by the wrapped handler: %q", out.String())
+    }
+}
@@ -0,0 +1,145 @@
+package scarftest
+
+import (
+    "sort"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// Clock is a scarf.Clock whose time only moves when Advance is called, for
+// deterministic tests of backoff, heartbeats, schedules and batching:
+//
+//   clk := scarftest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//   logger := scarf.New("", scarf.WithTransport(rec), scarf.WithClock(clk))
+//   stop := logger.StartHeartbeat(time.Hour, nil)
+//   clk.WaitForTimers(t, 1) // the heartbeat loop is waiting
+//   clk.Advance(time.Hour)
+//
+// It is safe for concurrent use.
+type Clock struct {
+    mu     sync.Mutex
+    now    time.Time
+    timers []*fakeTimer
+    added  chan struct{} // closed and replaced whenever a timer is armed
+}
+
+// NewClock returns a Clock set to start.
+func NewClock(start time.Time) *Clock {
+    return &Clock{now: start, added: make(chan struct{})}
+}
+
+// Now returns the clock's current time.
+func (c *Clock) Now() time.Time {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.now
+}
+
+// NewTimer returns a timer that fires once the clock has been advanced by d.
+func (c *Clock) NewTimer(d time.Duration) scarf.Timer {
+    t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+    t.Reset(d)
+    return t
+}
+
+// AfterFunc returns a timer that calls f in its own goroutine once the clock
+// has been advanced by d.
+func (c *Clock) AfterFunc(d time.Duration, f func()) scarf.Timer {
+    t := &fakeTimer{clock: c, f: f}
+    t.Reset(d)
+    return t
+}
+
+// Advance moves the clock forward by d and fires, in order, the timers that
+// fall due. A timer re-armed while firing fires at the next Advance.
+func (c *Clock) Advance(d time.Duration) {
+    c.mu.Lock()
+    c.now = c.now.Add(d)
+    var due []*fakeTimer
+    kept := c.timers[:0]
+    for _, t := range c.timers {
+        if !t.when.After(c.now) {
+            due = append(due, t)
+        } else {
+            kept = append(kept, t)
+        }
+    }
+    c.timers = kept
+    now := c.now
+    c.mu.Unlock()
+
+    sort.SliceStable(due, func(i, j int) bool { return due[i].when.Before(due[j].when) })
+    for _, t := range due {
+        if t.f != nil {
+            go t.f()
+            continue
+        }
+        select {
+        case t.ch <- now:
+        default:
+        }
+    }
+}
+
+// WaitForTimers blocks until at least n timers are armed, so a test can be
+// sure a background loop is waiting before it calls Advance. Like
+// Recorder.WaitForEvents, it fails t if they aren't armed within 5 seconds,
+// e.g. because the loop never started.
+func (c *Clock) WaitForTimers(t testing.TB, n int) {
+    t.Helper()
+    deadline := time.NewTimer(5 * time.Second)
+    defer deadline.Stop()
+    for {
+        c.mu.Lock()
+        armed, added := len(c.timers), c.added
+        c.mu.Unlock()
+        if armed >= n {
+            return
+        }
+        select {
+        case <-added:
+        case <-deadline.C:
+            t.Fatalf("scarftest: clock: %d timers armed, want %d", armed, n)
+            return
+        }
+    }
+}
+
+type fakeTimer struct {
+    clock *Clock
+    ch    chan time.Time
+    f     func()
+    when  time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+    return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+    c := t.clock
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for i, armed := range c.timers {
+        if armed == t {
+            c.timers = append(c.timers[:i], c.timers[i+1:]...)
+            return true
+        }
+    }
+    return false
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+    wasArmed := t.Stop()
+    c := t.clock
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    t.when = c.now.Add(d)
+    c.timers = append(c.timers, t)
+    close(c.added)
+    c.added = make(chan struct{})
+    return wasArmed
+}
@@ -0,0 +1,94 @@
+package scarftest
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+func TestClockHeartbeat(t *testing.T) {
+    start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    clk := NewClock(start)
+    rec := NewRecorder()
+    l := scarf.New("", scarf.WithTransport(rec), scarf.WithClock(clk))
+
+    stop := l.StartHeartbeat(time.Hour, nil)
+    defer stop()
+    for i := 1; i <= 2; i++ {
+        clk.WaitForTimers(t, 1)
+        clk.Advance(time.Hour)
+        waitForEvents(t, rec, i)
+    }
+    events := rec.Events()
+    if events[1].Properties["uptime_seconds"] != int64(7200) || !events[1].Timestamp.Equal(start.Add(2*time.Hour)) {
+        t.Fatalf("expected uptime and timestamp from the fake clock, got %+v", events[1])
+    }
+}
+
+func TestClockRetryAfter(t *testing.T) {
+    var limited atomic.Bool
+    limited.Store(true)
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if limited.Swap(false) {
+            w.Header().Set("Retry-After", "60")
+            w.WriteHeader(http.StatusTooManyRequests)
+        }
+    }))
+    defer srv.Close()
+
+    clk := NewClock(time.Now())
+    l := scarf.New(srv.URL, scarf.WithClock(clk))
+    props := map[string]any{"event": "x"}
+    if err := l.LogEvent(props); !errors.Is(err, scarf.ErrRateLimited) {
+        t.Fatalf("expected ErrRateLimited, got %v", err)
+    }
+    clk.Advance(59 * time.Second)
+    if err := l.LogEvent(props); !errors.Is(err, scarf.ErrRateLimited) {
+        t.Fatalf("expected sending to stay paused, got %v", err)
+    }
+    clk.Advance(time.Second)
+    if err := l.LogEvent(props); err != nil {
+        t.Fatalf("expected sending to resume after Retry-After, got %v", err)
+    }
+}
+
+func TestClockTimers(t *testing.T) {
+    clk := NewClock(time.Unix(0, 0))
+    fired := make(chan struct{})
+    clk.AfterFunc(time.Minute, func() { close(fired) })
+    timer := clk.NewTimer(time.Second)
+    stopped := clk.NewTimer(time.Second)
+    if !stopped.Stop() {
+        t.Fatalf("expected Stop to report an armed timer")
+    }
+
+    clk.Advance(time.Second)
+    select {
+    case <-timer.C():
+    default:
+        t.Fatalf("expected the timer to fire")
+    }
+    select {
+    case <-stopped.C():
+        t.Fatalf("expected a stopped timer not to fire")
+    default:
+    }
+    clk.Advance(time.Minute)
+    <-fired
+}
+
+func waitForEvents(t *testing.T, rec *Recorder, n int) {
+    t.Helper()
+    deadline := time.Now().Add(5 * time.Second)
+    for len(rec.Events()) < n {
+        if time.Now().After(deadline) {
+            t.Fatalf("expected %d events, got %d", n, len(rec.Events()))
+        }
+        time.Sleep(time.Millisecond)
+    }
+}
@@ -0,0 +1,304 @@
+package scarftest
+
+import (
+    "bufio"
+    "bytes"
+    "compress/gzip"
+    "encoding/json"
+    "fmt"
+    "io"
+    "mime"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+    "github.com/scarf-sh/scarf-go/scarf/pb"
+)
+
+// Collector is a local HTTP endpoint that decodes the requests the scarf
+// package sends back into events, for end-to-end assertions over the real
+// wire format:
+//
+//   c := scarftest.NewCollector(t)
+//   logger := scarf.New(c.URL)
+//   // ... exercise code ...
+//   got := c.WaitForEvents(1)
+//
+// It understands query-parameter events, JSON and protobuf bodies, and JSON,
+// NDJSON and protobuf batches, gzipped or not. MessagePack requests are
+// answered with 415 Unsupported Media Type, so the logger falls back to JSON.
+// Query-parameter values are decoded as strings and JSON numbers as float64.
+// The reserved "event", "timestamp" and "event_id" fields are lifted into
+// Name, Timestamp and ID. HEAD requests, as sent by Ping, are answered
+// without collecting anything.
+//
+// It is safe for concurrent use.
+type Collector struct {
+    // URL is the endpoint URL to pass to scarf.New.
+    URL string
+
+    t   testing.TB
+    srv *httptest.Server
+
+    mu       sync.Mutex
+    events   []scarf.Event
+    requests int
+    status   int
+    changed  chan struct{} // closed and replaced whenever events arrive
+}
+
+// NewCollector starts a Collector that is shut down when the test ends.
+// Requests it can't decode fail the test.
+func NewCollector(t testing.TB) *Collector {
+    t.Helper()
+    c := &Collector{t: t, status: http.StatusNoContent, changed: make(chan struct{})}
+    c.srv = httptest.NewServer(c)
+    c.URL = c.srv.URL
+    t.Cleanup(c.srv.Close)
+    return c
+}
+
+// ServeHTTP decodes the request into events and records them, unless
+// SetStatus has set a non-2xx status.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    c.mu.Lock()
+    c.requests++
+    status := c.status
+    c.mu.Unlock()
+
+    if r.Method == http.MethodHead {
+        // A Ping; there is no event to collect.
+        w.WriteHeader(status)
+        return
+    }
+    mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+    if mediaType == "application/msgpack" {
+        w.WriteHeader(http.StatusUnsupportedMediaType)
+        return
+    }
+    events, err := decodeRequest(r, mediaType)
+    if err != nil {
+        c.t.Errorf("scarftest: collector: %v", err)
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    if status >= 200 && status < 300 {
+        c.add(events)
+    }
+    w.WriteHeader(status)
+}
+
+func (c *Collector) add(events []scarf.Event) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.events = append(c.events, events...)
+    close(c.changed)
+    c.changed = make(chan struct{})
+}
+
+// Events returns the collected events in the order they were received.
+func (c *Collector) Events() []scarf.Event {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    out := make([]scarf.Event, len(c.events))
+    copy(out, c.events)
+    return out
+}
+
+// Requests returns the number of requests received, including rejected ones.
+func (c *Collector) Requests() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.requests
+}
+
+// SetStatus sets the status code of subsequent responses. Events in requests
+// answered with a non-2xx status are not collected. The default is 204.
+func (c *Collector) SetStatus(code int) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.status = code
+}
+
+// Reset discards all collected events and the request count.
+func (c *Collector) Reset() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.events = nil
+    c.requests = 0
+}
+
+// WaitForEvents blocks until at least n events have been collected, e.g.
+// after LogEventAsync or a batched send, and returns them. It fails the test
+// if they don't arrive within 5 seconds.
+func (c *Collector) WaitForEvents(n int) []scarf.Event {
+    c.t.Helper()
+    deadline := time.NewTimer(5 * time.Second)
+    defer deadline.Stop()
+    for {
+        c.mu.Lock()
+        got, changed := len(c.events), c.changed
+        c.mu.Unlock()
+        if got >= n {
+            return c.Events()
+        }
+        select {
+        case <-changed:
+        case <-deadline.C:
+            c.t.Fatalf("scarftest: collector: got %d events, want %d", got, n)
+            return nil
+        }
+    }
+}
+
+// decodeRequest decodes the events in r, whose body has the given media type.
+func decodeRequest(r *http.Request, mediaType string) ([]scarf.Event, error) {
+    var body io.Reader = r.Body
+    if r.Header.Get("Content-Encoding") == "gzip" {
+        zr, err := gzip.NewReader(r.Body)
+        if err != nil {
+            return nil, fmt.Errorf("gzip: %w", err)
+        }
+        defer zr.Close()
+        body = zr
+    }
+    b, err := io.ReadAll(body)
+    if err != nil {
+        return nil, fmt.Errorf("read body: %w", err)
+    }
+    if len(b) == 0 {
+        return []scarf.Event{queryEvent(r.URL.Query())}, nil
+    }
+
+    switch mediaType {
+    case "application/json":
+        if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+            var objs []map[string]any
+            if err := json.Unmarshal(b, &objs); err != nil {
+                return nil, fmt.Errorf("JSON batch: %w", err)
+            }
+            events := make([]scarf.Event, len(objs))
+            for i, obj := range objs {
+                events[i] = fieldsEvent(obj)
+            }
+            return events, nil
+        }
+        var obj map[string]any
+        if err := json.Unmarshal(b, &obj); err != nil {
+            return nil, fmt.Errorf("JSON body: %w", err)
+        }
+        return []scarf.Event{fieldsEvent(obj)}, nil
+    case "application/x-ndjson":
+        var events []scarf.Event
+        sc := bufio.NewScanner(bytes.NewReader(b))
+        sc.Buffer(nil, len(b)+1)
+        for sc.Scan() {
+            line := bytes.TrimSpace(sc.Bytes())
+            if len(line) == 0 {
+                continue
+            }
+            var obj map[string]any
+            if err := json.Unmarshal(line, &obj); err != nil {
+                return nil, fmt.Errorf("NDJSON line %d: %w", len(events)+1, err)
+            }
+            events = append(events, fieldsEvent(obj))
+        }
+        return events, sc.Err()
+    case pb.ContentType:
+        return protobufEvents(b)
+    }
+    return nil, fmt.Errorf("unsupported Content-Type %q", mediaType)
+}
+
+// queryEvent decodes an event sent as query parameters.
+func queryEvent(q url.Values) scarf.Event {
+    fields := make(map[string]any, len(q))
+    for k, vs := range q {
+        fields[k] = vs[len(vs)-1]
+    }
+    return fieldsEvent(fields)
+}
+
+// fieldsEvent lifts the reserved fields out of a decoded event object.
+func fieldsEvent(fields map[string]any) scarf.Event {
+    var ev scarf.Event
+    if name, ok := fields["event"].(string); ok {
+        ev.Name = name
+        delete(fields, "event")
+    }
+    if ts, ok := fields["timestamp"].(string); ok {
+        if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+            ev.Timestamp = t
+            delete(fields, "timestamp")
+        }
+    }
+    if id, ok := fields["event_id"].(string); ok {
+        ev.ID = id
+        delete(fields, "event_id")
+    }
+    ev.Properties = fields
+    return ev
+}
+
+// protobufEvents decodes a protobuf Event or Batch. The two share a
+// Content-Type, so a body is taken as a Batch when it holds nothing but
+// well-formed events; the SDK always stamps single events with a timestamp
+// or ID, which a Batch can't have.
+func protobufEvents(b []byte) ([]scarf.Event, error) {
+    var single pb.Event
+    singleErr := single.Unmarshal(b)
+    if singleErr != nil || (single.TimestampUnixNano == 0 && single.ID == "" && single.Properties == nil) {
+        var batch pb.Batch
+        if err := batch.Unmarshal(b); err == nil {
+            events := make([]scarf.Event, len(batch.Events))
+            for i, e := range batch.Events {
+                ev, err := protobufEvent(e)
+                if err != nil {
+                    return nil, err
+                }
+                events[i] = ev
+            }
+            return events, nil
+        }
+    }
+    if singleErr != nil {
+        return nil, fmt.Errorf("protobuf body: %w", singleErr)
+    }
+    ev, err := protobufEvent(single)
+    if err != nil {
+        return nil, err
+    }
+    return []scarf.Event{ev}, nil
+}
+
+func protobufEvent(e pb.Event) (scarf.Event, error) {
+    ev := scarf.Event{Name: e.Name, ID: e.ID, Properties: make(map[string]any, len(e.Properties))}
+    if e.TimestampUnixNano != 0 {
+        ev.Timestamp = time.Unix(0, e.TimestampUnixNano).UTC()
+    }
+    for k, v := range e.Properties {
+        switch v.Kind {
+        case pb.KindString:
+            ev.Properties[k] = v.String
+        case pb.KindInt:
+            ev.Properties[k] = v.Int
+        case pb.KindDouble:
+            ev.Properties[k] = v.Double
+        case pb.KindBool:
+            ev.Properties[k] = v.Bool
+        case pb.KindJSON:
+            var x any
+            if err := json.Unmarshal([]byte(v.String), &x); err != nil {
+                return scarf.Event{}, fmt.Errorf("protobuf property %q: %w", k, err)
+            }
+            ev.Properties[k] = x
+        default:
+            ev.Properties[k] = nil
+        }
+    }
+    return ev, nil
+}
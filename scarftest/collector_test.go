@@ -0,0 +1,74 @@
+package scarftest
+
+import (
+    "context"
+    "net/http"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+func TestCollectorFormats(t *testing.T) {
+    batch := []scarf.Event{
+        {Name: "a", Properties: map[string]any{"n": 1}},
+        {Name: "b", Properties: map[string]any{"ok": true}},
+    }
+    tests := []struct {
+        name string
+        opts []scarf.Option
+        n    any // the decoded value of the single event's "n" property
+    }{
+        {"query", nil, "1"},
+        {"json", []scarf.Option{scarf.WithEncoding(scarf.EncodingJSON)}, float64(1)},
+        {"ndjson", []scarf.Option{scarf.WithEncoding(scarf.EncodingJSON), scarf.WithBatchFormat(scarf.BatchNDJSON)}, float64(1)},
+        {"gzip", []scarf.Option{scarf.WithEncoding(scarf.EncodingJSON), scarf.WithGzipThreshold(1)}, float64(1)},
+        {"protobuf", []scarf.Option{scarf.WithEncoding(scarf.EncodingProtobuf)}, int64(1)},
+        {"msgpack", []scarf.Option{scarf.WithEncoding(scarf.EncodingMessagePack)}, float64(1)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            c := NewCollector(t)
+            l := scarf.New(c.URL, tt.opts...)
+            if err := l.LogEvent(map[string]any{"event": "install", "n": 1}); err != nil {
+                t.Fatal(err)
+            }
+            got := c.Events()
+            if len(got) != 1 || got[0].Name != "install" || got[0].Properties["n"] != tt.n {
+                t.Fatalf("unexpected events: %+v", got)
+            }
+            if got[0].Timestamp.IsZero() || got[0].ID == "" {
+                t.Fatalf("expected the timestamp and ID to be decoded, got %+v", got[0])
+            }
+
+            c.Reset()
+            if err := l.LogEvents(context.Background(), batch); err != nil {
+                t.Fatal(err)
+            }
+            got = c.Events()
+            if len(got) != 2 || got[0].Name != "a" || got[1].Name != "b" || got[1].Properties["ok"] != true {
+                t.Fatalf("unexpected batch: %+v", got)
+            }
+        })
+    }
+}
+
+func TestCollectorStatus(t *testing.T) {
+    c := NewCollector(t)
+    l := scarf.New(c.URL)
+    c.SetStatus(http.StatusServiceUnavailable)
+    if err := l.LogEvent(map[string]any{"event": "x"}); err == nil {
+        t.Fatal("expected the rejected event to fail")
+    }
+    if len(c.Events()) != 0 || c.Requests() == 0 {
+        t.Fatalf("expected a request but no events, got %d requests and %+v", c.Requests(), c.Events())
+    }
+}
+
+func TestCollectorWaitForEvents(t *testing.T) {
+    c := NewCollector(t)
+    l := scarf.New(c.URL)
+    l.LogEventAsync(map[string]any{"event": "x"})
+    if got := c.WaitForEvents(1); got[0].Name != "x" {
+        t.Fatalf("unexpected events: %+v", got)
+    }
+}
@@ -0,0 +1,144 @@
+package scarftest
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/rand"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// ErrInjected is the error of sends failed by a FaultTransport without a
+// status code.
+var ErrInjected = errors.New("scarftest: injected failure")
+
+// Faults describes how a FaultTransport misbehaves.
+type Faults struct {
+    // Latency delays every send, or until the send's context is done.
+    Latency time.Duration
+    // Jitter adds a random extra delay of up to Jitter.
+    Jitter time.Duration
+    // FailureRate is the fraction of sends, from 0 to 1, that fail.
+    FailureRate float64
+    // StatusCode makes failed sends return a *scarf.EndpointError with this
+    // status, as if the backend had answered it. Otherwise they return Err.
+    StatusCode int
+    // RetryAfter, with StatusCode, sets the EndpointError's RetryAfter, so
+    // the failure also matches scarf.ErrRateLimited.
+    RetryAfter time.Duration
+    // Err is returned by failed sends without a StatusCode. The default is
+    // ErrInjected.
+    Err error
+}
+
+// FaultTransport is a scarf.Transport that simulates an unreliable telemetry
+// backend, injecting latency, failures and status codes, so applications can
+// check they behave under outages:
+//
+//   rec := scarftest.NewRecorder()
+//   ft := scarftest.NewFaultTransport(rec, scarftest.Faults{FailureRate: 1, StatusCode: 503})
+//   logger := scarf.New("", scarf.WithTransport(ft))
+//   // ... exercise code during the outage ...
+//   ft.SetFaults(scarftest.Faults{}) // the backend recovers
+//
+// Sends that don't fail are passed to next, if not nil. It is safe for
+// concurrent use.
+type FaultTransport struct {
+    next scarf.Transport
+
+    mu       sync.Mutex
+    faults   Faults
+    rng      *rand.Rand
+    attempts int
+    failures int
+}
+
+// NewFaultTransport returns a FaultTransport that forwards to next and
+// misbehaves as described by faults.
+func NewFaultTransport(next scarf.Transport, faults Faults) *FaultTransport {
+    return &FaultTransport{next: next, faults: faults, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// SetFaults changes how the transport misbehaves from the next send on, e.g.
+// to end a simulated outage.
+func (f *FaultTransport) SetFaults(faults Faults) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.faults = faults
+}
+
+// Seed makes the transport's random choices reproducible.
+func (f *FaultTransport) Seed(seed int64) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.rng = rand.New(rand.NewSource(seed))
+}
+
+// Attempts returns the number of sends attempted.
+func (f *FaultTransport) Attempts() int {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.attempts
+}
+
+// Failures returns the number of sends that were made to fail.
+func (f *FaultTransport) Failures() int {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.failures
+}
+
+// Send waits for the configured latency, then fails or forwards ev. It
+// implements scarf.Transport.
+func (f *FaultTransport) Send(ctx context.Context, ev scarf.Event) error {
+    f.mu.Lock()
+    faults := f.faults
+    delay := faults.Latency
+    if faults.Jitter > 0 {
+        delay += time.Duration(f.rng.Int63n(int64(faults.Jitter) + 1))
+    }
+    fail := faults.FailureRate > 0 && f.rng.Float64() < faults.FailureRate
+    f.attempts++
+    if fail {
+        f.failures++
+    }
+    f.mu.Unlock()
+
+    if delay > 0 {
+        t := time.NewTimer(delay)
+        select {
+        case <-t.C:
+        case <-ctx.Done():
+            t.Stop()
+            return ctx.Err()
+        }
+    }
+    if fail {
+        return faults.err()
+    }
+    if f.next == nil {
+        return nil
+    }
+    return f.next.Send(ctx, ev)
+}
+
+func (faults Faults) err() error {
+    if faults.StatusCode != 0 {
+        epErr := &scarf.EndpointError{
+            StatusCode: faults.StatusCode,
+            Status:     fmt.Sprintf("%d %s", faults.StatusCode, http.StatusText(faults.StatusCode)),
+        }
+        if faults.RetryAfter > 0 {
+            epErr.RetryAfter = time.Now().Add(faults.RetryAfter)
+        }
+        return epErr
+    }
+    if faults.Err != nil {
+        return faults.Err
+    }
+    return ErrInjected
+}
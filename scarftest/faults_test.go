@@ -0,0 +1,68 @@
+package scarftest
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+func TestFaultTransport(t *testing.T) {
+    rec := NewRecorder()
+    ft := NewFaultTransport(rec, Faults{FailureRate: 1, StatusCode: 503})
+    l := scarf.New("", scarf.WithTransport(ft))
+
+    err := l.LogEvent(map[string]any{"event": "x"})
+    var epErr *scarf.EndpointError
+    if !errors.As(err, &epErr) || epErr.StatusCode != 503 {
+        t.Fatalf("expected an injected 503, got %v", err)
+    }
+
+    ft.SetFaults(Faults{FailureRate: 1, StatusCode: 429, RetryAfter: time.Minute})
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, scarf.ErrRateLimited) {
+        t.Fatalf("expected an injected rate limit, got %v", err)
+    }
+
+    ft.SetFaults(Faults{FailureRate: 1})
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, ErrInjected) {
+        t.Fatalf("expected ErrInjected, got %v", err)
+    }
+
+    ft.SetFaults(Faults{})
+    if err := l.LogEvent(map[string]any{"event": "y"}); err != nil || len(rec.Events()) != 1 {
+        t.Fatalf("expected the event to go through after recovery, got %v", err)
+    }
+    if ft.Attempts() != 4 || ft.Failures() != 3 {
+        t.Fatalf("expected 4 attempts and 3 failures, got %d and %d", ft.Attempts(), ft.Failures())
+    }
+}
+
+func TestFaultTransportLatency(t *testing.T) {
+    ft := NewFaultTransport(nil, Faults{Latency: time.Hour})
+    l := scarf.New("", scarf.WithTransport(ft), scarf.WithTimeout(20*time.Millisecond))
+    if err := l.LogEvent(map[string]any{"event": "x"}); !errors.Is(err, scarf.ErrTimeout) {
+        t.Fatalf("expected the slow backend to time out, got %v", err)
+    }
+
+    ft.SetFaults(Faults{Latency: 5 * time.Millisecond, Jitter: 5 * time.Millisecond})
+    start := time.Now()
+    if err := ft.Send(context.Background(), scarf.Event{Name: "x"}); err != nil {
+        t.Fatal(err)
+    }
+    if d := time.Since(start); d < 5*time.Millisecond {
+        t.Fatalf("expected at least the configured latency, took %v", d)
+    }
+}
+
+func TestFaultTransportFailureRate(t *testing.T) {
+    ft := NewFaultTransport(nil, Faults{FailureRate: 0.3})
+    ft.Seed(1)
+    for i := 0; i < 1000; i++ {
+        _ = ft.Send(context.Background(), scarf.Event{Name: "x"})
+    }
+    if n := ft.Failures(); n < 250 || n > 350 {
+        t.Fatalf("expected about 300 failures, got %d", n)
+    }
+}
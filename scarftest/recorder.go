@@ -0,0 +1,99 @@
+// Package scarftest provides helpers for testing code that emits telemetry
+// through the scarf package.
+package scarftest
+
+import (
+    "context"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// Recorder is a scarf.Transport that captures events in memory instead of
+// sending them, for assertions in tests:
+//
+//   rec := scarftest.NewRecorder()
+//   logger := scarf.New("", scarf.WithTransport(rec))
+//   // ... exercise code ...
+//   if got := rec.Events(); len(got) != 1 { ... }
+//
+// It is safe for concurrent use.
+type Recorder struct {
+    mu      sync.Mutex
+    events  []scarf.Event
+    changed chan struct{} // closed and replaced whenever events arrive
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+    return &Recorder{}
+}
+
+// Send records ev. It implements scarf.Transport.
+func (r *Recorder) Send(_ context.Context, ev scarf.Event) error {
+    ev.Properties = copyProperties(ev.Properties)
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.events = append(r.events, ev)
+    if r.changed != nil {
+        close(r.changed)
+        r.changed = nil
+    }
+    return nil
+}
+
+// Events returns the recorded events in the order they were sent.
+func (r *Recorder) Events() []scarf.Event {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    out := make([]scarf.Event, len(r.events))
+    copy(out, r.events)
+    return out
+}
+
+// WaitForEvents blocks until at least n events have been recorded, e.g. by
+// middleware that sends in the background, and returns them. Like
+// Collector.WaitForEvents, it fails t if they don't arrive within 5 seconds.
+func (r *Recorder) WaitForEvents(t testing.TB, n int) []scarf.Event {
+    t.Helper()
+    deadline := time.NewTimer(5 * time.Second)
+    defer deadline.Stop()
+    for {
+        r.mu.Lock()
+        got := len(r.events)
+        if r.changed == nil {
+            r.changed = make(chan struct{})
+        }
+        changed := r.changed
+        r.mu.Unlock()
+        if got >= n {
+            return r.Events()
+        }
+        select {
+        case <-changed:
+        case <-deadline.C:
+            t.Fatalf("scarftest: recorder: got %d events, want %d", got, n)
+            return nil
+        }
+    }
+}
+
+// Reset discards all recorded events.
+func (r *Recorder) Reset() {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.events = nil
+}
+
+func copyProperties(props map[string]any) map[string]any {
+    if props == nil {
+        return nil
+    }
+    out := make(map[string]any, len(props))
+    for k, v := range props {
+        out[k] = v
+    }
+    return out
+}
@@ -0,0 +1,61 @@
+package scarftest
+
+import (
+    "sync"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+func TestRecorder(t *testing.T) {
+    rec := NewRecorder()
+    l := scarf.New("", scarf.WithTransport(rec))
+
+    props := map[string]any{"event": "install", "version": "1.0.0"}
+    if err := l.LogEvent(props); err != nil {
+        t.Fatalf("expected success, got %v", err)
+    }
+    props["version"] = "mutated"
+
+    events := rec.Events()
+    if len(events) != 1 {
+        t.Fatalf("expected 1 event, got %d", len(events))
+    }
+    if events[0].Name != "install" || events[0].Properties["version"] != "1.0.0" {
+        t.Fatalf("unexpected event: %+v", events[0])
+    }
+
+    rec.Reset()
+    if got := len(rec.Events()); got != 0 {
+        t.Fatalf("expected no events after Reset, got %d", got)
+    }
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+    rec := NewRecorder()
+    l := scarf.New("", scarf.WithTransport(rec))
+
+    var wg sync.WaitGroup
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            _ = l.LogEvent(map[string]any{"event": "x"})
+        }()
+    }
+    wg.Wait()
+    if got := len(rec.Events()); got != 20 {
+        t.Fatalf("expected 20 events, got %d", got)
+    }
+}
+
+func TestRecorder_WaitForEvents(t *testing.T) {
+    rec := NewRecorder()
+    l := scarf.New("", scarf.WithTransport(rec))
+    for i := 0; i < 3; i++ {
+        l.LogEventAsync(map[string]any{"event": "x"})
+    }
+    if got := rec.WaitForEvents(t, 3); len(got) != 3 {
+        t.Fatalf("expected 3 events, got %d", len(got))
+    }
+}
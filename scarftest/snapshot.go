@@ -0,0 +1,148 @@
+package scarftest
+
+import (
+    "bytes"
+    "compress/gzip"
+    "context"
+    "encoding/base64"
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strings"
+    "testing"
+    "time"
+    "unicode/utf8"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+// UpdateSnapshotsEnv is the environment variable that makes SnapshotRequest
+// write golden files instead of comparing against them.
+const UpdateSnapshotsEnv = "SCARFTEST_UPDATE"
+
+// Fixed values SnapshotRequest gives events that don't set their own.
+var (
+    SnapshotTimestamp = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+    SnapshotEventID   = "00000000-0000-7000-8000-000000000000"
+)
+
+// SnapshotRequest renders the HTTP request logger would send for ev (method,
+// URL, headers and body, via scarf's PrepareRequest) and compares it with the
+// golden file testdata/<test name>.golden, so SDK upgrades that change the
+// wire format fail downstream tests. Run the tests with SCARFTEST_UPDATE=1 to
+// create or update golden files.
+//
+// To keep snapshots stable, ev gets SnapshotTimestamp and SnapshotEventID if
+// it has no timestamp or ID, and values that vary by machine or build are
+// masked: the SDK version, platform, architecture and Go version in the
+// User-Agent, and the signature headers of WithRequestSigning. So are the
+// credentials in the Authorization header of WithAPIKey, which don't belong in
+// committed golden files. Gzipped bodies are shown decompressed, and binary
+// bodies as base64.
+func SnapshotRequest(t testing.TB, logger *scarf.ScarfEventLogger, ev scarf.Event) {
+    t.Helper()
+    if ev.Timestamp.IsZero() {
+        ev.Timestamp = SnapshotTimestamp
+    }
+    if ev.ID == "" {
+        ev.ID = SnapshotEventID
+    }
+    req, err := logger.PrepareRequest(context.Background(), ev)
+    if err != nil {
+        t.Fatalf("scarftest: prepare request: %v", err)
+    }
+    got, err := renderRequest(req)
+    if err != nil {
+        t.Fatalf("scarftest: render request: %v", err)
+    }
+
+    path := filepath.Join("testdata", strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())+".golden")
+    if os.Getenv(UpdateSnapshotsEnv) != "" {
+        if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+            t.Fatalf("scarftest: %v", err)
+        }
+        if err := os.WriteFile(path, got, 0o644); err != nil {
+            t.Fatalf("scarftest: %v", err)
+        }
+        return
+    }
+    want, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("scarftest: %v (run with %s=1 to create it)", err, UpdateSnapshotsEnv)
+    }
+    if !bytes.Equal(got, want) {
+        t.Errorf("scarftest: request differs from %s (run with %s=1 to update)\n--- got\n%s\n--- want\n%s", path, UpdateSnapshotsEnv, got, want)
+    }
+}
+
+// userAgentVolatile matches the parts of the SDK's User-Agent that vary by
+// machine or build.
+var userAgentVolatile = regexp.MustCompile(`scarf-go/[^ ]+|(platform|arch|go)=[^;)]+`)
+
+// maskedHeaders have values that change on every request.
+var maskedHeaders = map[string]bool{scarf.SignatureHeader: true, scarf.SignatureTimestampHeader: true}
+
+// renderRequest formats req like an HTTP/1.1 request, with sorted headers.
+func renderRequest(req scarf.PreparedRequest) ([]byte, error) {
+    var b bytes.Buffer
+    u, err := url.Parse(req.URL)
+    if err != nil {
+        return nil, err
+    }
+    fmt.Fprintf(&b, "%s %s\n", req.Method, u.Redacted())
+
+    keys := make([]string, 0, len(req.Header))
+    for k := range req.Header {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    for _, k := range keys {
+        for _, v := range req.Header[k] {
+            switch {
+            case maskedHeaders[k]:
+                v = "<masked>"
+            case k == "Authorization":
+                // Keep the scheme but not the credentials.
+                if scheme, _, ok := strings.Cut(v, " "); ok {
+                    v = scheme + " <masked>"
+                } else {
+                    v = "<masked>"
+                }
+            case k == "User-Agent":
+                v = userAgentVolatile.ReplaceAllStringFunc(v, func(m string) string {
+                    if strings.HasPrefix(m, "scarf-go/") {
+                        return "scarf-go/<version>"
+                    }
+                    name, _, _ := strings.Cut(m, "=")
+                    return name + "=<" + name + ">"
+                })
+            }
+            fmt.Fprintf(&b, "%s: %s\n", k, v)
+        }
+    }
+
+    body := req.Body
+    if req.Header.Get("Content-Encoding") == "gzip" {
+        zr, err := gzip.NewReader(bytes.NewReader(body))
+        if err != nil {
+            return nil, err
+        }
+        if body, err = io.ReadAll(zr); err != nil {
+            return nil, err
+        }
+    }
+    if len(body) > 0 {
+        b.WriteByte('\n')
+        if utf8.Valid(body) {
+            b.Write(body)
+        } else {
+            b.WriteString("base64:" + base64.StdEncoding.EncodeToString(body))
+        }
+        b.WriteByte('\n')
+    }
+    return b.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+package scarftest
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "testing"
+
+    "github.com/scarf-sh/scarf-go/scarf"
+)
+
+func TestSnapshotRequest(t *testing.T) {
+    logger := scarf.New("https://telemetry.example.com/e", scarf.WithAPIKey("k"), scarf.WithRequestSigning([]byte("secret")))
+    SnapshotRequest(t, logger, scarf.Event{Name: "install", Properties: map[string]any{"version": "1.0.0"}})
+
+    logger = scarf.New("https://telemetry.example.com/e", scarf.WithEncoding(scarf.EncodingJSON))
+    t.Run("json", func(t *testing.T) {
+        SnapshotRequest(t, logger, scarf.Event{Name: "install", Properties: map[string]any{"version": "1.0.0"}})
+    })
+}
+
+func TestSnapshotRequestMismatch(t *testing.T) {
+    t.Setenv(UpdateSnapshotsEnv, "")
+    wd, _ := os.Getwd()
+    if err := os.Chdir(t.TempDir()); err != nil {
+        t.Fatal(err)
+    }
+    defer os.Chdir(wd)
+
+    logger := scarf.New("https://telemetry.example.com/e")
+    ft := snapshot(t, logger, scarf.Event{Name: "install"})
+    if !ft.failed || !strings.Contains(ft.msg, UpdateSnapshotsEnv) {
+        t.Fatalf("expected a missing golden file to fail with a hint, got %q", ft.msg)
+    }
+
+    t.Setenv(UpdateSnapshotsEnv, "1")
+    snapshot(t, logger, scarf.Event{Name: "install"})
+    golden, err := os.ReadFile(filepath.Join("testdata", "Golden.golden"))
+    if err != nil || !strings.Contains(string(golden), "User-Agent: scarf-go/<version> (platform=<platform>; arch=<arch>; go=<go>)") {
+        t.Fatalf("expected a golden file with a masked User-Agent, got %q (%v)", golden, err)
+    }
+
+    t.Setenv(UpdateSnapshotsEnv, "")
+    if ft := snapshot(t, logger, scarf.Event{Name: "install"}); ft.failed {
+        t.Fatalf("expected the same request to match, got %q", ft.msg)
+    }
+    if ft := snapshot(t, logger, scarf.Event{Name: "startup"}); !ft.failed || !strings.Contains(ft.msg, "event=startup") {
+        t.Fatalf("expected a changed request to fail with a diff, got %q", ft.msg)
+    }
+}
+
+// snapshot runs SnapshotRequest for a test named "Golden", recording rather
+// than reporting its failure.
+func snapshot(t *testing.T, logger *scarf.ScarfEventLogger, ev scarf.Event) *fakeT {
+    ft := &fakeT{TB: t}
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        SnapshotRequest(ft, logger, ev)
+    }()
+    <-done
+    return ft
+}
+
+// fakeT records the first failure instead of failing the test.
+type fakeT struct {
+    testing.TB
+    failed bool
+    msg    string
+}
+
+func (f *fakeT) Name() string { return "Golden" }
+func (f *fakeT) Helper()      {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+    if !f.failed {
+        f.failed, f.msg = true, fmt.Sprintf(format, args...)
+    }
+}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+    f.Errorf(format, args...)
+    runtime.Goexit()
+}